@@ -3,9 +3,12 @@ package commands
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"regexp"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -37,6 +40,8 @@ type wrappedResult struct {
 
 type resultMetadata struct {
 	URL             string `json:"url"`
+	FinalURL        string `json:"final_url,omitempty"`
+	CanonicalURL    string `json:"canonical_url,omitempty"`
 	FetchedAt       string `json:"fetched_at"`
 	Model           string `json:"model"`
 	Provider        string `json:"provider"`
@@ -45,6 +50,69 @@ type resultMetadata struct {
 	FetchDurationMs int64  `json:"fetch_duration_ms"`
 	LLMDurationMs   int64  `json:"llm_duration_ms"`
 	RetryCount      int    `json:"retry_count,omitempty"`
+	Fetcher         string `json:"fetcher,omitempty"`
+	Unchanged       bool   `json:"unchanged,omitempty"` // Data is from the last incremental crawl; the page hasn't changed
+
+	// Pages the crawl deliberately didn't extract; only output with --include-skipped
+	Skipped     bool   `json:"skipped,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	DuplicateOf string `json:"duplicate_of,omitempty"` // Page a duplicate was skipped in favour of
+
+	// Fetch retries; only present when the fetch needed more than one attempt
+	FetchRetries  int               `json:"fetch_retries,omitempty"`
+	FetchAttempts []attemptMetadata `json:"fetch_attempts,omitempty"`
+}
+
+// attemptMetadata describes one fetch attempt.
+type attemptMetadata struct {
+	StatusCode int    `json:"status_code,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	BackoffMs  int64  `json:"backoff_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// withFetchAttempts records fetch retries on the metadata when there were any.
+func (m resultMetadata) withFetchAttempts(attempts []fetcher.Attempt) resultMetadata {
+	if len(attempts) < 2 {
+		return m
+	}
+	m.FetchRetries = len(attempts) - 1
+	for _, a := range attempts {
+		m.FetchAttempts = append(m.FetchAttempts, attemptMetadata{
+			StatusCode: a.StatusCode,
+			DurationMs: a.Duration.Milliseconds(),
+			BackoffMs:  a.Backoff.Milliseconds(),
+			Error:      a.Error,
+		})
+	}
+	return m
+}
+
+// skippedResult describes a page the crawl deliberately didn't extract.
+// It has no data, so it is wrapped with its metadata whatever --include-metadata says.
+func skippedResult(result *refyne.Result) wrappedResult {
+	meta := resultMetadata{
+		URL:          result.URL,
+		FinalURL:     result.FinalURL,
+		CanonicalURL: result.CanonicalURL,
+		Fetcher:      result.Fetcher,
+		Skipped:      true,
+	}
+	if !result.FetchedAt.IsZero() {
+		meta.FetchedAt = result.FetchedAt.Format(time.RFC3339) // robots.txt skips are never fetched
+	}
+	if result.Error != nil {
+		meta.Reason = result.Error.Error()
+	}
+	switch {
+	case result.DuplicateOf != "":
+		meta.DuplicateOf = result.DuplicateOf
+	case errors.Is(result.Error, refyne.ErrDuplicateCanonical):
+		meta.DuplicateOf = result.CanonicalURL
+	case errors.Is(result.Error, refyne.ErrDuplicateRedirect):
+		meta.DuplicateOf = result.FinalURL
+	}
+	return wrappedResult{Metadata: meta}
 }
 
 // trainingDataRecord is a single input/output pair for fine-tuning.
@@ -95,14 +163,45 @@ func init() {
 	flags.StringP("output", "o", "", "output file (default: stdout)")
 	flags.String("format", "json", "output format: json, jsonl, yaml")
 	flags.Bool("include-metadata", true, "wrap output with _metadata and data keys (use --include-metadata=false to disable)")
+	flags.Bool("include-skipped", false, "output pages the crawl skipped (robots.txt, duplicates, ...) with _metadata.skipped and reason")
 	flags.String("save-training-data", "", "save input/output pairs for fine-tuning to this file (JSONL)")
 
 	// Fetch settings
-	flags.String("fetch-mode", "static", "fetch mode: static, dynamic")
+	flags.String("fetch-mode", "static", "fetch mode: static, dynamic, auto (static, escalating to dynamic per host)")
 	flags.Duration("timeout", 30*time.Second, "request timeout")
 	flags.Bool("stealth", false, "enable anti-bot detection evasion for dynamic fetch mode")
 	flags.Bool("googlebot", false, "spoof Googlebot user-agent (sites often whitelist Googlebot)")
+	flags.Int("browser-tabs", 0, "max browser tabs open at once in dynamic/auto fetch mode (0=match --concurrency)")
+	flags.Int("tab-max-pages", clifetcher.DefaultTabMaxPages, "pages a browser tab loads before it is replaced")
+	flags.String("chrome-url", "", "connect to a running Chrome's DevTools endpoint instead of launching one (e.g., ws://localhost:9222)")
 	flags.String("flaresolverr-url", "", "FlareSolverr API URL for Cloudflare bypass (e.g., http://localhost:8191/v1)")
+	flags.String("cookies", "", "seed the cookie jar from a Netscape cookies.txt or JSON file")
+	flags.String("save-cookies", "", "save the cookie jar to this file when done (JSON if it ends in .json, else cookies.txt)")
+	flags.String("auth", "", "YAML/JSON login step (form post or browser script) run once per host before fetching")
+	flags.String("actions", "", "YAML file of browser actions run on each page, optionally per URL pattern (dynamic/auto fetch mode)")
+	flags.StringArray("action", nil, "browser action run on every page, e.g. click:.load-more, scroll:10, network_idle (repeatable, after --actions)")
+	flags.Bool("capture-resources", false, "record the JSON responses pages load via XHR/fetch (dynamic/auto fetch mode)")
+	flags.String("capture-pattern", "", "only capture XHR/fetch responses whose URL matches this regex (implies --capture-resources)")
+	flags.StringSlice("capture-types", nil, "content types to capture (default: JSON types)")
+	flags.String("resource-mode", "page", "what to extract from when resources are captured: page, append (page then resources), only (resources instead of page)")
+	flags.String("vision", "", "send a page screenshot to the model: append (with the page content) or only (instead of it); overrides the schema's vision setting (needs a vision model and dynamic/auto fetch mode)")
+	flags.Bool("vision-images", false, "also send the page's images (floor plans, charts) with the screenshot (implies --vision append unless set)")
+	flags.String("max-body-size", "10MiB", "skip responses larger than this (static fetches, e.g., 10MiB, 500KB)")
+	flags.StringSlice("content-types", nil, "only fetch these content types, e.g., text/html,application/pdf,text/* (static fetches; default: any)")
+	flags.Int("fetch-attempts", 3, "max fetch attempts per URL, including the first (1=no retries)")
+	flags.Duration("fetch-backoff", 500*time.Millisecond, "backoff before the first fetch retry (doubles on each retry)")
+	flags.Duration("fetch-max-backoff", 30*time.Second, "max backoff between fetch retries, including Retry-After waits")
+	flags.Float64("fetch-jitter", 0.2, "fraction of each backoff that is randomized (0-1)")
+	flags.IntSlice("fetch-retry-status", fetcher.DefaultRetryStatuses, "HTTP status codes that trigger a fetch retry")
+
+	// Cache settings
+	flags.String("cache-dir", "", "directory for the on-disk HTTP response cache (disabled if empty)")
+	flags.String("cache-mode", "readwrite", "cache mode: off, read, write, readwrite")
+	flags.Duration("cache-ttl", 0, "treat cached responses as fresh for this long, ignoring Cache-Control (0=honour headers)")
+
+	// Archive settings
+	flags.String("record", "", "record every fetched page to a WARC file (gzip-compressed if it ends in .gz)")
+	flags.String("replay", "", "serve pages from a WARC file instead of the network")
 
 	// Extraction settings
 	flags.Int("max-retries", 3, "max extraction retries")
@@ -116,8 +215,33 @@ func init() {
 	flags.Int("max-depth", 1, "max link depth (0=seed only)")
 	flags.Int("max-pages", 0, "max pagination pages (0=unlimited)")
 	flags.Int("max-urls", 0, "max total URLs to process (0=unlimited)")
-	flags.Duration("delay", 200*time.Millisecond, "delay between requests")
+	flags.Duration("delay", 200*time.Millisecond, "minimum delay between requests to the same host")
 	flags.IntP("concurrency", "c", 3, "concurrent requests")
+	flags.Float64("host-rps", 0, "requests per second per host (overrides --delay, 0=derive from --delay)")
+	flags.Int("host-burst", 1, "requests per host that may be made back-to-back")
+	flags.Int("host-max-inflight", 0, "max concurrent requests per host (0=limited by --concurrency)")
+	flags.Bool("respect-robots", false, "honour robots.txt rules and Crawl-delay (disallowed URLs are skipped)")
+	flags.String("strategy", "bfs", "crawl order: bfs (breadth-first), dfs (depth-first), priority (highest-scoring URLs first)")
+	flags.StringArray("priority-pattern", nil, "raise the priority of URLs matching a regex, as regex=weight (implies --strategy priority, repeatable)")
+	flags.StringArray("priority-keyword", nil, "raise the priority of links whose text contains a keyword, as keyword=weight (implies --strategy priority, repeatable)")
+	flags.Float64("host-fairness", refyne.DefaultPriorityConfig().HostFairness, "priority lost per URL already crawled from the same host, so one host doesn't starve the others")
+	flags.StringSlice("ignore-params", nil, "query parameters removed from URLs before deduplication, e.g., sessionid,sort (a trailing * matches a prefix)")
+	flags.Bool("keep-tracking-params", false, "keep tracking parameters (utm_*, gclid, fbclid, ref, ...) in crawled URLs")
+	flags.Bool("ignore-www", false, "treat www.example.com and example.com as the same host when deduplicating URLs")
+	flags.Bool("ignore-canonical", false, "don't deduplicate pages on their rel=canonical URL")
+	flags.Float64("dedup-similarity", 0, "skip extracting pages whose content is at least this similar (0-1, e.g., 0.95) to a page already extracted (0=off)")
+	flags.String("incremental-dir", "", "keep page hashes and extracted data here between crawls; unchanged pages reuse their previous data instead of being extracted again")
+	flags.Bool("omit-unchanged", false, "leave pages unchanged since the last --incremental-dir crawl out of the output")
+	flags.String("state-dir", "", "directory the crawl's progress is saved to periodically and on exit, so it can be resumed")
+	flags.Bool("resume", false, "continue the crawl saved in --state-dir instead of starting from the seeds; output is appended")
+
+	// Seed sources
+	flags.String("seed-requests", "", "YAML/JSON list of seed requests: URLs, or url/method/body/form specs for search forms and APIs")
+	flags.StringSlice("sitemap", nil, "sitemap or sitemap index URL whose pages are crawled as seeds (gzipped OK, repeatable)")
+	flags.StringSlice("feed", nil, "RSS/Atom feed URL whose items are crawled as seeds (repeatable)")
+	flags.String("seed-since", "", "only seed sitemap/feed entries dated on or after this (YYYY-MM-DD, RFC 3339, or a duration ago like 168h)")
+	flags.String("seed-until", "", "only seed sitemap/feed entries dated on or before this (same formats as --seed-since)")
+	flags.String("seed-pattern", "", "only seed sitemap/feed URLs matching this regex")
 
 	// Required flags
 	_ = scrapeCmd.MarkFlagRequired("schema")
@@ -143,7 +267,36 @@ func runScrape(cmd *cobra.Command, args []string) error {
 
 	// Get URLs
 	urls, _ := cmd.Flags().GetStringSlice("url")
-	if len(urls) == 0 {
+	seedSources, err := seedSourcesFromFlags(cmd, time.Now())
+	if err != nil {
+		return err
+	}
+	var seedRequests []fetcher.Request
+	if seedRequestsPath, _ := cmd.Flags().GetString("seed-requests"); seedRequestsPath != "" {
+		seedRequests, err = fetcher.LoadRequests(seedRequestsPath)
+		if err != nil {
+			logger.Error("failed to load seed requests", "path", seedRequestsPath, "error", err)
+			return err
+		}
+	}
+	crawlOrder, err := crawlOrderFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	dedupSimilarity, _ := cmd.Flags().GetFloat64("dedup-similarity")
+	if dedupSimilarity < 0 || dedupSimilarity > 1 {
+		return fmt.Errorf("--dedup-similarity must be between 0 and 1, got %v", dedupSimilarity)
+	}
+	stateDir, _ := cmd.Flags().GetString("state-dir")
+	resume, _ := cmd.Flags().GetBool("resume")
+	incrementalDir, _ := cmd.Flags().GetString("incremental-dir")
+	if resume && stateDir == "" {
+		return fmt.Errorf("--resume needs --state-dir")
+	}
+	if omit, _ := cmd.Flags().GetBool("omit-unchanged"); omit && incrementalDir == "" {
+		return fmt.Errorf("--omit-unchanged needs --incremental-dir")
+	}
+	if len(urls) == 0 && len(seedSources) == 0 && len(seedRequests) == 0 && !resume {
 		return cmd.Help()
 	}
 	logger.Debug("URLs to process", "count", len(urls), "urls", urls)
@@ -157,6 +310,9 @@ func runScrape(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	logger.Debug("schema loaded", "name", s.Name, "fields", len(s.Fields))
+	if err := applyVisionFlags(cmd, &s); err != nil {
+		return err
+	}
 
 	// Get fetch mode
 	fetchModeStr, _ := cmd.Flags().GetString("fetch-mode")
@@ -187,34 +343,35 @@ func runScrape(cmd *cobra.Command, args []string) error {
 	stealth, _ := cmd.Flags().GetBool("stealth")
 	googlebot, _ := cmd.Flags().GetBool("googlebot")
 	flareSolverrURL, _ := cmd.Flags().GetString("flaresolverr-url")
-
-	// Create fetcher based on mode
-	var f fetcher.Fetcher
-	switch fetchModeStr {
-	case "dynamic":
-		// Use CLI's dynamic fetcher with advanced options
-		dynamicFetcher, err := clifetcher.NewDynamicFetcher(clifetcher.Config{
-			Timeout:         timeout,
-			Stealth:         stealth,
-			Googlebot:       googlebot,
-			FlareSolverrURL: flareSolverrURL,
-		})
-		if err != nil {
-			logger.Error("failed to create dynamic fetcher", "error", err)
+	chromeURL, _ := cmd.Flags().GetString("chrome-url")
+	retryPolicy := fetcher.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts, _ = cmd.Flags().GetInt("fetch-attempts")
+	retryPolicy.BaseBackoff, _ = cmd.Flags().GetDuration("fetch-backoff")
+	retryPolicy.MaxBackoff, _ = cmd.Flags().GetDuration("fetch-max-backoff")
+	retryPolicy.Jitter, _ = cmd.Flags().GetFloat64("fetch-jitter")
+	retryPolicy.RetryStatuses, _ = cmd.Flags().GetIntSlice("fetch-retry-status")
+
+	// Session cookies are shared by every request in the run
+	cookiesPath, _ := cmd.Flags().GetString("cookies")
+	saveCookiesPath, _ := cmd.Flags().GetString("save-cookies")
+	jar := fetcher.NewCookieJar()
+	if cookiesPath != "" {
+		if err := jar.LoadFile(cookiesPath); err != nil {
+			logger.Error("failed to load cookies", "path", cookiesPath, "error", err)
 			return err
 		}
-		f = dynamicFetcher
-	case "static", "":
-		// Use static fetcher (default)
-		f = fetcher.NewStatic(fetcher.StaticConfig{
-			Timeout: timeout,
-		})
-	default:
-		return fmt.Errorf("unknown fetch mode: %s (use 'static' or 'dynamic')", fetchModeStr)
+		logger.Info("cookies loaded", "path", cookiesPath, "count", jar.Len())
 	}
-	// Note: fetcher is closed by refyne.Close()
 
-	// Create cleaner based on --no-cleanse flag
+	// Get archive options
+	recordPath, _ := cmd.Flags().GetString("record")
+	replayPath, _ := cmd.Flags().GetString("replay")
+	if recordPath != "" && replayPath != "" {
+		return fmt.Errorf("--record and --replay cannot be used together")
+	}
+
+	// Create cleaner based on --no-cleanse flag. This comes before the fetcher
+	// because auto fetch mode measures pages the way the extractor will see them.
 	noCleanse, _ := cmd.Flags().GetBool("no-cleanse")
 	var cl cleaner.Cleaner
 	if noCleanse {
@@ -232,6 +389,158 @@ func runScrape(cmd *cobra.Command, args []string) error {
 		logger.Debug("using refyne cleaner with markdown output", "cleaner", cl.Name())
 	}
 
+	// Responses too large or of the wrong type are aborted and skipped
+	maxBodySizeStr, _ := cmd.Flags().GetString("max-body-size")
+	maxBodySize, err := humanize.ParseBytes(maxBodySizeStr)
+	if err != nil {
+		logger.Error("invalid max-body-size", "value", maxBodySizeStr, "error", err)
+		return err
+	}
+	contentTypes, _ := cmd.Flags().GetStringSlice("content-types")
+
+	staticConfig := fetcher.StaticConfig{
+		Timeout:             timeout,
+		Retry:               retryPolicy,
+		CookieJar:           jar,
+		SaveCookies:         saveCookiesPath,
+		MaxBodyBytes:        int64(maxBodySize),
+		AllowedContentTypes: contentTypes,
+	}
+	// Each concurrent fetch gets its own browser tab unless capped
+	browserTabs, _ := cmd.Flags().GetInt("browser-tabs")
+	if browserTabs <= 0 {
+		browserTabs, _ = cmd.Flags().GetInt("concurrency")
+	}
+	tabMaxPages, _ := cmd.Flags().GetInt("tab-max-pages")
+	dynamicConfig := clifetcher.Config{
+		Timeout:         timeout,
+		Stealth:         stealth,
+		Googlebot:       googlebot,
+		FlareSolverrURL: flareSolverrURL,
+		Retry:           retryPolicy,
+		CookieJar:       jar,
+		SaveCookies:     saveCookiesPath,
+		MaxTabs:         browserTabs,
+		TabMaxPages:     tabMaxPages,
+		ChromeURL:       chromeURL,
+	}
+
+	// Create fetcher based on mode. A browser renders robots.txt, sitemaps and
+	// feeds as pages, so with one they are fetched statically, sharing the
+	// cookie jar (and so any login session), the cache and the archive.
+	var f, documents fetcher.Fetcher
+	switch {
+	case replayPath != "":
+		// Serve every page from the archive with no network access
+		replayFetcher, err := fetcher.NewReplay(replayPath)
+		if err != nil {
+			logger.Error("failed to load replay archive", "path", replayPath, "error", err)
+			return err
+		}
+		logger.Info("replaying from archive", "path", replayPath, "responses", replayFetcher.Len())
+		f = replayFetcher
+	case fetchModeStr == "dynamic":
+		// Use CLI's dynamic fetcher with advanced options
+		dynamicFetcher, err := clifetcher.NewDynamicFetcher(dynamicConfig)
+		if err != nil {
+			logger.Error("failed to create dynamic fetcher", "error", err)
+			return err
+		}
+		f = dynamicFetcher
+		documents = fetcher.NewStatic(staticConfig)
+	case fetchModeStr == "auto":
+		// Fetch statically and escalate to the browser per host when a page
+		// needs JavaScript. Chrome only starts if a host is escalated.
+		dynamicFetcher, err := clifetcher.NewDynamicFetcher(dynamicConfig)
+		if err != nil {
+			logger.Error("failed to create dynamic fetcher", "error", err)
+			return err
+		}
+		documents = fetcher.NewStatic(staticConfig)
+		f = fetcher.NewAuto(documents, dynamicFetcher, fetcher.AutoConfig{Cleaner: cl})
+	case fetchModeStr == "static" || fetchModeStr == "":
+		// Use static fetcher (default)
+		f = fetcher.NewStatic(staticConfig)
+	default:
+		return fmt.Errorf("unknown fetch mode: %s (use 'static', 'dynamic' or 'auto')", fetchModeStr)
+	}
+
+	// Log in before fetching from each host. This sits inside the cache so a
+	// logged-out page is never cached in place of the real one.
+	authPath, _ := cmd.Flags().GetString("auth")
+	if authPath != "" && replayPath == "" {
+		authCfg, err := fetcher.LoadAuthConfig(authPath)
+		if err != nil {
+			logger.Error("failed to load auth config", "path", authPath, "error", err)
+			return err
+		}
+		f = fetcher.NewAuthenticating(f, authCfg)
+		logger.Debug("auth step enabled", "path", authPath)
+	}
+
+	// Browser actions run on each page after it loads
+	actionsCfg, err := actionConfigFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if (len(actionsCfg.Actions) > 0 || len(actionsCfg.URLs) > 0) && f.Type() == "static" {
+		logger.Warn("browser actions are ignored in static fetch mode, use --fetch-mode dynamic or auto")
+	}
+
+	// XHR/fetch responses recorded while pages render
+	captureCfg, resourceMode, err := captureConfigFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if captureCfg != nil && f.Type() == "static" {
+		logger.Warn("resource capture is ignored in static fetch mode, use --fetch-mode dynamic or auto")
+	}
+	if s.Vision != nil && f.Type() == "static" {
+		logger.Warn("static fetch mode can't take screenshots, so vision extraction uses page content only; use --fetch-mode dynamic or auto")
+	}
+
+	// Wrap with the response cache if configured
+	cacheDir, _ := cmd.Flags().GetString("cache-dir")
+	cacheModeStr, _ := cmd.Flags().GetString("cache-mode")
+	cacheTTL, _ := cmd.Flags().GetDuration("cache-ttl")
+	cacheMode, err := fetcher.ParseCacheMode(cacheModeStr)
+	if err != nil {
+		return err
+	}
+	if cacheDir != "" && cacheMode != fetcher.CacheOff && replayPath == "" {
+		store, err := fetcher.NewDiskCache(cacheDir)
+		if err != nil {
+			logger.Error("failed to open cache", "dir", cacheDir, "error", err)
+			return err
+		}
+		cacheCfg := fetcher.CacheConfig{
+			Store: store,
+			Mode:  cacheMode,
+			TTL:   cacheTTL,
+		}
+		f = fetcher.NewCaching(f, cacheCfg)
+		if documents != nil {
+			documents = fetcher.NewCaching(documents, cacheCfg)
+		}
+		logger.Debug("response cache enabled", "dir", cacheDir, "mode", cacheMode, "ttl", cacheTTL)
+	}
+
+	// Record everything the pipeline receives (including cache hits) so the
+	// archive can be replayed on its own later
+	if recordPath != "" {
+		warcWriter, err := fetcher.CreateWARC(recordPath)
+		if err != nil {
+			logger.Error("failed to create WARC archive", "path", recordPath, "error", err)
+			return err
+		}
+		f = fetcher.NewRecording(f, warcWriter)
+		if documents != nil {
+			documents = fetcher.NewRecording(documents, warcWriter) // not closed, so the archive is closed once
+		}
+		logger.Info("recording to archive", "path", recordPath)
+	}
+	// Note: fetcher is closed by refyne.Close()
+
 	// Build extractor fallback chain
 	// Order: --provider flag first (if set), then config fallback_order, default: openrouter → anthropic → ollama
 	preferredProvider := viper.GetString("provider")
@@ -255,22 +564,37 @@ func runScrape(cmd *cobra.Command, args []string) error {
 
 	logger.Debug("extractor chain built", "chain", ext.Name())
 
-	r, err := refyne.New(
+	refyneOpts := []refyne.Option{
 		refyne.WithFetcher(f),
+		refyne.WithDocumentFetcher(documents),
 		refyne.WithCleaner(cl),
 		refyne.WithExtractor(ext),
-	)
+		refyne.WithActions(actionsCfg),
+	}
+	if captureCfg != nil {
+		refyneOpts = append(refyneOpts, refyne.WithResourceCapture(*captureCfg, resourceMode))
+	}
+	r, err := refyne.New(refyneOpts...)
 	if err != nil {
 		logger.Error("failed to initialize", "error", err)
 		return err
 	}
-	defer func() { _ = r.Close() }()
+	defer func() {
+		if err := r.Close(); err != nil {
+			logger.Warn("failed to close fetcher", "error", err)
+		}
+	}()
 	logger.Debug("refyne instance created")
 
 	// Setup output
 	outFile := os.Stdout
+	formatStr, _ := cmd.Flags().GetString("format")
 	if outPath, _ := cmd.Flags().GetString("output"); outPath != "" {
-		f, err := os.Create(outPath) //#nosec G304 -- CLI tool writes to user-specified output file
+		// A resumed crawl adds to the results of the runs before it
+		if resume && output.Format(formatStr) != output.FormatJSONL {
+			logger.Warn("resumed crawl output is appended to the file; use --format jsonl to keep it valid", "path", outPath)
+		}
+		f, err := createOutputFile(outPath, resume)
 		if err != nil {
 			logger.Error("failed to create output file", "path", outPath, "error", err)
 			return err
@@ -279,7 +603,6 @@ func runScrape(cmd *cobra.Command, args []string) error {
 		outFile = f
 	}
 
-	formatStr, _ := cmd.Flags().GetString("format")
 	writer, err := output.NewWriter(outFile, output.Format(formatStr))
 	if err != nil {
 		logger.Error("failed to create output writer", "format", formatStr, "error", err)
@@ -289,13 +612,14 @@ func runScrape(cmd *cobra.Command, args []string) error {
 
 	// Get metadata option
 	includeMetadata, _ := cmd.Flags().GetBool("include-metadata")
+	includeSkipped, _ := cmd.Flags().GetBool("include-skipped")
 
 	// Setup training data output if requested
 	trainingDataPath, _ := cmd.Flags().GetString("save-training-data")
 	var trainingFile *os.File
 	var trainingEncoder *json.Encoder
 	if trainingDataPath != "" {
-		f, err := os.Create(trainingDataPath) //#nosec G304 -- CLI tool writes to user-specified file
+		f, err := createOutputFile(trainingDataPath, resume)
 		if err != nil {
 			logger.Error("failed to create training data file", "path", trainingDataPath, "error", err)
 			return err
@@ -315,24 +639,32 @@ func runScrape(cmd *cobra.Command, args []string) error {
 	maxURLs, _ := cmd.Flags().GetInt("max-urls")
 	delay, _ := cmd.Flags().GetDuration("delay")
 	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	hostRPS, _ := cmd.Flags().GetFloat64("host-rps")
+	hostBurst, _ := cmd.Flags().GetInt("host-burst")
+	hostMaxInFlight, _ := cmd.Flags().GetInt("host-max-inflight")
+	respectRobots, _ := cmd.Flags().GetBool("respect-robots")
 
 	// Determine if we're doing simple extraction or crawling
-	isCrawling := followSelector != "" || followPattern != "" || nextSelector != ""
+	isCrawling := followSelector != "" || followPattern != "" || nextSelector != "" || len(seedSources) > 0 || len(seedRequests) > 0 || stateDir != "" || incrementalDir != ""
 
 	var hasErrors bool
 
 	if isCrawling {
 		// Crawling mode
 		logger.Info("starting crawl",
-			"seeds", len(urls),
+			"seeds", len(urls)+len(seedRequests),
 			"extractors", ext.Name(),
 			"concurrency", concurrency,
 			"delay", delay)
 
 		crawlOpts := []refyne.CrawlOption{
+			refyne.WithURLNormalization(normalizeConfigFromFlags(cmd)),
 			refyne.WithMaxDepth(maxDepth),
 			refyne.WithDelay(delay),
 			refyne.WithConcurrency(concurrency),
+			refyne.WithHostRateLimit(hostRPS, hostBurst),
+			refyne.WithHostMaxInFlight(hostMaxInFlight),
+			crawlOrder,
 		}
 
 		if followSelector != "" {
@@ -350,12 +682,49 @@ func runScrape(cmd *cobra.Command, args []string) error {
 		if maxURLs > 0 {
 			crawlOpts = append(crawlOpts, refyne.WithMaxURLs(maxURLs))
 		}
+		if respectRobots {
+			crawlOpts = append(crawlOpts, refyne.WithRespectRobots(true))
+		}
+		if ignoreCanonical, _ := cmd.Flags().GetBool("ignore-canonical"); ignoreCanonical {
+			crawlOpts = append(crawlOpts, refyne.WithCanonicalDedup(false))
+		}
+		if incrementalDir != "" {
+			omitUnchanged, _ := cmd.Flags().GetBool("omit-unchanged")
+			crawlOpts = append(crawlOpts, refyne.WithIncremental(incrementalDir), refyne.WithOmitUnchanged(omitUnchanged))
+		}
+		if dedupSimilarity > 0 {
+			crawlOpts = append(crawlOpts, refyne.WithNearDuplicateDetection(dedupSimilarity))
+		}
+		if len(seedSources) > 0 {
+			crawlOpts = append(crawlOpts, refyne.WithSitemapSeeds(seedSources...))
+		}
+		if len(seedRequests) > 0 {
+			crawlOpts = append(crawlOpts, refyne.WithSeedRequests(seedRequests...))
+		}
+		if stateDir != "" {
+			crawlOpts = append(crawlOpts, refyne.WithCrawlState(stateDir), refyne.WithResume(resume))
+		}
 
 		results := r.CrawlMany(ctx, urls, s, crawlOpts...)
 
 		count := 0
 		errorCount := 0
+		skippedCount := 0
+		unchangedCount := 0
 		for result := range results {
+			if result.Unchanged {
+				unchangedCount++
+			}
+			if result.Skipped {
+				skippedCount++
+				if includeSkipped {
+					if err := writer.Write(skippedResult(result)); err != nil {
+						logger.Error("failed to write output", "error", err)
+						return err
+					}
+				}
+				continue
+			}
 			if result.Error != nil {
 				errorCount++
 				hasErrors = true
@@ -368,6 +737,8 @@ func runScrape(cmd *cobra.Command, args []string) error {
 					out = wrappedResult{
 						Metadata: resultMetadata{
 							URL:             result.URL,
+							FinalURL:        result.FinalURL,
+							CanonicalURL:    result.CanonicalURL,
 							FetchedAt:       result.FetchedAt.Format(time.RFC3339),
 							Model:           result.Model,
 							Provider:        result.Provider,
@@ -376,7 +747,9 @@ func runScrape(cmd *cobra.Command, args []string) error {
 							FetchDurationMs: result.FetchDuration.Milliseconds(),
 							LLMDurationMs:   result.ExtractDuration.Milliseconds(),
 							RetryCount:      result.RetryCount,
-						},
+							Fetcher:         result.Fetcher,
+							Unchanged:       result.Unchanged,
+						}.withFetchAttempts(result.FetchAttempts),
 						Data: result.Data,
 					}
 				}
@@ -399,7 +772,7 @@ func runScrape(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		logger.Info("crawl complete", "extracted", count, "errors", errorCount)
+		logger.Info("crawl complete", "extracted", count-unchangedCount, "unchanged", unchangedCount, "errors", errorCount, "skipped", skippedCount)
 	} else {
 		// Simple extraction mode
 		logger.Info("starting extraction",
@@ -423,6 +796,8 @@ func runScrape(cmd *cobra.Command, args []string) error {
 				out = wrappedResult{
 					Metadata: resultMetadata{
 						URL:             result.URL,
+						FinalURL:        result.FinalURL,
+						CanonicalURL:    result.CanonicalURL,
 						FetchedAt:       result.FetchedAt.Format(time.RFC3339),
 						Model:           result.Model,
 						Provider:        result.Provider,
@@ -431,7 +806,9 @@ func runScrape(cmd *cobra.Command, args []string) error {
 						FetchDurationMs: result.FetchDuration.Milliseconds(),
 						LLMDurationMs:   result.ExtractDuration.Milliseconds(),
 						RetryCount:      result.RetryCount,
-					},
+						Fetcher:         result.Fetcher,
+						Unchanged:       result.Unchanged,
+					}.withFetchAttempts(result.FetchAttempts),
 					Data: result.Data,
 				}
 			}
@@ -463,6 +840,16 @@ func runScrape(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// createOutputFile creates (or truncates) the file at path, or opens it for
+// appending if appendTo is set.
+func createOutputFile(path string, appendTo bool) (*os.File, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendTo {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	return os.OpenFile(path, flags, 0o666) //#nosec G302 G304 -- CLI tool writes to user-specified output file
+}
+
 // ProviderConfig holds provider-specific settings from config file.
 type ProviderConfig struct {
 	Model       string  `mapstructure:"model"`
@@ -600,3 +987,193 @@ func buildExtractorChain(preferredProvider, modelOverride string, baseCfg *extra
 
 	return extractor.NewFallback(extractors...), nil
 }
+
+// actionConfigFromFlags loads the --actions file and appends any --action
+// flags to the actions run on every page.
+func actionConfigFromFlags(cmd *cobra.Command) (fetcher.ActionConfig, error) {
+	var cfg fetcher.ActionConfig
+	if path, _ := cmd.Flags().GetString("actions"); path != "" {
+		var err error
+		if cfg, err = fetcher.LoadActionConfig(path); err != nil {
+			logger.Error("failed to load actions", "path", path, "error", err)
+			return cfg, err
+		}
+		logger.Debug("browser actions loaded", "path", path, "actions", len(cfg.Actions), "url_rules", len(cfg.URLs))
+	}
+	inline, _ := cmd.Flags().GetStringArray("action")
+	for _, s := range inline {
+		a, err := fetcher.ParseAction(s)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid --action: %w", err)
+		}
+		cfg.Actions = append(cfg.Actions, a)
+	}
+	return cfg, nil
+}
+
+// captureConfigFromFlags returns the resource capture config, or nil if
+// capture is off, and how captured resources are used for extraction.
+func captureConfigFromFlags(cmd *cobra.Command) (*fetcher.CaptureConfig, fetcher.ResourceMode, error) {
+	modeStr, _ := cmd.Flags().GetString("resource-mode")
+	mode, err := fetcher.ParseResourceMode(modeStr)
+	if err != nil {
+		return nil, "", err
+	}
+	enabled, _ := cmd.Flags().GetBool("capture-resources")
+	pattern, _ := cmd.Flags().GetString("capture-pattern")
+	types, _ := cmd.Flags().GetStringSlice("capture-types")
+	if !enabled && pattern == "" && len(types) == 0 && mode == fetcher.ResourcesIgnore {
+		return nil, mode, nil
+	}
+
+	cfg := &fetcher.CaptureConfig{URLPattern: pattern, ContentTypes: types}
+	if err := cfg.Validate(); err != nil {
+		return nil, "", err
+	}
+	logger.Debug("resource capture enabled", "pattern", pattern, "types", types, "mode", mode)
+	return cfg, mode, nil
+}
+
+// applyVisionFlags applies --vision and --vision-images to the schema's vision
+// setting and validates it.
+func applyVisionFlags(cmd *cobra.Command, s *schema.Schema) error {
+	modeStr, _ := cmd.Flags().GetString("vision")
+	images, _ := cmd.Flags().GetBool("vision-images")
+	if modeStr != "" || images {
+		v := schema.Vision{}
+		if s.Vision != nil {
+			v = *s.Vision
+		}
+		if modeStr != "" {
+			v.Mode = schema.VisionMode(modeStr)
+		}
+		v.Images = v.Images || images
+		s.Vision = &v
+	}
+	if s.Vision == nil {
+		return nil
+	}
+	if err := s.Vision.Validate(); err != nil {
+		return err
+	}
+	logger.Debug("vision extraction enabled", "mode", s.Vision.Mode, "images", s.Vision.Images)
+	return nil
+}
+
+// seedSourcesFromFlags builds the sitemap and feed seed sources from the
+// --sitemap, --feed and --seed-* flags.
+func seedSourcesFromFlags(cmd *cobra.Command, now time.Time) ([]refyne.SeedSource, error) {
+	sitemaps, _ := cmd.Flags().GetStringSlice("sitemap")
+	feeds, _ := cmd.Flags().GetStringSlice("feed")
+	if len(sitemaps) == 0 && len(feeds) == 0 {
+		return nil, nil
+	}
+
+	sinceStr, _ := cmd.Flags().GetString("seed-since")
+	untilStr, _ := cmd.Flags().GetString("seed-until")
+	pattern, _ := cmd.Flags().GetString("seed-pattern")
+	since, err := parseSeedTime(sinceStr, now, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --seed-since: %w", err)
+	}
+	until, err := parseSeedTime(untilStr, now, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --seed-until: %w", err)
+	}
+
+	var sources []refyne.SeedSource
+	for _, u := range append(sitemaps, feeds...) {
+		sources = append(sources, refyne.SeedSource{URL: u, Since: since, Until: until, Pattern: pattern})
+	}
+	return sources, nil
+}
+
+// crawlOrderFromFlags returns the crawl option for --strategy and the
+// --priority-* and --host-fairness scoring flags.
+func crawlOrderFromFlags(cmd *cobra.Command) (refyne.CrawlOption, error) {
+	strategyStr, _ := cmd.Flags().GetString("strategy")
+	strategy, err := refyne.ParseStrategy(strategyStr)
+	if err != nil {
+		return nil, err
+	}
+	patterns, _ := cmd.Flags().GetStringArray("priority-pattern")
+	keywords, _ := cmd.Flags().GetStringArray("priority-keyword")
+	if (len(patterns) > 0 || len(keywords) > 0) && !cmd.Flags().Changed("strategy") {
+		strategy = refyne.StrategyPriority
+	}
+	if strategy != refyne.StrategyPriority {
+		return refyne.WithCrawlStrategy(strategy), nil
+	}
+
+	cfg := refyne.DefaultPriorityConfig()
+	cfg.HostFairness, _ = cmd.Flags().GetFloat64("host-fairness")
+	for _, spec := range patterns {
+		pattern, weight, err := parseWeight(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --priority-pattern: %w", err)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --priority-pattern %q: %w", pattern, err)
+		}
+		cfg.URLPatterns = append(cfg.URLPatterns, refyne.PatternWeight{Pattern: re, Weight: weight})
+	}
+	for _, spec := range keywords {
+		keyword, weight, err := parseWeight(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --priority-keyword: %w", err)
+		}
+		if cfg.Keywords == nil {
+			cfg.Keywords = make(map[string]float64)
+		}
+		cfg.Keywords[keyword] = weight
+	}
+	logger.Debug("priority crawl order", "patterns", len(cfg.URLPatterns), "keywords", len(cfg.Keywords), "host_fairness", cfg.HostFairness)
+	return refyne.WithPriority(cfg), nil
+}
+
+// normalizeConfigFromFlags returns the URL normalization rules for
+// --ignore-params, --keep-tracking-params and --ignore-www.
+func normalizeConfigFromFlags(cmd *cobra.Command) refyne.NormalizeConfig {
+	cfg := refyne.DefaultNormalizeConfig()
+	cfg.IgnoreParams, _ = cmd.Flags().GetStringSlice("ignore-params")
+	keepTracking, _ := cmd.Flags().GetBool("keep-tracking-params")
+	cfg.StripTracking = !keepTracking
+	cfg.IgnoreWWW, _ = cmd.Flags().GetBool("ignore-www")
+	return cfg
+}
+
+// parseWeight splits a "name=weight" spec at its last "=", so the name (a
+// regex, say) may contain "=".
+func parseWeight(spec string) (string, float64, error) {
+	i := strings.LastIndex(spec, "=")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("%q is not name=weight", spec)
+	}
+	weight, err := strconv.ParseFloat(strings.TrimSpace(spec[i+1:]), 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("%q has an invalid weight: %w", spec, err)
+	}
+	return spec[:i], weight, nil
+}
+
+// parseSeedTime parses a date (YYYY-MM-DD or RFC 3339) or a duration before now.
+// With endOfDay, a bare date means the last instant of that day.
+func parseSeedTime(s string, now time.Time, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		if endOfDay {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date (YYYY-MM-DD, RFC 3339) or duration", s)
+}
@@ -0,0 +1,252 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+)
+
+const (
+	// defaultActionPause is how long repeated clicks and scrolls wait for new content.
+	defaultActionPause = time.Second
+	// defaultNetworkQuiet is how long the network must be idle for network_idle.
+	defaultNetworkQuiet = 500 * time.Millisecond
+	// maxNetworkIdleWait bounds network_idle on pages that never go quiet
+	// (analytics beacons, long polling); the script continues afterwards.
+	maxNetworkIdleWait = 15 * time.Second
+)
+
+// browserActions converts an action script to chromedp actions, resolving
+// navigate URLs against baseURL.
+func browserActions(baseURL string, actions []fetcher.Action) ([]chromedp.Action, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	steps := make([]chromedp.Action, 0, len(actions))
+	for i, a := range actions {
+		if err := a.Validate(); err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		var step chromedp.Action
+		switch a.Type {
+		case fetcher.ActionNavigate:
+			ref, err := url.Parse(a.URL)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: invalid url: %w", i+1, err)
+			}
+			step = chromedp.Navigate(base.ResolveReference(ref).String())
+		case fetcher.ActionFill:
+			step = chromedp.Tasks{
+				chromedp.WaitReady(a.Selector, chromedp.ByQuery),
+				chromedp.SendKeys(a.Selector, a.Value, chromedp.ByQuery),
+			}
+		case fetcher.ActionClick:
+			if a.Times > 1 {
+				step = clickRepeatedly(a)
+			} else {
+				step = chromedp.Click(a.Selector, chromedp.ByQuery)
+			}
+		case fetcher.ActionSelect:
+			step = selectOption(a.Selector, a.Value)
+		case fetcher.ActionScroll:
+			step = scrollToBottom(a)
+		case fetcher.ActionWait:
+			step = chromedp.WaitReady(a.Selector, chromedp.ByQuery)
+		case fetcher.ActionNetworkIdle:
+			step = waitNetworkIdle(durationOr(a.Duration, defaultNetworkQuiet))
+		case fetcher.ActionEval:
+			step = chromedp.Evaluate(a.Script, nil, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+				return p.WithAwaitPromise(true)
+			})
+		case fetcher.ActionSleep:
+			step = chromedp.Sleep(a.Duration)
+		}
+		if a.Optional && a.Selector != "" {
+			step = ifPresent(a.Selector, step)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// ifPresent runs step only if an element matches selector right now, so
+// optional steps (e.g., dismissing a consent dialog) don't wait for a timeout.
+func ifPresent(selector string, step chromedp.Action) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		present, err := elementPresent(ctx, selector)
+		if err != nil {
+			return err
+		}
+		if !present {
+			logger.Debug("optional action skipped, no matching element", "selector", selector)
+			return nil
+		}
+		return step.Do(ctx)
+	}
+}
+
+// clickRepeatedly clicks selector up to a.Times times until it disappears,
+// pausing after each click for new content to load.
+func clickRepeatedly(a fetcher.Action) chromedp.ActionFunc {
+	pause := durationOr(a.Duration, defaultActionPause)
+	return func(ctx context.Context) error {
+		for i := range a.Times {
+			present, err := elementPresent(ctx, a.Selector)
+			if err != nil {
+				return err
+			}
+			if !present {
+				if i == 0 {
+					return fmt.Errorf("click: no element matches %q", a.Selector)
+				}
+				logger.Debug("click target gone", "selector", a.Selector, "clicks", i)
+				return nil
+			}
+			// A JS click keeps working when the button is scrolled off screen
+			if err := chromedp.Evaluate(`document.querySelector(`+jsString(a.Selector)+`).click()`, nil).Do(ctx); err != nil {
+				return err
+			}
+			if err := chromedp.Sleep(pause).Do(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// selectOption chooses value in a <select> and fires the events that
+// frameworks listen for.
+func selectOption(selector, value string) chromedp.Tasks {
+	script := `(() => {
+		const el = document.querySelector(` + jsString(selector) + `);
+		el.value = ` + jsString(value) + `;
+		el.dispatchEvent(new Event("input", {bubbles: true}));
+		el.dispatchEvent(new Event("change", {bubbles: true}));
+	})()`
+	return chromedp.Tasks{
+		chromedp.WaitReady(selector, chromedp.ByQuery),
+		chromedp.Evaluate(script, nil),
+	}
+}
+
+// scrollToBottom scrolls to the end of the page up to a.Times times, stopping
+// once a scroll no longer adds elements (matching a.Selector, if set).
+func scrollToBottom(a fetcher.Action) chromedp.ActionFunc {
+	times := max(a.Times, 1)
+	pause := durationOr(a.Duration, defaultActionPause)
+	selector := a.Selector
+	if selector == "" {
+		selector = "*"
+	}
+	count := `document.querySelectorAll(` + jsString(selector) + `).length`
+
+	return func(ctx context.Context) error {
+		var before int
+		if err := chromedp.Evaluate(count, &before).Do(ctx); err != nil {
+			return err
+		}
+		for i := range times {
+			if err := chromedp.Evaluate(`window.scrollTo(0, document.scrollingElement.scrollHeight)`, nil).Do(ctx); err != nil {
+				return err
+			}
+			if err := chromedp.Sleep(pause).Do(ctx); err != nil {
+				return err
+			}
+			var after int
+			if err := chromedp.Evaluate(count, &after).Do(ctx); err != nil {
+				return err
+			}
+			if after <= before {
+				logger.Debug("scrolling stopped, no new content", "scrolls", i+1, "elements", after)
+				return nil
+			}
+			before = after
+		}
+		return nil
+	}
+}
+
+// waitNetworkIdle waits until no requests have been in flight for quiet.
+func waitNetworkIdle(quiet time.Duration) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		var mu sync.Mutex
+		inflight := make(map[network.RequestID]bool)
+		lastActivity := time.Now()
+
+		listenCtx, stopListening := context.WithCancel(ctx)
+		defer stopListening()
+		chromedp.ListenTarget(listenCtx, func(ev any) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch e := ev.(type) {
+			case *network.EventRequestWillBeSent:
+				inflight[e.RequestID] = true
+			case *network.EventLoadingFinished:
+				delete(inflight, e.RequestID)
+			case *network.EventLoadingFailed:
+				delete(inflight, e.RequestID)
+			default:
+				return
+			}
+			lastActivity = time.Now()
+		})
+		if err := network.Enable().Do(ctx); err != nil {
+			return err
+		}
+
+		deadline := time.Now().Add(maxNetworkIdleWait)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+			mu.Lock()
+			idle := len(inflight) == 0 && time.Since(lastActivity) >= quiet
+			pending := len(inflight)
+			mu.Unlock()
+			if idle {
+				return nil
+			}
+			if time.Now().After(deadline) {
+				logger.Debug("network not idle, continuing", "pending_requests", pending)
+				return nil
+			}
+		}
+	}
+}
+
+// elementPresent reports whether an element currently matches selector.
+func elementPresent(ctx context.Context, selector string) (bool, error) {
+	var present bool
+	err := chromedp.Evaluate(`document.querySelector(`+jsString(selector)+`) !== null`, &present).Do(ctx)
+	return present, err
+}
+
+// jsString quotes s as a JavaScript string literal.
+func jsString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// durationOr returns d, or def if d is zero.
+func durationOr(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
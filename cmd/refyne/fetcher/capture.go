@@ -0,0 +1,131 @@
+package fetcher
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+)
+
+// resourceRecorder records the XHR/fetch responses matching a capture config
+// while a page renders.
+type resourceRecorder struct {
+	config fetcher.CaptureConfig
+
+	stop     context.CancelFunc
+	mu       sync.Mutex
+	closed   bool
+	requests map[network.RequestID]capturedResource // XHR/fetch requests seen so far
+	pending  map[network.RequestID]*capturedResource
+	captured []*capturedResource
+	wg       sync.WaitGroup
+}
+
+// capturedResource is a matching response, in the order its request was seen.
+type capturedResource struct {
+	seq      int
+	resource fetcher.Resource
+}
+
+// newResourceRecorder starts recording responses on the browser tab in ctx.
+// Recording stops when Resources is called or ctx is cancelled.
+func newResourceRecorder(ctx context.Context, cfg fetcher.CaptureConfig) *resourceRecorder {
+	listenCtx, stop := context.WithCancel(ctx)
+	r := &resourceRecorder{
+		config:   cfg,
+		stop:     stop,
+		requests: make(map[network.RequestID]capturedResource),
+		pending:  make(map[network.RequestID]*capturedResource),
+	}
+	chromedp.ListenTarget(listenCtx, func(ev any) {
+		r.handle(ctx, ev)
+	})
+	return r
+}
+
+// handle tracks XHR/fetch requests and reads the bodies of matching responses
+// once they have finished loading.
+func (r *resourceRecorder) handle(ctx context.Context, ev any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+
+	switch e := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		if e.Type == network.ResourceTypeXHR || e.Type == network.ResourceTypeFetch {
+			r.requests[e.RequestID] = capturedResource{
+				seq:      len(r.requests),
+				resource: fetcher.Resource{Method: e.Request.Method},
+			}
+		}
+	case *network.EventResponseReceived:
+		req, ok := r.requests[e.RequestID]
+		if !ok || !r.config.Matches(e.Response.URL, e.Response.MimeType) {
+			return
+		}
+		req.resource.URL = e.Response.URL
+		req.resource.StatusCode = int(e.Response.Status)
+		req.resource.ContentType = e.Response.MimeType
+		r.pending[e.RequestID] = &req
+	case *network.EventLoadingFinished:
+		res, ok := r.pending[e.RequestID]
+		if !ok {
+			return
+		}
+		delete(r.pending, e.RequestID)
+
+		// Listeners must not block, so read the body in the background
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			tab := chromedp.FromContext(ctx)
+			if tab == nil || tab.Target == nil {
+				return
+			}
+			body, err := network.GetResponseBody(e.RequestID).Do(cdp.WithExecutor(ctx, tab.Target))
+			if err != nil {
+				logger.Debug("failed to read captured response", "url", res.resource.URL, "error", err)
+				return
+			}
+			if len(body) > r.config.MaxBodySize() {
+				logger.Debug("captured response too large, skipping", "url", res.resource.URL, "bytes", len(body))
+				return
+			}
+			res.resource.Body = string(body)
+
+			r.mu.Lock()
+			r.captured = append(r.captured, res)
+			r.mu.Unlock()
+		}()
+	case *network.EventLoadingFailed:
+		delete(r.pending, e.RequestID)
+	}
+}
+
+// Resources waits for outstanding body reads and returns the captured
+// responses in request order. Responses still loading are not included.
+func (r *resourceRecorder) Resources() []fetcher.Resource {
+	r.stop()
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+
+	r.wg.Wait()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	slices.SortFunc(r.captured, func(a, b *capturedResource) int { return a.seq - b.seq })
+	resources := make([]fetcher.Resource, len(r.captured))
+	for i, c := range r.captured {
+		resources[i] = c.resource
+	}
+	return resources
+}
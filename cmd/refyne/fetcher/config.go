@@ -5,6 +5,8 @@ package fetcher
 
 import (
 	"time"
+
+	"github.com/jmylchreest/refyne/pkg/fetcher"
 )
 
 // Config holds configuration for the dynamic fetcher.
@@ -14,13 +16,30 @@ type Config struct {
 	Stealth         bool   // Enable anti-bot detection evasion
 	Googlebot       bool   // Spoof Googlebot user-agent
 	FlareSolverrURL string // FlareSolverr API URL for Cloudflare bypass
+
+	Retry fetcher.RetryPolicy // Retry policy for failed page loads (zero value = no retries)
+
+	// Browser tab pool. Tabs share one Chrome process and are reused across pages.
+	MaxTabs     int // Max tabs open at once; match the crawl concurrency (default: 4)
+	TabMaxPages int // Pages a tab loads before it is replaced (default: 50)
+
+	// Session state, shared with the browser on every page load
+	CookieJar   *fetcher.CookieJar // Cookie jar (default: a new jar per fetcher)
+	SaveCookies string             // If set, the jar is written to this file on Close
+
+	// Remote browser. When set, pages load in an already running Chrome over
+	// its DevTools endpoint instead of a locally launched one.
+	ChromeURL string // e.g. ws://localhost:9222, http://chrome:9222 or a ws://.../devtools/browser/<id> URL
 }
 
 // DefaultConfig returns sensible defaults.
 func DefaultConfig() Config {
 	return Config{
-		UserAgent: defaultUserAgent,
-		Timeout:   30 * time.Second,
+		UserAgent:   defaultUserAgent,
+		Timeout:     30 * time.Second,
+		Retry:       fetcher.DefaultRetryPolicy(),
+		MaxTabs:     DefaultMaxTabs,
+		TabMaxPages: DefaultTabMaxPages,
 	}
 }
 
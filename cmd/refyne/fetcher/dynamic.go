@@ -2,7 +2,9 @@ package fetcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -11,13 +13,20 @@ import (
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
 	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/storage"
 	"github.com/chromedp/chromedp"
 
 	"github.com/jmylchreest/refyne/internal/logger"
 	"github.com/jmylchreest/refyne/pkg/fetcher"
 )
 
+// errBrowserLost reports a page load cut short because the browser exited or
+// the connection to a remote browser dropped.
+var errBrowserLost = errors.New("lost connection to browser")
+
 // DynamicFetcher uses chromedp for JavaScript-rendered pages.
 // It supports stealth mode, Googlebot spoofing, and FlareSolverr integration.
 type DynamicFetcher struct {
@@ -25,6 +34,8 @@ type DynamicFetcher struct {
 	allocCtx     context.Context
 	cancelCtx    context.CancelFunc
 	flareSolverr *FlareSolverr
+	jar          *fetcher.CookieJar
+	pool         *tabPool
 
 	// Session cache for reusing FlareSolverr sessions per domain.
 	// Sessions keep the same browser instance running in FlareSolverr,
@@ -41,13 +52,74 @@ func NewDynamicFetcher(cfg Config) (*DynamicFetcher, error) {
 	if cfg.Timeout == 0 {
 		cfg.Timeout = DefaultConfig().Timeout
 	}
+	if cfg.MaxTabs <= 0 {
+		cfg.MaxTabs = DefaultMaxTabs
+	}
 
 	// Override user-agent for Googlebot mode
 	if cfg.Googlebot {
 		cfg.UserAgent = GooglebotMobileUserAgent
 	}
 
-	// Create browser allocator with options
+	// Launch a local Chrome, or connect to a running one
+	var allocCtx context.Context
+	var cancelAlloc context.CancelFunc
+	if cfg.ChromeURL != "" {
+		if err := validateChromeURL(cfg.ChromeURL); err != nil {
+			return nil, err
+		}
+		allocCtx, cancelAlloc = chromedp.NewRemoteAllocator(context.Background(), cfg.ChromeURL)
+	} else {
+		allocCtx, cancelAlloc = chromedp.NewExecAllocator(context.Background(), execAllocatorOptions(cfg)...)
+	}
+
+	// Create FlareSolverr client if URL is configured
+	var fs *FlareSolverr
+	if cfg.FlareSolverrURL != "" {
+		fs = NewFlareSolverr(cfg.FlareSolverrURL)
+	}
+
+	jar := cfg.CookieJar
+	if jar == nil {
+		jar = fetcher.NewCookieJar()
+	}
+
+	// A remote browser was started with its own flags, so the window size the
+	// local path sets on the command line is set per tab
+	var tabSetup []chromedp.Action
+	if cfg.ChromeURL != "" {
+		tabSetup = append(tabSetup, emulation.SetDeviceMetricsOverride(1920, 1080, 1, false))
+	}
+
+	// Inject the stealth script into every tab before its first navigation
+	if cfg.Stealth {
+		tabSetup = append(tabSetup, InjectStealthScript())
+	}
+
+	logger.Debug("dynamic fetcher created",
+		"stealth", cfg.Stealth,
+		"max_tabs", cfg.MaxTabs,
+		"googlebot", cfg.Googlebot,
+		"flaresolverr", cfg.FlareSolverrURL != "",
+		"remote", cfg.ChromeURL != "",
+		"timeout", cfg.Timeout)
+
+	pool := newTabPool(allocCtx, cfg.MaxTabs, cfg.TabMaxPages, tabSetup...)
+	pool.remote = cfg.ChromeURL != ""
+
+	return &DynamicFetcher{
+		config:       cfg,
+		allocCtx:     allocCtx,
+		cancelCtx:    cancelAlloc,
+		flareSolverr: fs,
+		jar:          jar,
+		pool:         pool,
+		sessions:     make(map[string]string),
+	}, nil
+}
+
+// execAllocatorOptions returns the flags for launching a local Chrome.
+func execAllocatorOptions(cfg Config) []chromedp.ExecAllocatorOption {
 	var opts []chromedp.ExecAllocatorOption
 	if cfg.Stealth {
 		// Use stealth options to avoid bot detection
@@ -69,29 +141,26 @@ func NewDynamicFetcher(cfg Config) (*DynamicFetcher, error) {
 		opts = append(opts, chromedp.ExecPath(chromePath))
 	}
 
-	opts = append(opts, chromedp.UserAgent(cfg.UserAgent))
-
-	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), opts...)
+	return append(opts, chromedp.UserAgent(cfg.UserAgent))
+}
 
-	// Create FlareSolverr client if URL is configured
-	var fs *FlareSolverr
-	if cfg.FlareSolverrURL != "" {
-		fs = NewFlareSolverr(cfg.FlareSolverrURL)
+// validateChromeURL checks a remote DevTools endpoint. Endpoints without a
+// /devtools/browser/ path are resolved through /json/version on each connect,
+// so a restarted browser is found again.
+func validateChromeURL(chromeURL string) error {
+	u, err := url.Parse(chromeURL)
+	if err != nil {
+		return fmt.Errorf("invalid Chrome URL: %w", err)
 	}
-
-	logger.Debug("dynamic fetcher created",
-		"stealth", cfg.Stealth,
-		"googlebot", cfg.Googlebot,
-		"flaresolverr", cfg.FlareSolverrURL != "",
-		"timeout", cfg.Timeout)
-
-	return &DynamicFetcher{
-		config:       cfg,
-		allocCtx:     allocCtx,
-		cancelCtx:    cancelAlloc,
-		flareSolverr: fs,
-		sessions:     make(map[string]string),
-	}, nil
+	switch u.Scheme {
+	case "ws", "wss", "http", "https":
+	default:
+		return fmt.Errorf("invalid Chrome URL %q: scheme must be ws, wss, http or https", chromeURL)
+	}
+	if u.Port() == "" && !strings.Contains(u.Path, "/devtools/browser/") {
+		return fmt.Errorf("invalid Chrome URL %q: missing DevTools port (e.g. :9222)", chromeURL)
+	}
+	return nil
 }
 
 // getSession returns the session ID for a domain, or empty string if none exists.
@@ -127,8 +196,16 @@ func (f *DynamicFetcher) getOrCreateSession(ctx context.Context, domain string)
 	return sessionID, nil
 }
 
-// Fetch retrieves page content using a headless browser.
+// Fetch retrieves page content using a headless browser, retrying according to
+// the configured policy.
 func (f *DynamicFetcher) Fetch(ctx context.Context, targetURL string, opts fetcher.Options) (fetcher.Content, error) {
+	return f.config.Retry.Do(ctx, targetURL, func(ctx context.Context) (fetcher.Content, error) {
+		return f.fetchOnce(ctx, targetURL, opts)
+	})
+}
+
+// fetchOnce makes a single attempt, via FlareSolverr if configured.
+func (f *DynamicFetcher) fetchOnce(ctx context.Context, targetURL string, opts fetcher.Options) (fetcher.Content, error) {
 	logger.Debug("dynamic fetch",
 		"url", targetURL,
 		"stealth", f.config.Stealth,
@@ -141,6 +218,8 @@ func (f *DynamicFetcher) Fetch(ctx context.Context, targetURL string, opts fetch
 	}
 	domain := parsedURL.Host
 
+	f.jar.AddCookies(targetURL, opts.Cookies)
+
 	// If FlareSolverr is configured, use sessions to avoid repeated challenges
 	if f.flareSolverr != nil {
 		// Get or create a persistent session for this domain
@@ -155,13 +234,21 @@ func (f *DynamicFetcher) Fetch(ctx context.Context, targetURL string, opts fetch
 				return fetcher.Content{URL: targetURL, FetchedAt: time.Now()}, err
 			}
 
-			// FlareSolverr returns the page content directly
-			if solution.Response != "" {
+			// Keep clearance cookies for later requests (including browser fetches)
+			f.jar.Import(solution.HTTPCookies())
+
+			// FlareSolverr returns the page content directly. Pages with actions,
+			// resource capture, screenshots or a request body are loaded in the
+			// browser instead, reusing the clearance cookies.
+			method, _, body := opts.RequestBody()
+			if solution.Response != "" && len(opts.Actions) == 0 && opts.Capture == nil && opts.Screenshot == nil && isPlainGet(method, body) {
 				result := fetcher.Content{
 					URL:        targetURL,
 					FetchedAt:  time.Now(),
 					HTML:       solution.Response,
 					StatusCode: solution.Status,
+					FinalURL:   solution.URL,
+					Headers:    flareSolverrHeaders(solution.Headers),
 				}
 
 				// Detect challenge pages in the response
@@ -185,13 +272,20 @@ func (f *DynamicFetcher) Fetch(ctx context.Context, targetURL string, opts fetch
 				return result, nil
 			}
 
-			// FlareSolverr didn't return content (unusual) - fall through to chromedp
-			logger.Debug("FlareSolverr returned no response content, falling back to chromedp")
+			// FlareSolverr didn't return content (unusual), or the page needs
+			// the browser - fall through to chromedp
+			logger.Debug("continuing with chromedp after FlareSolverr", "actions", len(opts.Actions))
 		}
 	}
 
 	// Fetch using browser (no FlareSolverr, or FlareSolverr returned no content)
-	return f.fetchWithBrowser(ctx, targetURL, opts)
+	result, err := f.fetchWithBrowser(ctx, targetURL, opts)
+	if errors.Is(err, errBrowserLost) && ctx.Err() == nil {
+		// The page didn't fail, the browser did; try again on a new one
+		logger.Warn("browser lost during fetch, retrying", "url", targetURL, "remote", f.config.ChromeURL != "")
+		return f.fetchWithBrowser(ctx, targetURL, opts)
+	}
+	return result, err
 }
 
 // fetchWithBrowser fetches a page using chromedp with the given options.
@@ -201,15 +295,14 @@ func (f *DynamicFetcher) fetchWithBrowser(ctx context.Context, targetURL string,
 		FetchedAt: time.Now(),
 	}
 
-	logger.Debug("chromedp starting browser context", "url", targetURL)
-
-	// Create a new browser context for this request
-	browserCtx, cancelBrowser := chromedp.NewContext(f.allocCtx,
-		chromedp.WithLogf(func(format string, args ...interface{}) {
-			logger.Debug("chromedp", "msg", fmt.Sprintf(format, args...))
-		}),
-	)
-	defer cancelBrowser()
+	// Borrow a tab from the pool; this blocks while all tabs are busy
+	tab, err := f.pool.acquire(ctx)
+	if err != nil {
+		return result, fmt.Errorf("browser unavailable: %w", err)
+	}
+	failed := true
+	defer func() { f.pool.release(tab, failed) }()
+	logger.Debug("chromedp using browser tab", "url", targetURL, "tab_pages", tab.pages)
 
 	// Set timeout
 	timeout := opts.Timeout
@@ -217,23 +310,48 @@ func (f *DynamicFetcher) fetchWithBrowser(ctx context.Context, targetURL string,
 		timeout = f.config.Timeout
 	}
 
-	timeoutCtx, cancelTimeout := context.WithTimeout(browserCtx, timeout)
+	timeoutCtx, cancelTimeout := context.WithTimeout(tab.ctx, timeout)
 	defer cancelTimeout()
+	stop := context.AfterFunc(ctx, cancelTimeout)
+	defer stop()
+
+	// Record the main document's response and redirects
+	document := newDocumentRecorder(timeoutCtx)
+
+	// Record the JSON the page loads through XHR/fetch if requested
+	var recorder *resourceRecorder
+	if opts.Capture != nil {
+		recorder = newResourceRecorder(timeoutCtx, *opts.Capture)
+	}
 
 	// Build the actions
 	var html string
 	var title string
+	var characterSet string
 	var actions []chromedp.Action
 
-	// Set cookies before navigation (e.g., cf_clearance from FlareSolverr)
-	if len(opts.Cookies) > 0 {
-		actions = append(actions, setCookies(targetURL, opts.Cookies))
+	// Apply the request's user agent and headers (e.g., from fetch middleware).
+	// Tabs are reused, so both are set on every page.
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = f.config.UserAgent
 	}
+	actions = append(actions,
+		emulation.SetUserAgentOverride(userAgent),
+		network.SetExtraHTTPHeaders(cdpRequestHeaders(opts.Headers)),
+	)
+
+	// Set cookies before navigation (e.g., cf_clearance from FlareSolverr, or a
+	// session from an earlier page)
+	actions = append(actions, loadCookies(targetURL, f.jar))
 
-	if f.config.Stealth {
-		// Inject stealth script before navigation to evade bot detection
-		actions = append(actions, InjectStealthScript())
+	// Navigate with the request's method and body (e.g., a search form post)
+	if method, contentType, body := opts.RequestBody(); !isPlainGet(method, body) {
+		rewriter, enable := newNavigationRewriter(timeoutCtx, method, contentType, body)
+		defer rewriter.Stop(tab.ctx)
+		actions = append(actions, enable)
 	}
+
 	actions = append(actions, chromedp.Navigate(targetURL))
 
 	// Wait for selector if specified
@@ -244,15 +362,31 @@ func (f *DynamicFetcher) fetchWithBrowser(ctx context.Context, targetURL string,
 		actions = append(actions, chromedp.WaitReady("body"))
 	}
 
+	// Interact with the page (consent dialogs, "load more" buttons, infinite scroll)
+	if len(opts.Actions) > 0 {
+		steps, err := browserActions(targetURL, opts.Actions)
+		if err != nil {
+			return result, err
+		}
+		actions = append(actions, steps...)
+	}
+
 	// Additional wait if specified
 	if opts.WaitDuration > 0 {
 		actions = append(actions, chromedp.Sleep(opts.WaitDuration))
 	}
 
+	// Capture images for vision extraction once the page has settled
+	if opts.Screenshot != nil {
+		actions = append(actions, captureImages(*opts.Screenshot, &result))
+	}
+
 	// Extract content
 	actions = append(actions,
 		chromedp.OuterHTML("html", &html),
 		chromedp.Title(&title),
+		chromedp.Evaluate(`document.characterSet`, &characterSet), // The browser has already decoded the page
+		saveCookies(f.jar),
 	)
 
 	// Execute actions
@@ -261,16 +395,20 @@ func (f *DynamicFetcher) fetchWithBrowser(ctx context.Context, targetURL string,
 		"action_count", len(actions),
 		"timeout", timeout,
 		"stealth", f.config.Stealth,
-		"cookies", len(opts.Cookies))
+		"cookies", f.jar.Len())
 
 	if err := chromedp.Run(timeoutCtx, actions...); err != nil {
 		// Attempt to capture a debug screenshot on failure
-		if screenshot := CaptureScreenshotOnError(browserCtx); screenshot != nil {
+		if screenshot := CaptureScreenshotOnError(tab.ctx); screenshot != nil {
 			screenshotPath := filepath.Join(os.TempDir(), fmt.Sprintf("refyne-debug-%d.png", time.Now().UnixNano()))
 			if writeErr := os.WriteFile(screenshotPath, screenshot, 0644); writeErr == nil {
 				logger.Debug("debug screenshot saved", "path", screenshotPath)
 			}
 		}
+		// The browser crashed or the connection to it dropped mid-page
+		if tab.ctx.Err() != nil && ctx.Err() == nil {
+			return result, fmt.Errorf("%w: %v", errBrowserLost, err)
+		}
 		// Check if this looks like a timeout (likely anti-bot blocking)
 		if ctx.Err() != nil || strings.Contains(err.Error(), "deadline exceeded") {
 			logger.Warn("browser timeout - possible anti-bot protection", "url", targetURL)
@@ -279,9 +417,19 @@ func (f *DynamicFetcher) fetchWithBrowser(ctx context.Context, targetURL string,
 		return result, fmt.Errorf("browser automation failed: %w", err)
 	}
 
+	failed = false
 	result.HTML = html
 	result.Title = title
-	result.StatusCode = 200 // chromedp doesn't easily expose status codes
+	result.Charset = strings.ToLower(characterSet)
+	if !document.Apply(&result) {
+		result.StatusCode = http.StatusOK // No response seen, but the page rendered
+	}
+	if recorder != nil {
+		result.Resources = recorder.Resources()
+	}
+	if len(result.RedirectChain) > 0 {
+		logger.Debug("browser followed redirects", "url", targetURL, "final_url", result.FinalURL, "hops", len(result.RedirectChain))
+	}
 
 	// Detect challenge pages in the response
 	if challenge := detectChallengePage(title, html); challenge != "" {
@@ -289,6 +437,11 @@ func (f *DynamicFetcher) fetchWithBrowser(ctx context.Context, targetURL string,
 		return result, fmt.Errorf("%w: %s", fetcher.ErrAntiBot, challenge)
 	}
 
+	// Like the static fetcher, error pages are failures, not content
+	if result.StatusCode >= http.StatusBadRequest {
+		return result, fmt.Errorf("fetch error: %d %s", result.StatusCode, http.StatusText(result.StatusCode))
+	}
+
 	// Parse content
 	if err := f.parseContent(&result); err != nil {
 		return result, fmt.Errorf("failed to parse content: %w", err)
@@ -298,11 +451,44 @@ func (f *DynamicFetcher) fetchWithBrowser(ctx context.Context, targetURL string,
 		"url", targetURL,
 		"title", title,
 		"text_size", len(result.Text),
-		"links", len(result.Links))
+		"links", len(result.Links),
+		"resources", len(result.Resources))
 
 	return result, nil
 }
 
+// RunActions runs a browser action script starting from baseURL (e.g., a
+// login flow). Cookies from the jar are loaded first and the browser's cookies
+// are saved back afterwards, so later fetches share the session.
+func (f *DynamicFetcher) RunActions(ctx context.Context, baseURL string, actions []fetcher.Action) error {
+	steps, err := browserActions(baseURL, actions)
+	if err != nil {
+		return err
+	}
+
+	tab, err := f.pool.acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("browser unavailable: %w", err)
+	}
+	failed := true
+	defer func() { f.pool.release(tab, failed) }()
+
+	timeoutCtx, cancelTimeout := context.WithTimeout(tab.ctx, f.config.Timeout)
+	defer cancelTimeout()
+	stop := context.AfterFunc(ctx, cancelTimeout)
+	defer stop()
+
+	run := append([]chromedp.Action{loadCookies(baseURL, f.jar)}, steps...)
+	run = append(run, saveCookies(f.jar))
+
+	logger.Debug("chromedp running action script", "url", baseURL, "steps", len(actions))
+	if err := chromedp.Run(timeoutCtx, run...); err != nil {
+		return fmt.Errorf("action script failed: %w", err)
+	}
+	failed = false
+	return nil
+}
+
 // detectChallengePage checks if the page content indicates a challenge/CAPTCHA page.
 func detectChallengePage(title, html string) string {
 	titleLower := strings.ToLower(title)
@@ -370,8 +556,8 @@ func (f *DynamicFetcher) parseContent(content *fetcher.Content) error {
 	})
 	content.Text = strings.Join(textParts, "\n")
 
-	// Extract links
-	baseURL, _ := url.Parse(content.URL)
+	// Extract links, resolving them against the URL after any redirects
+	baseURL, _ := url.Parse(content.PageURL())
 	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
 		href, exists := s.Attr("href")
 		if !exists || href == "" || strings.HasPrefix(href, "#") {
@@ -401,7 +587,13 @@ func cleanText(s string) string {
 	return strings.Join(parts, " ")
 }
 
-// Close releases browser resources and destroys FlareSolverr sessions.
+// CookieJar returns the fetcher's cookie jar.
+func (f *DynamicFetcher) CookieJar() *fetcher.CookieJar {
+	return f.jar
+}
+
+// Close releases browser resources, destroys FlareSolverr sessions and saves
+// the cookie jar if configured.
 func (f *DynamicFetcher) Close() error {
 	// Destroy FlareSolverr sessions
 	if f.flareSolverr != nil {
@@ -420,9 +612,17 @@ func (f *DynamicFetcher) Close() error {
 		}
 	}
 
+	f.pool.close()
 	if f.cancelCtx != nil {
 		f.cancelCtx()
 	}
+
+	if f.config.SaveCookies != "" {
+		if err := f.jar.SaveFile(f.config.SaveCookies); err != nil {
+			return err
+		}
+		logger.Debug("cookies saved", "path", f.config.SaveCookies, "count", f.jar.Len())
+	}
 	return nil
 }
 
@@ -431,29 +631,79 @@ func (f *DynamicFetcher) Type() string {
 	return "dynamic"
 }
 
-// setCookies returns a chromedp action that sets cookies before navigation.
-func setCookies(targetURL string, cookies []fetcher.Cookie) chromedp.Action {
+// loadCookies returns a chromedp action that copies the jar's cookies for
+// targetURL into the browser before navigation.
+func loadCookies(targetURL string, jar *fetcher.CookieJar) chromedp.Action {
 	return chromedp.ActionFunc(func(ctx context.Context) error {
 		u, err := url.Parse(targetURL)
 		if err != nil {
 			return fmt.Errorf("failed to parse URL for cookies: %w", err)
 		}
 
-		var cookieParams []*network.CookieParam
+		cookies := jar.Export(u)
+		if len(cookies) == 0 {
+			return nil
+		}
+
+		cookieParams := make([]*network.CookieParam, 0, len(cookies))
 		for _, c := range cookies {
-			domain := c.Domain
-			if domain == "" {
-				domain = u.Host
+			param := &network.CookieParam{
+				Name:     c.Name,
+				Value:    c.Value,
+				Path:     c.Path,
+				Secure:   c.Secure,
+				HTTPOnly: c.HttpOnly,
+			}
+			if strings.HasPrefix(c.Domain, ".") {
+				param.Domain = c.Domain
+			} else {
+				// Host-only cookies are scoped by URL rather than Domain
+				param.URL = u.Scheme + "://" + c.Domain + c.Path
+			}
+			if !c.Expires.IsZero() {
+				expires := cdp.TimeSinceEpoch(c.Expires)
+				param.Expires = &expires
 			}
-			cookieParams = append(cookieParams, &network.CookieParam{
-				Name:   c.Name,
-				Value:  c.Value,
-				Domain: domain,
-				Path:   "/",
-				Secure: u.Scheme == "https",
-			})
+			cookieParams = append(cookieParams, param)
 		}
 
 		return network.SetCookies(cookieParams).Do(ctx)
 	})
 }
+
+// saveCookies returns a chromedp action that copies the browser's cookies back
+// into the jar, so cookies set by the page (including from JavaScript) survive
+// into later requests.
+func saveCookies(jar *fetcher.CookieJar) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		cookies, err := storage.GetCookies().Do(ctx)
+		if err != nil {
+			// The page itself loaded fine; losing its cookies isn't worth failing it
+			logger.Debug("failed to read browser cookies", "error", err)
+			return nil
+		}
+		jar.Import(browserCookies(cookies))
+		return nil
+	})
+}
+
+// browserCookies converts CDP cookies to http.Cookies using the jar's Domain
+// convention (Chrome also marks domain cookies with a leading dot).
+func browserCookies(cookies []*network.Cookie) []*http.Cookie {
+	converted := make([]*http.Cookie, 0, len(cookies))
+	for _, c := range cookies {
+		cookie := &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+		}
+		if !c.Session && c.Expires > 0 {
+			cookie.Expires = time.Unix(0, int64(c.Expires*float64(time.Second)))
+		}
+		converted = append(converted, cookie)
+	}
+	return converted
+}
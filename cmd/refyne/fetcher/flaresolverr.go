@@ -302,6 +302,27 @@ func (f *FlareSolverr) classifyError(url, message string) error {
 	return fmt.Errorf("%w: %s", fetcher.ErrAntiBot, message)
 }
 
+// HTTPCookies converts FlareSolverr cookies to http.Cookies with their full
+// attributes, for fetcher.CookieJar.Import.
+func (s *FlareSolverSolution) HTTPCookies() []*http.Cookie {
+	cookies := make([]*http.Cookie, 0, len(s.Cookies))
+	for _, c := range s.Cookies {
+		cookie := &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+		}
+		if !c.Session && c.Expires > 0 {
+			cookie.Expires = time.Unix(0, int64(c.Expires*float64(time.Second)))
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies
+}
+
 // ToCookies converts FlareSolverr cookies to the fetcher.Cookie type.
 func (s *FlareSolverSolution) ToCookies() []fetcher.Cookie {
 	cookies := make([]fetcher.Cookie, 0, len(s.Cookies))
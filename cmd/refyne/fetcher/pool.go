@@ -0,0 +1,238 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+)
+
+const (
+	// DefaultMaxTabs is the default number of browser tabs open at once.
+	DefaultMaxTabs = 4
+	// DefaultTabMaxPages is the default number of pages a tab loads before it
+	// is closed and replaced, which keeps memory leaks in long crawls in check.
+	DefaultTabMaxPages = 50
+	// tabHealthTimeout bounds the liveness check run before an idle tab is reused.
+	tabHealthTimeout = 5 * time.Second
+)
+
+// errPoolClosed is returned when acquiring a tab from a closed pool.
+var errPoolClosed = errors.New("browser tab pool is closed")
+
+// browserTab is a tab in the pool's browser.
+type browserTab struct {
+	ctx    context.Context // chromedp context for the tab
+	cancel context.CancelFunc
+	pages  int
+}
+
+// tabPool keeps a bounded set of warm tabs in one browser process. The browser
+// starts on first use and is restarted if it crashes. A remote browser is
+// connected to instead, and reconnected to if the connection drops.
+type tabPool struct {
+	allocCtx context.Context
+	size     int
+	maxPages int
+	setup    []chromedp.Action // Run once on each new tab (e.g., stealth scripts)
+	remote   bool              // allocCtx connects to a running browser
+
+	slots chan struct{} // One token per tab in use
+
+	mu            sync.Mutex
+	browserCtx    context.Context
+	cancelBrowser context.CancelFunc
+	idle          []*browserTab
+	closed        bool
+}
+
+// newTabPool creates a pool of at most size tabs, each recycled after maxPages pages.
+func newTabPool(allocCtx context.Context, size, maxPages int, setup ...chromedp.Action) *tabPool {
+	if size <= 0 {
+		size = DefaultMaxTabs
+	}
+	if maxPages <= 0 {
+		maxPages = DefaultTabMaxPages
+	}
+	return &tabPool{
+		allocCtx: allocCtx,
+		size:     size,
+		maxPages: maxPages,
+		setup:    setup,
+		slots:    make(chan struct{}, size),
+	}
+}
+
+// acquire waits for a free slot and returns a healthy tab, reusing an idle one
+// when possible. The tab must be returned with release.
+func (p *tabPool) acquire(ctx context.Context) (*browserTab, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	tab, err := p.take(ctx)
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+	return tab, nil
+}
+
+// take returns a healthy idle tab or opens a new one.
+func (p *tabPool) take(ctx context.Context) (*browserTab, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, errPoolClosed
+		}
+		if len(p.idle) == 0 {
+			p.mu.Unlock()
+			return p.open(ctx)
+		}
+		tab := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.mu.Unlock()
+
+		err := p.check(tab)
+		if err == nil {
+			return tab, nil
+		}
+		logger.Debug("discarding unhealthy browser tab", "error", err)
+		tab.cancel()
+	}
+}
+
+// open starts the browser if needed and opens a new tab in it.
+func (p *tabPool) open(ctx context.Context) (*browserTab, error) {
+	browserCtx, err := p.browser()
+	if err != nil {
+		return nil, err
+	}
+
+	tabCtx, cancel := chromedp.NewContext(browserCtx, chromedp.WithLogf(func(format string, args ...interface{}) {
+		logger.Debug("chromedp", "msg", fmt.Sprintf(format, args...))
+	}))
+	stop := context.AfterFunc(ctx, cancel)
+	err = chromedp.Run(tabCtx, p.setup...)
+	stop()
+	if err != nil {
+		cancel()
+		if browserCtx.Err() != nil {
+			p.resetBrowser(browserCtx)
+		}
+		return nil, fmt.Errorf("failed to open browser tab: %w", err)
+	}
+	logger.Debug("browser tab opened")
+	return &browserTab{ctx: tabCtx, cancel: cancel}, nil
+}
+
+// browser returns the browser context, starting Chrome (or connecting to the
+// remote browser) if it isn't running.
+func (p *tabPool) browser() (context.Context, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.browserCtx != nil && p.browserCtx.Err() == nil {
+		return p.browserCtx, nil
+	}
+
+	opts := []chromedp.ContextOption{chromedp.WithLogf(func(format string, args ...interface{}) {
+		logger.Debug("chromedp", "msg", fmt.Sprintf(format, args...))
+	})}
+	if p.remote {
+		// Keep our tabs and cookies apart from the browser's other clients.
+		// Closing the pool disposes of them and leaves the browser running.
+		opts = append(opts, chromedp.WithNewBrowserContext())
+		logger.Debug("connecting to remote browser")
+	} else {
+		logger.Debug("starting browser")
+	}
+	browserCtx, cancel := chromedp.NewContext(p.allocCtx, opts...)
+	if err := chromedp.Run(browserCtx); err != nil {
+		cancel()
+		if p.remote {
+			return nil, fmt.Errorf("failed to connect to browser: %w", err)
+		}
+		return nil, fmt.Errorf("failed to start browser: %w", err)
+	}
+	p.browserCtx, p.cancelBrowser = browserCtx, cancel
+	return browserCtx, nil
+}
+
+// resetBrowser discards a crashed browser and its idle tabs so the next tab
+// starts a new one.
+func (p *tabPool) resetBrowser(crashed context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.browserCtx != crashed {
+		return // already replaced
+	}
+	if p.remote {
+		logger.Warn("lost connection to browser, reconnecting on next page")
+	} else {
+		logger.Warn("browser exited, restarting on next page")
+	}
+	for _, tab := range p.idle {
+		tab.cancel()
+	}
+	p.idle = nil
+	p.cancelBrowser()
+	p.browserCtx, p.cancelBrowser = nil, nil
+}
+
+// check verifies an idle tab still responds.
+func (p *tabPool) check(tab *browserTab) error {
+	if tab.ctx.Err() != nil {
+		return tab.ctx.Err()
+	}
+	checkCtx, cancel := context.WithTimeout(tab.ctx, tabHealthTimeout)
+	defer cancel()
+	var ok bool
+	return chromedp.Run(checkCtx, chromedp.Evaluate(`true`, &ok))
+}
+
+// release returns a tab to the pool. Tabs that failed, or have loaded
+// maxPages pages, are closed instead of being reused.
+func (p *tabPool) release(tab *browserTab, failed bool) {
+	defer func() { <-p.slots }()
+	tab.pages++
+
+	p.mu.Lock()
+	reuse := !failed && !p.closed && tab.pages < p.maxPages && tab.ctx.Err() == nil
+	if reuse {
+		p.idle = append(p.idle, tab)
+	}
+	browserCtx := p.browserCtx
+	p.mu.Unlock()
+
+	if !reuse {
+		logger.Debug("closing browser tab", "pages", tab.pages, "failed", failed)
+		tab.cancel()
+		if browserCtx != nil && browserCtx.Err() != nil {
+			p.resetBrowser(browserCtx)
+		}
+	}
+}
+
+// close closes idle tabs and the browser. Tabs still in use are closed when
+// the browser exits.
+func (p *tabPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for _, tab := range p.idle {
+		tab.cancel()
+	}
+	p.idle = nil
+	if p.cancelBrowser != nil {
+		p.cancelBrowser()
+		p.browserCtx, p.cancelBrowser = nil, nil
+	}
+}
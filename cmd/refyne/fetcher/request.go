@@ -0,0 +1,117 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+)
+
+// navigationRewriter turns a tab's next top-level navigation into a request
+// with a method and body. Browsers can only navigate with a GET, so the
+// navigation is paused with the Fetch domain and continued as the request.
+type navigationRewriter struct {
+	method      string
+	contentType string
+	body        []byte
+	frameID     cdp.FrameID // Top-level frame of the tab (empty = any frame)
+
+	stop context.CancelFunc
+	mu   sync.Mutex
+	done bool // The navigation has been rewritten
+}
+
+// newNavigationRewriter starts listening on the browser tab in ctx. The
+// returned action enables interception and must run before the navigation;
+// Stop disables it again so the tab can be reused.
+func newNavigationRewriter(ctx context.Context, method, contentType string, body []byte) (*navigationRewriter, chromedp.Action) {
+	listenCtx, stop := context.WithCancel(ctx)
+	r := &navigationRewriter{method: method, contentType: contentType, body: body, stop: stop}
+	if tab := chromedp.FromContext(ctx); tab != nil && tab.Target != nil {
+		r.frameID = cdp.FrameID(tab.Target.TargetID)
+	}
+	chromedp.ListenTarget(listenCtx, func(ev any) {
+		if e, ok := ev.(*fetch.EventRequestPaused); ok {
+			r.handle(ctx, e)
+		}
+	})
+
+	enable := fetch.Enable().WithPatterns([]*fetch.RequestPattern{{
+		URLPattern:   "*",
+		ResourceType: network.ResourceTypeDocument,
+		RequestStage: fetch.RequestStageRequest,
+	}})
+	return r, enable
+}
+
+// handle rewrites the first top-level document request and lets every other
+// paused request (redirects, iframes) continue unchanged.
+func (r *navigationRewriter) handle(ctx context.Context, e *fetch.EventRequestPaused) {
+	r.mu.Lock()
+	rewrite := !r.done && e.RedirectedRequestID == "" && (r.frameID == "" || e.FrameID == r.frameID)
+	if rewrite {
+		r.done = true
+	}
+	r.mu.Unlock()
+
+	continueRequest := fetch.ContinueRequest(e.RequestID)
+	if rewrite {
+		continueRequest = continueRequest.
+			WithMethod(r.method).
+			WithPostData(base64.StdEncoding.EncodeToString(r.body)).
+			WithHeaders(requestHeaders(e.Request.Headers, r.contentType))
+		logger.Debug("rewrote browser navigation", "url", e.Request.URL, "method", r.method, "body_size", len(r.body))
+	}
+
+	// Listeners must not block, so continue the request in the background
+	go func() {
+		tab := chromedp.FromContext(ctx)
+		if tab == nil || tab.Target == nil {
+			return
+		}
+		if err := continueRequest.Do(cdp.WithExecutor(ctx, tab.Target)); err != nil {
+			logger.Debug("failed to continue paused request", "url", e.Request.URL, "error", err)
+		}
+	}()
+}
+
+// Stop stops listening and disables interception on the tab.
+func (r *navigationRewriter) Stop(ctx context.Context) {
+	r.stop()
+	tab := chromedp.FromContext(ctx)
+	if tab == nil || tab.Target == nil || ctx.Err() != nil {
+		return
+	}
+	if err := fetch.Disable().Do(cdp.WithExecutor(ctx, tab.Target)); err != nil {
+		logger.Debug("failed to disable request interception", "error", err)
+	}
+}
+
+// requestHeaders returns the paused request's headers with Content-Type set.
+func requestHeaders(headers network.Headers, contentType string) []*fetch.HeaderEntry {
+	entries := make([]*fetch.HeaderEntry, 0, len(headers)+1)
+	for name, value := range headers {
+		if contentType != "" && strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		entries = append(entries, &fetch.HeaderEntry{Name: name, Value: fmt.Sprint(value)})
+	}
+	if contentType != "" {
+		entries = append(entries, &fetch.HeaderEntry{Name: "Content-Type", Value: contentType})
+	}
+	return entries
+}
+
+// isPlainGet reports whether a request can be made with an ordinary navigation.
+func isPlainGet(method string, body []byte) bool {
+	return method == http.MethodGet && body == nil
+}
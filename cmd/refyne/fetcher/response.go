@@ -0,0 +1,126 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+)
+
+// documentRecorder records the main document's response, and the redirects
+// followed to reach it, while a page loads in a browser tab.
+type documentRecorder struct {
+	frameID cdp.FrameID // Top-level frame of the tab (empty = any frame)
+
+	stop      context.CancelFunc
+	mu        sync.Mutex
+	requestID network.RequestID // Current main document request
+	redirects []fetcher.Redirect
+	response  *network.Response
+}
+
+// newDocumentRecorder starts recording on the browser tab in ctx. Recording
+// stops when Apply is called or ctx is cancelled.
+func newDocumentRecorder(ctx context.Context) *documentRecorder {
+	listenCtx, stop := context.WithCancel(ctx)
+	r := &documentRecorder{stop: stop}
+	if tab := chromedp.FromContext(ctx); tab != nil && tab.Target != nil {
+		// A page target's main frame shares its ID
+		r.frameID = cdp.FrameID(tab.Target.TargetID)
+	}
+	chromedp.ListenTarget(listenCtx, r.handle)
+	return r
+}
+
+// handle follows top-level document requests. HTTP redirects reuse the
+// request ID; client-side redirects (meta refresh, script, or an action that
+// navigates) start a new request, and the page they left is recorded as a hop.
+func (r *documentRecorder) handle(ev any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch e := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		if e.Type != network.ResourceTypeDocument || (r.frameID != "" && e.FrameID != r.frameID) {
+			return
+		}
+		if e.RequestID == r.requestID && e.RedirectResponse != nil {
+			r.redirects = append(r.redirects, fetcher.Redirect{URL: e.RedirectResponse.URL, StatusCode: int(e.RedirectResponse.Status)})
+			return
+		}
+		if r.response != nil {
+			r.redirects = append(r.redirects, fetcher.Redirect{URL: r.response.URL, StatusCode: int(r.response.Status)})
+		}
+		r.requestID = e.RequestID
+		r.response = nil
+	case *network.EventResponseReceived:
+		if e.RequestID == r.requestID {
+			r.response = e.Response
+		}
+	}
+}
+
+// Apply stops recording and fills in the status, headers, final URL and
+// redirect chain of content. It reports false if no document response was
+// seen (for example, a page served from the back/forward cache).
+func (r *documentRecorder) Apply(content *fetcher.Content) bool {
+	r.stop()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.response == nil {
+		return false
+	}
+	content.StatusCode = int(r.response.Status)
+	content.FinalURL = r.response.URL
+	content.Headers = cdpHeaders(r.response.Headers)
+	content.ContentType = content.Headers.Get("Content-Type")
+	if content.ContentType == "" {
+		content.ContentType = r.response.MimeType
+	}
+	if len(r.redirects) > 0 {
+		content.RedirectChain = r.redirects
+	}
+	return true
+}
+
+// cdpHeaders converts DevTools headers to http.Header. Chrome joins repeated
+// headers with newlines.
+func cdpHeaders(headers network.Headers) http.Header {
+	h := make(http.Header, len(headers))
+	for name, value := range headers {
+		for _, v := range strings.Split(fmt.Sprint(value), "\n") {
+			h.Add(name, v)
+		}
+	}
+	return h
+}
+
+// cdpRequestHeaders converts request headers for Network.setExtraHTTPHeaders.
+// An empty map clears the headers set for an earlier page.
+func cdpRequestHeaders(headers map[string]string) network.Headers {
+	h := make(network.Headers, len(headers))
+	for name, value := range headers {
+		h[name] = value
+	}
+	return h
+}
+
+// flareSolverrHeaders converts the headers in a FlareSolverr solution.
+func flareSolverrHeaders(headers map[string]string) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	h := make(http.Header, len(headers))
+	for name, value := range headers {
+		h.Set(name, value)
+	}
+	return h
+}
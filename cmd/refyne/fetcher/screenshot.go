@@ -0,0 +1,123 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+)
+
+// pageImage is an image element's source and position in the document.
+type pageImage struct {
+	Src    string  `json:"src"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// captureImages returns an action that stores a full-page screenshot, and the
+// page's images if configured, in result.
+func captureImages(cfg fetcher.ScreenshotConfig, result *fetcher.Content) chromedp.ActionFunc {
+	cfg = cfg.WithDefaults()
+	return func(ctx context.Context) error {
+		var size struct {
+			Width  float64 `json:"width"`
+			Height float64 `json:"height"`
+		}
+		if err := chromedp.Evaluate(`({width: document.documentElement.scrollWidth, height: document.documentElement.scrollHeight})`, &size).Do(ctx); err != nil {
+			return fmt.Errorf("screenshot failed: %w", err)
+		}
+		height := min(size.Height, float64(cfg.MaxHeight))
+		if size.Height > height {
+			logger.Debug("page taller than screenshot limit, cutting off", "height", size.Height, "max_height", cfg.MaxHeight)
+		}
+		shot, err := captureClip(ctx, cfg, pageImage{Width: size.Width, Height: height})
+		if err != nil {
+			return fmt.Errorf("screenshot failed: %w", err)
+		}
+		result.Screenshot = shot
+
+		if cfg.Images {
+			// Page images are a bonus; a failure here keeps the screenshot
+			images, err := capturePageImages(ctx, cfg)
+			if err != nil {
+				logger.Debug("failed to capture page images", "error", err)
+			}
+			result.Images = images
+		}
+		return nil
+	}
+}
+
+// capturePageImages captures the images matching the configured selector as
+// rendered, which works for cross-origin images and picks the srcset variant
+// the page chose.
+func capturePageImages(ctx context.Context, cfg fetcher.ScreenshotConfig) ([]fetcher.Image, error) {
+	script := fmt.Sprintf(`(() => {
+		const out = [];
+		for (const img of document.querySelectorAll(%s)) {
+			const r = img.getBoundingClientRect();
+			if (r.width < %d || r.height < %d) continue;
+			out.push({src: img.currentSrc || img.src || '', x: r.left + window.scrollX, y: r.top + window.scrollY, width: r.width, height: r.height});
+			if (out.length >= %d) break;
+		}
+		return out;
+	})()`, jsString(cfg.ImageSelector), cfg.MinImageSize, cfg.MinImageSize, cfg.MaxImages)
+
+	var found []pageImage
+	if err := chromedp.Evaluate(script, &found).Do(ctx); err != nil {
+		return nil, err
+	}
+
+	images := make([]fetcher.Image, 0, len(found))
+	var errs []error
+	for _, img := range found {
+		captured, err := captureClip(ctx, cfg, img)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", img.Src, err))
+			continue
+		}
+		images = append(images, *captured)
+	}
+	logger.Debug("captured page images", "found", len(found), "captured", len(images))
+	return images, errors.Join(errs...)
+}
+
+// captureClip captures an area of the page, downscaled to the configured width.
+func captureClip(ctx context.Context, cfg fetcher.ScreenshotConfig, area pageImage) (*fetcher.Image, error) {
+	if area.Width <= 0 || area.Height <= 0 {
+		return nil, errors.New("nothing to capture")
+	}
+	scale := 1.0
+	if area.Width > float64(cfg.MaxWidth) {
+		scale = float64(cfg.MaxWidth) / area.Width
+	}
+
+	format := page.CaptureScreenshotFormatJpeg
+	if cfg.MediaType() == "image/png" {
+		format = page.CaptureScreenshotFormatPng
+	}
+	data, err := page.CaptureScreenshot().
+		WithFormat(format).
+		WithQuality(int64(cfg.Quality)).
+		WithCaptureBeyondViewport(true).
+		WithFromSurface(true).
+		WithClip(&page.Viewport{X: area.X, Y: area.Y, Width: area.Width, Height: area.Height, Scale: scale}).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &fetcher.Image{
+		URL:       area.Src,
+		MediaType: cfg.MediaType(),
+		Data:      data,
+		Width:     int(area.Width * scale),
+		Height:    int(area.Height * scale),
+	}, nil
+}
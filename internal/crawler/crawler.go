@@ -1,16 +1,23 @@
 package crawler
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"net/http"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jmylchreest/refyne/internal/logger"
 	"github.com/jmylchreest/refyne/pkg/cleaner"
 	"github.com/jmylchreest/refyne/pkg/extractor"
 	"github.com/jmylchreest/refyne/pkg/fetcher"
+	"github.com/jmylchreest/refyne/pkg/llm"
 	"github.com/jmylchreest/refyne/pkg/schema"
 )
 
@@ -18,6 +25,18 @@ import (
 // This typically indicates the page requires JavaScript rendering (dynamic fetch mode).
 var ErrInsufficientContent = errors.New("insufficient content for extraction")
 
+// ErrDuplicateRedirect is reported for a page that redirected to a URL that
+// was already crawled or queued.
+var ErrDuplicateRedirect = errors.New("redirected to an already seen URL")
+
+// ErrDuplicateCanonical is reported for a page whose rel=canonical URL was
+// already crawled, queued or claimed by another page.
+var ErrDuplicateCanonical = errors.New("canonical URL already seen")
+
+// ErrNearDuplicate is reported for a page whose content nearly duplicates a
+// page already extracted in the crawl (see Config.DuplicateSimilarity).
+var ErrNearDuplicate = errors.New("near-duplicate of an extracted page")
+
 // InsufficientContentError provides details about why content was insufficient.
 type InsufficientContentError struct {
 	ContentSize int // Actual content size in bytes
@@ -35,19 +54,30 @@ func (e *InsufficientContentError) Unwrap() error {
 // Result represents a single crawl/extraction result.
 type Result struct {
 	URL             string
+	FinalURL        string // URL after redirects (empty if the page wasn't redirected)
+	CanonicalURL    string // URL the page declared canonical with rel=canonical (empty if none, or the page's own URL)
+	DuplicateOf     string // Already extracted page this one nearly duplicates (set on ErrNearDuplicate results)
 	Data            any
 	Raw             string
 	Errors          []schema.ValidationError
 	Usage           *extractor.Result // Extraction result with token usage, model, etc.
 	Error           error
+	Skipped         bool // URL was deliberately not processed; Error explains why
+	Unchanged       bool // Content is unchanged since the last crawl; Data is the data extracted then (see Config.IncrementalDir)
 	Depth           int
 	FetchedAt       time.Time
 	FetchDuration   time.Duration
+	FetchAttempts   []fetcher.Attempt // Per-attempt fetch timings (more than one if the fetch was retried)
+	Fetcher         string            // Type of fetcher that produced the page (e.g., static, dynamic)
 	ExtractDuration time.Duration
 }
 
 // Config holds crawler configuration.
 type Config struct {
+	// Seeding
+	SeedSources  []SeedSource      // Sitemaps and feeds whose URLs are added to the seeds
+	SeedRequests []fetcher.Request // Requests (form posts, JSON search queries, ...) added to the seeds
+
 	// Link following
 	FollowSelector string // CSS selector for links to follow
 	FollowPattern  string // Regex pattern for URLs to follow
@@ -62,8 +92,27 @@ type Config struct {
 	MaxURLs int // Max total URLs to process (0 = unlimited)
 
 	// Rate limiting
-	Delay       time.Duration // Delay between requests
-	Concurrency int           // Max concurrent requests
+	Delay                 time.Duration // Minimum interval between requests to the same host (used when HostRequestsPerSecond is 0)
+	Concurrency           int           // Max concurrent requests across all hosts
+	HostRequestsPerSecond float64       // Sustained requests per second per host (0 = derive from Delay)
+	HostBurst             int           // Requests per host that may be made back-to-back (default: 1)
+	HostMaxInFlight       int           // Max concurrent requests per host (0 = limited only by Concurrency)
+
+	// Politeness
+	RespectRobots   bool            // Honour robots.txt Allow/Disallow and Crawl-delay
+	UserAgent       string          // User agent whose product token robots.txt groups are matched against (see RobotsAgent)
+	DocumentFetcher fetcher.Fetcher // Fetches robots.txt, sitemaps and feeds as served (default: the crawler's fetcher; set it when that renders pages in a browser)
+
+	// Authentication
+	Auth *fetcher.AuthConfig // Login step run once per host before it is crawled (nil = none)
+
+	// Page interaction
+	Actions      fetcher.ActionConfig   // Browser actions run on each page after it loads (dynamic fetchers)
+	Capture      *fetcher.CaptureConfig // Record matching XHR/fetch responses on each page (dynamic fetchers; nil = off)
+	ResourceMode fetcher.ResourceMode   // How captured responses are passed to the extractor (default: page only)
+
+	// Vision
+	Screenshot *fetcher.ScreenshotConfig // Screenshot settings for schemas that use vision (nil = defaults)
 
 	// Extraction
 	ExtractFromSeeds bool // Whether to extract from seed pages (vs just follow links)
@@ -71,6 +120,24 @@ type Config struct {
 	// Content validation
 	MinContentSize int // Minimum cleaned content size in bytes (default: 200). Returns error if content is smaller.
 
+	// Crawl order
+	Strategy Strategy       // Order URLs are crawled in (default: StrategyBFS)
+	Priority PriorityConfig // URL scoring for StrategyPriority
+
+	// Deduplication
+	Normalize           NormalizeConfig // Rules URLs are normalized with before deduplication
+	IgnoreCanonical     bool            // Don't deduplicate extracted pages on their rel=canonical URL
+	DuplicateSimilarity float64         // Skip extracting pages at least this similar (0-1) to one already extracted (0 = off)
+
+	// Incremental recrawl
+	IncrementalDir string // Directory pages' content hashes and data are kept in between crawls, so unchanged pages aren't extracted again (empty = off)
+	OmitUnchanged  bool   // Report unchanged pages as skipped (ErrUnchanged) instead of with their previous data
+
+	// Checkpointing
+	StateDir           string        // Directory the crawl's progress is saved to so it can be resumed (empty = not saved)
+	Resume             bool          // Continue from the state saved in StateDir instead of starting from the seeds
+	CheckpointInterval time.Duration // How often the state is saved while crawling (default: DefaultCheckpointInterval)
+
 	// Callbacks
 	OnURLsQueued func(count int) // Called when URLs are queued (for progress tracking)
 }
@@ -86,12 +153,32 @@ func DefaultConfig() Config {
 		Concurrency:      3,
 		ExtractFromSeeds: false,
 		MinContentSize:   200, // Minimum 200 bytes of cleaned content
+		Strategy:         StrategyBFS,
+		Priority:         DefaultPriorityConfig(),
+		Normalize:        DefaultNormalizeConfig(),
 	}
 }
 
+// hostLimits returns the per-host scheduler limits for this config.
+func (c Config) hostLimits() HostLimits {
+	rps := c.HostRequestsPerSecond
+	if rps <= 0 && c.Delay > 0 {
+		rps = float64(time.Second) / float64(c.Delay)
+	}
+	return HostLimits{
+		RequestsPerSecond: rps,
+		Burst:             c.HostBurst,
+		MaxInFlight:       c.HostMaxInFlight,
+	}
+}
+
+// minDispatchWait bounds how often the dispatcher re-checks rate-limited hosts.
+const minDispatchWait = 5 * time.Millisecond
+
 // Crawler orchestrates multi-page crawling and extraction.
 type Crawler struct {
 	fetcher   fetcher.Fetcher
+	auth      *fetcher.AuthFetcher // Set when Config.Auth is; also wraps fetcher
 	cleaner   cleaner.Cleaner
 	extractor extractor.Extractor
 	config    Config
@@ -102,12 +189,17 @@ func New(f fetcher.Fetcher, cl cleaner.Cleaner, ext extractor.Extractor, cfg Con
 	if cfg.Concurrency < 1 {
 		cfg.Concurrency = 1
 	}
-	return &Crawler{
+	c := &Crawler{
 		fetcher:   f,
 		cleaner:   cl,
 		extractor: ext,
 		config:    cfg,
 	}
+	if cfg.Auth != nil {
+		c.auth = fetcher.NewAuthenticating(f, *cfg.Auth)
+		c.fetcher = c.auth
+	}
+	return c
 }
 
 // Crawl starts crawling from seed URLs and returns results via channel.
@@ -128,9 +220,18 @@ func (c *Crawler) crawl(ctx context.Context, seeds []string, s schema.Schema, re
 		"max_depth", c.config.MaxDepth,
 		"max_urls", c.config.MaxURLs,
 		"concurrency", c.config.Concurrency,
-		"delay", c.config.Delay)
+		"delay", c.config.Delay,
+		"strategy", c.config.Strategy)
 
-	queue := NewURLQueue()
+	frontier, err := NewFrontier(c.config.Strategy, c.config.Priority)
+	if err != nil {
+		results <- Result{Error: err}
+		return
+	}
+	queue := NewURLQueueWithFrontier(frontier)
+	queue.SetNormalizer(NewURLNormalizer(c.config.Normalize))
+	sched := NewHostScheduler(c.config.hostLimits())
+	var robots *RobotsPolicy
 	var linkSelector *LinkSelector
 	var paginationSelector *PaginationSelector
 
@@ -154,25 +255,96 @@ func (c *Crawler) crawl(ctx context.Context, seeds []string, s schema.Schema, re
 		paginationSelector = NewPaginationSelector(c.config.NextSelector)
 	}
 
-	// Add seed URLs to queue at depth 0
-	for _, seed := range seeds {
-		logger.Debug("crawler adding seed URL", "url", seed)
-		queue.Add(seed, 0)
+	// Setup near-duplicate detection if configured
+	var dupes *FingerprintIndex
+	if c.config.DuplicateSimilarity > 0 {
+		logger.Debug("crawler detecting near-duplicate pages", "similarity", c.config.DuplicateSimilarity)
+		dupes = NewFingerprintIndex(c.config.DuplicateSimilarity)
 	}
 
-	// Notify about initial queued URLs
-	if c.config.OnURLsQueued != nil {
-		c.config.OnURLsQueued(queue.TotalQueued())
+	// Load the last crawl's page records if this crawl is incremental
+	var pages *PageStore
+	if c.config.IncrementalDir != "" {
+		if pages, err = OpenPageStore(c.config.IncrementalDir); err != nil {
+			results <- Result{Error: fmt.Errorf("failed to open page records: %w", err)}
+			return
+		}
+		logger.Debug("crawler incremental", "path", pages.Path(), "pages", pages.Len())
+		defer savePages(pages)
+	}
+
+	// Setup robots.txt policy if configured
+	if c.config.RespectRobots {
+		logger.Debug("crawler respecting robots.txt", "user_agent", c.config.UserAgent)
+		robots = NewRobotsPolicy(c.config.UserAgent, c.documentFetcher())
 	}
 
-	// Track processed URLs
+	// Continue a saved crawl, or start one from the seeds
 	urlsProcessed := 0
 	paginationPages := 0
+	var checkpoints *checkpointer
+	var saved *State
+	if c.config.StateDir != "" {
+		store, err := NewStateStore(c.config.StateDir)
+		if err != nil {
+			results <- Result{Error: err}
+			return
+		}
+		if c.config.Resume {
+			var found bool
+			if saved, found, err = store.Load(); err != nil {
+				results <- Result{Error: fmt.Errorf("failed to load crawl state: %w", err)}
+				return
+			} else if !found {
+				logger.Info("no saved crawl state, starting from the seeds", "path", store.Path())
+			}
+		}
+		checkpoints = newCheckpointer(store, c.config.CheckpointInterval, saved)
+
+		// Every exit waits for the workers first, so this saves a settled state
+		defer func() {
+			checkpoints.save(queue, urlsProcessed, paginationPages)
+		}()
+	}
+	if saved != nil {
+		restoreQueue(queue, saved)
+		urlsProcessed, paginationPages = saved.URLsProcessed, saved.PaginationPages
+		if robots != nil {
+			c.restoreCrawlDelays(ctx, saved.Pending, robots, sched)
+		}
+		logger.Info("resuming crawl",
+			"pending", len(saved.Pending),
+			"seen", len(saved.Visited),
+			"processed", saved.URLsProcessed)
+	} else {
+		c.addSeeds(ctx, seeds, queue, robots, sched, results)
+	}
+
+	// Notify about initial queued URLs
+	if c.config.OnURLsQueued != nil {
+		c.config.OnURLsQueued(queue.TotalQueued())
+	}
 
-	// Semaphore for concurrency control
-	sem := make(chan struct{}, c.config.Concurrency)
+	// Workers signal wake when they finish so the dispatcher can re-check limits
+	var inFlight atomic.Int32
+	wake := make(chan struct{}, 1)
 	var wg sync.WaitGroup
 
+	// wait blocks until a worker finishes, a host slot frees up, or d elapses.
+	// It returns false if the context is cancelled.
+	wait := func(d time.Duration) bool {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return false
+		case <-wake:
+		case <-sched.Ready():
+		case <-timer.C:
+		}
+		return true
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -181,6 +353,13 @@ func (c *Crawler) crawl(ctx context.Context, seeds []string, s schema.Schema, re
 		default:
 		}
 
+		if checkpoints != nil && checkpoints.due() {
+			checkpoints.save(queue, urlsProcessed, paginationPages)
+		}
+		if pages != nil && pages.due(cmp.Or(c.config.CheckpointInterval, DefaultCheckpointInterval)) {
+			savePages(pages)
+		}
+
 		// Check max URLs limit
 		if c.config.MaxURLs > 0 && urlsProcessed >= c.config.MaxURLs {
 			logger.Debug("crawler reached max URLs limit", "max_urls", c.config.MaxURLs)
@@ -188,95 +367,312 @@ func (c *Crawler) crawl(ctx context.Context, seeds []string, s schema.Schema, re
 			return
 		}
 
-		// Get next URL
-		currentURL, depth, ok := queue.Pop()
+		// Respect global concurrency
+		if int(inFlight.Load()) >= c.config.Concurrency {
+			if !wait(time.Second) {
+				wg.Wait()
+				return
+			}
+			continue
+		}
+
+		// Dispatch the next URL, in frontier order, whose host is ready. Once a
+		// host refuses a slot, its other URLs are passed over for the rest of
+		// this scan.
+		maxPagesReached := c.config.MaxPages > 0 && paginationPages >= c.config.MaxPages
+		blocked := make(map[string]bool)
+		item, ok := queue.PopItemFunc(func(item FrontierItem) bool {
+			if item.Depth == 0 && maxPagesReached {
+				return true // dropped below without taking a host slot
+			}
+			if blocked[item.Host] {
+				return false
+			}
+			if sched.TryAcquire(item.URL) {
+				return true
+			}
+			blocked[item.Host] = true
+			return false
+		})
 		if !ok {
-			// Queue empty, wait for in-flight requests
-			wg.Wait()
-			// Check if queue is still empty
-			if queue.Len() == 0 {
+			// Workers enqueue links before decrementing inFlight, so an idle
+			// crawler with an empty queue is finished
+			if inFlight.Load() == 0 && queue.Len() == 0 {
+				wg.Wait()
+				return
+			}
+			if !wait(max(sched.NextReady(time.Second), minDispatchWait)) {
+				wg.Wait()
 				return
 			}
 			continue
 		}
 
 		// Check max pages for pagination (depth 0 pages only)
-		if depth == 0 && c.config.MaxPages > 0 && paginationPages >= c.config.MaxPages {
+		if item.Depth == 0 && maxPagesReached {
 			logger.Debug("crawler reached max pagination pages", "max_pages", c.config.MaxPages)
 			continue
 		}
 
-		// Acquire semaphore
-		sem <- struct{}{}
+		inFlight.Add(1)
 		wg.Add(1)
+		if checkpoints != nil {
+			checkpoints.start(item)
+		}
 
-		go func(url string, d int) {
+		go func(item FrontierItem) {
 			defer wg.Done()
-			defer func() { <-sem }()
+			defer func() {
+				inFlight.Add(-1)
+				select {
+				case wake <- struct{}{}:
+				default:
+				}
+			}()
 
-			// Rate limiting
-			if c.config.Delay > 0 {
-				time.Sleep(c.config.Delay)
+			process := func(results chan<- Result, linksOnly bool) bool {
+				return c.processURL(ctx, item.URL, item.Depth, item.Request, linksOnly, s, queue, robots, sched, linkSelector, paginationSelector, dupes, pages, results)
 			}
-
-			c.processURL(ctx, url, d, s, queue, linkSelector, paginationSelector, results)
-		}(currentURL, depth)
+			if checkpoints != nil {
+				checkpoints.track(ctx, item, results, process)
+			} else {
+				_ = process(results, false)
+			}
+		}(item)
 
 		urlsProcessed++
-		if depth == 0 {
+		if item.Depth == 0 {
 			paginationPages++
 		}
 	}
 }
 
+// addSeeds queues the seed URLs, the URLs of any sitemaps and feeds, and the
+// seed requests at depth 0, logging in to each seed host first if configured.
+func (c *Crawler) addSeeds(ctx context.Context, seeds []string, queue *URLQueue, robots *RobotsPolicy, sched *HostScheduler, results chan<- Result) {
+	// Expand sitemaps and feeds into additional seeds
+	if len(c.config.SeedSources) > 0 {
+		expander := NewSeedExpander(c.config.UserAgent, c.documentFetcher())
+		seeds = slices.Clip(seeds)
+		for _, src := range c.config.SeedSources {
+			urls, err := expander.Expand(ctx, src)
+			if err != nil {
+				logger.Warn("failed to expand seed source", "url", src.URL, "error", err)
+				results <- Result{URL: src.URL, Error: fmt.Errorf("seed source: %w", err)}
+				continue
+			}
+			logger.Info("expanded seed source", "url", src.URL, "urls", len(urls))
+			seeds = append(seeds, urls...)
+		}
+	}
+
+	// Seed requests are queued like URLs, keyed on the whole request
+	seedRequests := make([]fetcher.Request, 0, len(seeds)+len(c.config.SeedRequests))
+	for _, seed := range seeds {
+		seedRequests = append(seedRequests, fetcher.Request{URL: seed})
+	}
+	seedRequests = append(seedRequests, c.config.SeedRequests...)
+
+	// Log in to each seed host up front. Failures are sticky, so the seeds
+	// for a host that can't log in are reported as fetch errors.
+	if c.auth != nil {
+		for _, seed := range seedRequests {
+			if err := c.auth.Login(ctx, seed.URL, fetcher.Options{UserAgent: c.config.UserAgent}); err != nil {
+				logger.Warn("crawler login failed", "url", seed.URL, "error", err)
+			}
+		}
+	}
+
+	// Add seed URLs to queue at depth 0
+	for _, seed := range seedRequests {
+		logger.Debug("crawler adding seed URL", "url", seed.URL, "method", seed.Method)
+		c.enqueue(ctx, queue, robots, sched, FrontierItem{URL: seed.URL, Request: &seed}, results)
+	}
+}
+
+// processURL fetches a page, extracts from it if it should, and queues its
+// links and next page. It returns false if the crawl was cancelled before all
+// of them could be queued.
 func (c *Crawler) processURL(
 	ctx context.Context,
 	url string,
 	depth int,
+	req *fetcher.Request,
+	linksOnly bool,
 	s schema.Schema,
 	queue *URLQueue,
+	robots *RobotsPolicy,
+	sched *HostScheduler,
 	linkSelector *LinkSelector,
 	paginationSelector *PaginationSelector,
+	dupes *FingerprintIndex,
+	pages *PageStore,
 	results chan<- Result,
-) {
+) (linksQueued bool) {
 	logger.Debug("crawler processing URL", "url", url, "depth", depth)
 
-	// Fetch the page
+	// Check if we should extract from this page. A page whose result was
+	// reported before the crawl was resumed is only fetched for its links.
+	shouldExtract := false
+	if linksOnly {
+		logger.Debug("crawler refetching page for its links", "url", url)
+	} else if depth == 0 && c.config.ExtractFromSeeds {
+		// Seed page with extraction enabled
+		shouldExtract = true
+	} else if depth > 0 {
+		// Detail page (followed from seed)
+		shouldExtract = true
+	} else if linkSelector == nil {
+		// No link following configured, extract from seed
+		shouldExtract = true
+	}
+
+	// Fetch the page, with screenshots only where they will be extracted from
+	fetchOpts := fetcher.Options{
+		Actions: c.config.Actions.For(url),
+		Capture: c.config.Capture,
+	}
+	if shouldExtract {
+		fetchOpts.Screenshot = ScreenshotOptions(c.config.Screenshot, s)
+	}
+	if req != nil {
+		fetchOpts = req.Apply(fetchOpts)
+	}
+
+	// A page extracted by the last crawl is only fetched in full if it changed.
+	// Pages whose links or next page are needed always are.
+	pageKey := FrontierItem{URL: url, Request: req}.key()
+	var previous *PageRecord
+	if pages != nil && shouldExtract {
+		if rec, ok := pages.Get(pageKey); ok {
+			previous = &rec
+			followed := (linkSelector != nil && depth < c.config.MaxDepth) || (paginationSelector != nil && depth == 0)
+			if !followed && req == nil && c.fetcher.Type() == "static" {
+				fetchOpts.Headers = conditionalHeaders(fetchOpts.Headers, rec)
+			}
+		}
+	}
+
 	fetchStart := time.Now()
-	content, err := c.fetcher.Fetch(ctx, url, fetcher.Options{})
+	content, err := c.fetcher.Fetch(ctx, url, fetchOpts)
 	fetchDuration := time.Since(fetchStart)
+	fetchedBy := content.FetchedBy
+	if fetchedBy == "" {
+		fetchedBy = c.fetcher.Type()
+	}
 
+	// Release the host slot before extraction so slow LLM calls don't hold it.
+	// Latency comes from the last attempt so retry backoff doesn't look like a slow host.
+	latency := fetchDuration
+	if n := len(content.Attempts); n > 0 {
+		latency = content.Attempts[n-1].Duration
+	}
+	sched.Done(url, Outcome{
+		StatusCode: content.StatusCode,
+		Latency:    latency,
+		RetryAfter: fetcher.ParseRetryAfter(content.Headers.Get("Retry-After"), time.Now()),
+	})
+
+	if errors.Is(err, fetcher.ErrTooLarge) || errors.Is(err, fetcher.ErrUnsupportedContentType) {
+		// Not a page we can extract from (a video, an archive, ...); not a failure
+		logger.Info("skipping response", "url", url, "reason", err)
+		results <- Result{URL: url, Depth: depth, Skipped: true, Error: err, FetchDuration: fetchDuration, FetchAttempts: content.Attempts, Fetcher: fetchedBy}
+		return true
+	}
 	if err != nil {
 		logger.Info("fetch failed", "url", url, "error", err, "duration", fetchDuration)
-		results <- Result{URL: url, Depth: depth, Error: fmt.Errorf("fetch error: %w", err), FetchDuration: fetchDuration}
-		return
+		results <- Result{URL: url, Depth: depth, Error: fmt.Errorf("fetch error: %w", err), FetchDuration: fetchDuration, FetchAttempts: content.Attempts, Fetcher: fetchedBy}
+		return true
 	}
 	logger.Debug("crawler fetch complete",
 		"url", url,
 		"text_size", len(content.Text),
 		"links_count", len(content.Links))
 
-	// Check if we should extract from this page
-	shouldExtract := false
-	if depth == 0 && c.config.ExtractFromSeeds {
-		// Seed page with extraction enabled
-		shouldExtract = true
-	} else if depth > 0 {
-		// Detail page (followed from seed)
-		shouldExtract = true
-	} else if linkSelector == nil {
-		// No link following configured, extract from seed
-		shouldExtract = true
+	if content.StatusCode == http.StatusNotModified && previous != nil {
+		logger.Info("page not modified since the last crawl", "url", url)
+		rec := *previous
+		rec.CheckedAt = time.Now()
+		pages.Put(pageKey, rec)
+		results <- c.unchangedResult(Result{
+			URL:           url,
+			Depth:         depth,
+			FetchedAt:     content.FetchedAt,
+			FetchDuration: fetchDuration,
+			FetchAttempts: content.Attempts,
+			Fetcher:       fetchedBy,
+		}, rec)
+		return true
+	}
+
+	// Links are resolved against the URL the page was served from, and a page
+	// that redirects to one already seen isn't processed twice
+	pageURL := url
+	finalURL := ""
+	if content.FinalURL != "" && queue.Normalize(content.FinalURL) != queue.Normalize(url) {
+		pageURL, finalURL = content.FinalURL, content.FinalURL
+		logger.Debug("crawler followed redirect", "url", url, "final_url", finalURL, "hops", len(content.RedirectChain))
+		if !queue.MarkVisited(finalURL) && !linksOnly {
+			logger.Info("skipping redirect to already-seen URL", "url", url, "final_url", finalURL)
+			results <- Result{
+				URL:           url,
+				FinalURL:      finalURL,
+				Depth:         depth,
+				Skipped:       true,
+				Error:         fmt.Errorf("%w: %s", ErrDuplicateRedirect, finalURL),
+				FetchedAt:     content.FetchedAt,
+				FetchDuration: fetchDuration,
+				FetchAttempts: content.Attempts,
+				Fetcher:       fetchedBy,
+			}
+			return true
+		}
+	}
+
+	// A page extracted from is deduplicated on its canonical URL: the first
+	// page found for it is extracted, and its other aliases are skipped. A
+	// skipped alias's links and next page are still followed.
+	canonicalURL := ""
+	reported := false // A result was reported for the page without extracting it
+	if shouldExtract && !c.config.IgnoreCanonical && content.Document == nil {
+		if canonical, ok := FindCanonical(content.HTML, pageURL); ok && queue.Normalize(canonical) != queue.Normalize(pageURL) {
+			canonicalURL = queue.Normalize(canonical)
+			if !queue.MarkVisited(canonical) {
+				logger.Info("skipping alias of already-seen canonical URL", "url", url, "canonical_url", canonicalURL)
+				results <- Result{
+					URL:           url,
+					FinalURL:      finalURL,
+					CanonicalURL:  canonicalURL,
+					Depth:         depth,
+					Skipped:       true,
+					Error:         fmt.Errorf("%w: %s", ErrDuplicateCanonical, canonicalURL),
+					FetchedAt:     content.FetchedAt,
+					FetchDuration: fetchDuration,
+					FetchAttempts: content.Attempts,
+					Fetcher:       fetchedBy,
+				}
+				reported = true
+			} else {
+				logger.Debug("crawler page stands in for its canonical URL", "url", url, "canonical_url", canonicalURL)
+			}
+		}
 	}
 
 	// Extract data if appropriate
 	var extractDuration time.Duration
-	if shouldExtract {
-		// Clean the HTML content before extraction
+	var cleanedContent, contentHash string
+	var images []llm.Image
+	if shouldExtract && !reported {
+		// Clean the HTML content before extraction. Documents (PDF, JSON, ...)
+		// were already converted to markdown by the fetcher.
 		cleanStart := time.Now()
-		cleanedContent, err := c.cleaner.Clean(content.HTML)
-		cleanDuration := time.Since(cleanStart)
-		if err != nil {
+		if content.Document != nil {
+			cleanedContent = content.Text
+			logger.Debug("using converted document",
+				"url", url,
+				"format", content.Document.Format,
+				"pages", content.Document.Pages)
+		} else if cleanedContent, err = c.cleaner.Clean(content.HTML); err != nil {
 			// Fall back to fetcher's text extraction if cleaner fails
 			logger.Debug("cleaner failed, using raw text",
 				"url", url,
@@ -289,14 +685,52 @@ func (c *Crawler) processURL(
 				"cleaner", c.cleaner.Name(),
 				"input_size", len(content.HTML),
 				"output_size", len(cleanedContent),
-				"duration", cleanDuration)
+				"duration", time.Since(cleanStart))
+		}
+
+		// Add (or substitute) the JSON the page loaded through XHR/fetch
+		if len(content.Resources) > 0 {
+			cleanedContent = fetcher.ExtractionInput(cleanedContent, content.Resources, c.config.ResourceMode)
+			logger.Debug("captured resources", "url", url, "count", len(content.Resources), "mode", c.config.ResourceMode)
+		}
+
+		// Reuse the last crawl's data if the content hasn't changed since
+		if pages != nil {
+			contentHash = ContentHash(s, cleanedContent)
+			if previous != nil && previous.ContentHash == contentHash {
+				logger.Info("page unchanged since the last crawl", "url", url)
+				rec := *previous
+				rec.ETag, rec.LastModified = content.Headers.Get("ETag"), content.Headers.Get("Last-Modified")
+				rec.CheckedAt = time.Now()
+				pages.Put(pageKey, rec)
+				results <- c.unchangedResult(Result{
+					URL:           url,
+					FinalURL:      finalURL,
+					CanonicalURL:  canonicalURL,
+					Depth:         depth,
+					FetchedAt:     content.FetchedAt,
+					FetchDuration: fetchDuration,
+					FetchAttempts: content.Attempts,
+					Fetcher:       fetchedBy,
+				}, rec)
+				reported = true
+			}
+		}
+	}
+
+	if shouldExtract && !reported {
+		// Send screenshots with (or instead of) the content if the schema uses vision
+		cleanedContent, images = VisionInput(content, cleanedContent, s.Vision)
+		if s.Vision != nil {
+			logger.Debug("vision extraction", "url", url, "images", len(images), "with_content", cleanedContent != "")
 		}
 
 		// Validate minimum content size before extraction
 		// This prevents LLM hallucination on pages with insufficient content
-		// (e.g., JavaScript-heavy sites that need browser rendering)
+		// (e.g., JavaScript-heavy sites that need browser rendering).
+		// Images carry the page's content when present, so aren't held to it.
 		minSize := c.config.MinContentSize
-		if minSize > 0 && len(cleanedContent) < minSize {
+		if minSize > 0 && len(images) == 0 && len(cleanedContent) < minSize {
 			logger.Info("insufficient content for extraction",
 				"url", url,
 				"content_size", len(cleanedContent),
@@ -304,16 +738,45 @@ func (c *Crawler) processURL(
 				"hint", "page may require JavaScript rendering (dynamic fetch mode)")
 			results <- Result{
 				URL:           url,
+				FinalURL:      finalURL,
+				CanonicalURL:  canonicalURL,
 				Depth:         depth,
 				Error:         &InsufficientContentError{ContentSize: len(cleanedContent), MinRequired: minSize},
 				FetchedAt:     content.FetchedAt,
 				FetchDuration: fetchDuration,
+				FetchAttempts: content.Attempts,
+				Fetcher:       fetchedBy,
+			}
+			return true
+		}
+
+		// Skip extracting pages that nearly duplicate one already extracted
+		// (print and AMP variants, listings served under several URLs) instead
+		// of paying to extract them again. Their links are still followed.
+		if dupes != nil {
+			if original, dup := dupes.Match(cleanedContent); dup {
+				logger.Info("skipping near-duplicate page", "url", url, "duplicate_of", original)
+				results <- Result{
+					URL:           url,
+					FinalURL:      finalURL,
+					CanonicalURL:  canonicalURL,
+					DuplicateOf:   original,
+					Depth:         depth,
+					Skipped:       true,
+					Error:         fmt.Errorf("%w: %s", ErrNearDuplicate, original),
+					FetchedAt:     content.FetchedAt,
+					FetchDuration: fetchDuration,
+					FetchAttempts: content.Attempts,
+					Fetcher:       fetchedBy,
+				}
+				reported = true
 			}
-			return
 		}
+	}
 
+	if shouldExtract && !reported {
 		extractStart := time.Now()
-		extractResult, err := c.extractor.Extract(ctx, cleanedContent, s)
+		extractResult, err := extractor.ExtractWithImages(ctx, c.extractor, cleanedContent, images, s)
 		extractDuration = time.Since(extractStart)
 
 		if err != nil {
@@ -324,10 +787,14 @@ func (c *Crawler) processURL(
 				"error", err)
 			results <- Result{
 				URL:             url,
+				FinalURL:        finalURL,
+				CanonicalURL:    canonicalURL,
 				Depth:           depth,
 				Error:           fmt.Errorf("extraction error: %w", err),
 				FetchedAt:       content.FetchedAt,
 				FetchDuration:   fetchDuration,
+				FetchAttempts:   content.Attempts,
+				Fetcher:         fetchedBy,
 				ExtractDuration: extractDuration,
 			}
 		} else {
@@ -338,8 +805,26 @@ func (c *Crawler) processURL(
 				"input_tokens", extractResult.Usage.InputTokens,
 				"output_tokens", extractResult.Usage.OutputTokens,
 				"validation_errors", len(extractResult.Errors))
+			if dupes != nil {
+				dupes.Add(pageURL, cleanedContent)
+			}
+			if pages != nil && len(extractResult.Errors) == 0 {
+				if data, err := json.Marshal(extractResult.Data); err == nil {
+					now := time.Now()
+					pages.Put(pageKey, PageRecord{
+						ContentHash:  contentHash,
+						ETag:         content.Headers.Get("ETag"),
+						LastModified: content.Headers.Get("Last-Modified"),
+						Data:         data,
+						ExtractedAt:  now,
+						CheckedAt:    now,
+					})
+				}
+			}
 			results <- Result{
 				URL:             url,
+				FinalURL:        finalURL,
+				CanonicalURL:    canonicalURL,
 				Depth:           depth,
 				Data:            extractResult.Data,
 				Raw:             extractResult.Raw,
@@ -347,34 +832,40 @@ func (c *Crawler) processURL(
 				Usage:           extractResult,
 				FetchedAt:       content.FetchedAt,
 				FetchDuration:   fetchDuration,
+				FetchAttempts:   content.Attempts,
+				Fetcher:         fetchedBy,
 				ExtractDuration: extractDuration,
 			}
 		}
-	} else {
+	} else if !shouldExtract {
 		logger.Debug("fetched (no extraction)", "url", url, "fetch", fetchDuration.Round(time.Millisecond), "links", len(content.Links))
 	}
 
 	// Follow links if configured and within depth limit
+	linksQueued = true
 	if linkSelector != nil && depth < c.config.MaxDepth {
-		links, err := linkSelector.ExtractLinks(content.HTML, url)
+		links, err := linkSelector.ExtractAnchors(content.HTML, pageURL)
 		if err == nil {
 			logger.Debug("crawler found links to follow", "url", url, "links_count", len(links))
 			addedCount := 0
 			for _, link := range links {
 				// Check same domain constraint
-				if c.config.SameDomainOnly && !IsSameDomain(url, link) {
-					logger.Debug("crawler skipping cross-domain link", "link", link)
+				if c.config.SameDomainOnly && !IsSameDomain(pageURL, link.URL) {
+					logger.Debug("crawler skipping cross-domain link", "link", link.URL)
 					continue
 				}
-				if queue.Add(link, depth+1) {
-					logger.Debug("crawler queued link", "link", link, "depth", depth+1)
+				added, cutShort := c.enqueue(ctx, queue, robots, sched, FrontierItem{URL: link.URL, Depth: depth + 1, AnchorText: link.Text}, results)
+				if cutShort {
+					linksQueued = false
+				} else if added {
+					logger.Debug("crawler queued link", "link", link.URL, "depth", depth+1)
 					addedCount++
 				} else {
-					logger.Debug("crawler skipping already-seen link", "link", link)
+					logger.Debug("crawler skipping already-seen link", "link", link.URL)
 				}
 			}
 			if addedCount > 0 {
-				logger.Info("following links", "from", url, "count", addedCount)
+				logger.Info("following links", "from", pageURL, "count", addedCount)
 				// Notify about newly queued URLs
 				if c.config.OnURLsQueued != nil {
 					c.config.OnURLsQueued(queue.TotalQueued())
@@ -387,15 +878,116 @@ func (c *Crawler) processURL(
 
 	// Handle pagination (only at depth 0)
 	if paginationSelector != nil && depth == 0 {
-		if nextURL, found := paginationSelector.FindNextPage(content.HTML, url); found {
+		if nextURL, found := paginationSelector.FindNextPage(content.HTML, pageURL); found {
 			logger.Debug("crawler found next page", "next_url", nextURL)
 			logger.Info("pagination", "next", nextURL)
 			// Pagination stays at depth 0
-			if queue.Add(nextURL, 0) && c.config.OnURLsQueued != nil {
+			added, cutShort := c.enqueue(ctx, queue, robots, sched, FrontierItem{URL: nextURL}, results)
+			if cutShort {
+				linksQueued = false
+			} else if added && c.config.OnURLsQueued != nil {
 				c.config.OnURLsQueued(queue.TotalQueued())
 			}
 		}
 	}
 
 	logger.Debug("crawler finished processing URL", "url", url)
+	return linksQueued
+}
+
+// unchangedResult completes the result reported for a page whose content is
+// unchanged since the last crawl, from its record.
+func (c *Crawler) unchangedResult(r Result, rec PageRecord) Result {
+	r.Unchanged = true
+	if c.config.OmitUnchanged {
+		r.Skipped = true
+		r.Error = ErrUnchanged
+	} else {
+		r.Data = rec.decodeData()
+	}
+	return r
+}
+
+// conditionalHeaders returns headers plus the conditional request headers
+// for a page's record, leaving headers unmodified.
+func conditionalHeaders(headers map[string]string, rec PageRecord) map[string]string {
+	if rec.ETag == "" && rec.LastModified == "" {
+		return headers
+	}
+	headers = maps.Clone(headers)
+	if headers == nil {
+		headers = make(map[string]string, 2)
+	}
+	if rec.ETag != "" {
+		headers["If-None-Match"] = rec.ETag
+	}
+	if rec.LastModified != "" {
+		headers["If-Modified-Since"] = rec.LastModified
+	}
+	return headers
+}
+
+// savePages saves an incremental crawl's page records, logging failures.
+func savePages(pages *PageStore) {
+	if err := pages.Save(); err != nil {
+		logger.Warn("failed to save page records", "path", pages.Path(), "error", err)
+		return
+	}
+	logger.Debug("page records saved", "path", pages.Path(), "pages", pages.Len())
+}
+
+// documentFetcher returns the fetcher robots.txt, sitemaps and feeds are
+// fetched with.
+func (c *Crawler) documentFetcher() fetcher.Fetcher {
+	if c.config.DocumentFetcher != nil {
+		return c.config.DocumentFetcher
+	}
+	return c.fetcher
+}
+
+// restoreCrawlDelays applies the robots.txt Crawl-delay of each host with
+// pending URLs, as enqueue did when they were first queued.
+func (c *Crawler) restoreCrawlDelays(ctx context.Context, pending []PendingURL, robots *RobotsPolicy, sched *HostScheduler) {
+	seen := make(map[string]bool)
+	for _, p := range pending {
+		if host := hostKey(p.URL); !seen[host] {
+			seen[host] = true
+			if delay := robots.CrawlDelay(ctx, p.URL); delay > 0 {
+				sched.SetMinInterval(p.URL, delay)
+			}
+		}
+	}
+}
+
+// enqueue adds an item to the queue unless robots.txt disallows its URL, in which case
+// a skipped result is reported (once per URL) instead of dropping it silently.
+// A robots.txt Crawl-delay becomes the host's minimum request interval.
+//
+// If the crawl is cancelled before robots.txt can be checked, the item is
+// dropped and enqueue reports it was cut short, so the page it was found on
+// can stay in flight and be refetched for its links on resume.
+func (c *Crawler) enqueue(ctx context.Context, queue *URLQueue, robots *RobotsPolicy, sched *HostScheduler, item FrontierItem, results chan<- Result) (added, cutShort bool) {
+	if robots == nil {
+		return queue.AddItem(item), false
+	}
+	link := item.URL
+	if !robots.Allowed(ctx, link) {
+		if ctx.Err() != nil {
+			return false, true
+		}
+		if queue.MarkVisited(link) {
+			logger.Info("skipping URL disallowed by robots.txt", "url", link)
+			results <- Result{
+				URL:     link,
+				Depth:   item.Depth,
+				Skipped: true,
+				Error:   fmt.Errorf("%w: %s", ErrRobotsDisallowed, link),
+			}
+		}
+		return false, false
+	}
+	if delay := robots.CrawlDelay(ctx, link); delay > 0 {
+		sched.SetMinInterval(link, delay)
+	}
+	return queue.AddItem(item), false
 }
@@ -0,0 +1,286 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jmylchreest/refyne/pkg/cleaner"
+	"github.com/jmylchreest/refyne/pkg/extractor"
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+	"github.com/jmylchreest/refyne/pkg/schema"
+)
+
+// stubExtractor "extracts" the content it is given, counting its calls.
+type stubExtractor struct {
+	calls atomic.Int32
+}
+
+func (e *stubExtractor) Extract(_ context.Context, content string, _ schema.Schema) (*extractor.Result, error) {
+	e.calls.Add(1)
+	return &extractor.Result{Data: map[string]any{"content": content}}, nil
+}
+
+func (e *stubExtractor) Name() string    { return "stub" }
+func (e *stubExtractor) Available() bool { return true }
+
+// countingMux serves handlers by path, counting the requests for each.
+type countingMux struct {
+	mu   sync.Mutex
+	hits map[string]int
+	mux  *http.ServeMux
+}
+
+func newCountingMux() *countingMux {
+	return &countingMux{hits: make(map[string]int), mux: http.NewServeMux()}
+}
+
+func (m *countingMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	m.hits[r.URL.Path]++
+	m.mu.Unlock()
+	m.mux.ServeHTTP(w, r)
+}
+
+func (m *countingMux) count(path string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hits[path]
+}
+
+// collect drains a crawl's results, calling each for every one.
+func collect(t *testing.T, results <-chan Result, each func(Result)) []Result {
+	t.Helper()
+	var all []Result
+	for r := range results {
+		if r.Error != nil && r.URL == "" {
+			t.Errorf("crawl failed: %v", r.Error)
+		}
+		all = append(all, r)
+		if each != nil {
+			each(r)
+		}
+	}
+	return all
+}
+
+func testCrawlConfig() Config {
+	cfg := DefaultConfig()
+	cfg.Delay = 0
+	cfg.MinContentSize = 0
+	return cfg
+}
+
+// --- Crawler Tests ---
+
+func TestCrawler_StopAndResume(t *testing.T) {
+	const items = 8
+	mux := newCountingMux()
+	mux.mux.HandleFunc("/list", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "<html><body>")
+		for i := range items {
+			_, _ = fmt.Fprintf(w, `<a class="item" href="/item/%d">item %d</a>`, i, i)
+		}
+		_, _ = fmt.Fprint(w, "</body></html>")
+	})
+	mux.mux.HandleFunc("/item/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "<html><body><h1>%s</h1></body></html>", r.URL.Path)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := testCrawlConfig()
+	cfg.FollowSelector = "a.item"
+	cfg.Concurrency = 1
+	cfg.StateDir = t.TempDir()
+	ext := &stubExtractor{}
+
+	// Stop the crawl once it has extracted a few items
+	ctx, cancel := context.WithCancel(context.Background())
+	first := collect(t, New(fetcher.NewStatic(fetcher.StaticConfig{}), cleaner.NewNoop(), ext, cfg).Crawl(ctx, []string{srv.URL + "/list"}, schema.Schema{}), func(r Result) {
+		if r.Data != nil && ext.calls.Load() == 3 {
+			cancel()
+		}
+	})
+	cancel()
+
+	// The resumed crawl continues where the first stopped
+	cfg.Resume = true
+	second := collect(t, New(fetcher.NewStatic(fetcher.StaticConfig{}), cleaner.NewNoop(), ext, cfg).Crawl(context.Background(), []string{srv.URL + "/list"}, schema.Schema{}), nil)
+
+	extracted := make(map[string]int)
+	for _, r := range append(first, second...) {
+		switch {
+		case r.Data != nil:
+			extracted[r.URL]++
+		case r.Error != nil && !errors.Is(r.Error, context.Canceled):
+			t.Errorf("unexpected error for %s: %v", r.URL, r.Error)
+		}
+	}
+	for i := range items {
+		if url := fmt.Sprintf("%s/item/%d", srv.URL, i); extracted[url] != 1 {
+			t.Errorf("%s extracted %d times across both runs, want 1", url, extracted[url])
+		}
+	}
+	if len(second) == 0 || len(second) >= items {
+		t.Errorf("resumed crawl reported %d results, want the rest of the %d items", len(second), items)
+	}
+	if got := ext.calls.Load(); got != items {
+		t.Errorf("extractor called %d times, want %d (once per item)", got, items)
+	}
+	if got := mux.count("/list"); got != 1 {
+		t.Errorf("listing fetched %d times, want 1 (finished before the stop)", got)
+	}
+}
+
+func TestCrawler_ResumeLinksCutShort(t *testing.T) {
+	// The items' host answers robots.txt only once the first crawl is stopped
+	var stopped atomic.Bool
+	stop, release := make(chan struct{}), make(chan struct{})
+	items := newCountingMux()
+	items.mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		if !stopped.Load() {
+			close(stop)
+			<-release
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprint(w, "User-agent: *\nAllow: /\n")
+	})
+	items.mux.HandleFunc("/item/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "<html><body><h1>%s</h1></body></html>", r.URL.Path)
+	})
+	itemSrv := httptest.NewServer(items)
+	defer itemSrv.Close()
+
+	list := newCountingMux()
+	list.mux.HandleFunc("/list", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<html><body><a class="item" href="%[1]s/item/1">1</a><a class="item" href="%[1]s/item/2">2</a></body></html>`, itemSrv.URL)
+	})
+	listSrv := httptest.NewServer(list)
+	defer listSrv.Close()
+
+	cfg := testCrawlConfig()
+	cfg.FollowSelector = "a.item"
+	cfg.SameDomainOnly = false
+	cfg.ExtractFromSeeds = true
+	cfg.RespectRobots = true
+	cfg.StateDir = t.TempDir()
+	ext := &stubExtractor{}
+
+	// Stop the crawl while the listing's links wait on robots.txt
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stop
+		stopped.Store(true)
+		cancel()
+		close(release)
+	}()
+	first := collect(t, New(fetcher.NewStatic(fetcher.StaticConfig{}), cleaner.NewNoop(), ext, cfg).Crawl(ctx, []string{listSrv.URL + "/list"}, schema.Schema{}), nil)
+	if len(first) != 1 || first[0].Data == nil {
+		t.Fatalf("first crawl reported %+v, want the listing extracted", first)
+	}
+
+	// On resume the listing is fetched again for its links, not re-extracted
+	cfg.Resume = true
+	second := collect(t, New(fetcher.NewStatic(fetcher.StaticConfig{}), cleaner.NewNoop(), ext, cfg).Crawl(context.Background(), []string{listSrv.URL + "/list"}, schema.Schema{}), nil)
+	var urls []string
+	for _, r := range second {
+		if r.Data == nil {
+			t.Errorf("resumed crawl reported %s without data: %v", r.URL, r.Error)
+		}
+		urls = append(urls, r.URL)
+	}
+	if len(urls) != 2 || urls[0] == listSrv.URL+"/list" || urls[1] == listSrv.URL+"/list" {
+		t.Errorf("resumed crawl reported %v, want the two items only", urls)
+	}
+	if got := ext.calls.Load(); got != 3 {
+		t.Errorf("extractor called %d times, want 3 (the listing once, then each item)", got)
+	}
+	if got := list.count("/list"); got != 2 {
+		t.Errorf("listing fetched %d times, want 2 (again for its links)", got)
+	}
+}
+
+func TestCrawler_Incremental(t *testing.T) {
+	var version atomic.Int32
+	mux := newCountingMux()
+	mux.mux.HandleFunc("/etag", func(w http.ResponseWriter, r *http.Request) {
+		// Answers a conditional request with 304 Not Modified
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = fmt.Fprint(w, "<html><body>tagged</body></html>")
+	})
+	mux.mux.HandleFunc("/same", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "<html><body>always the same</body></html>")
+	})
+	mux.mux.HandleFunc("/changing", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "<html><body>version %d</body></html>", version.Load())
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	seeds := []string{srv.URL + "/etag", srv.URL + "/same", srv.URL + "/changing"}
+	cfg := testCrawlConfig()
+	cfg.IncrementalDir = t.TempDir()
+	ext := &stubExtractor{}
+	crawl := func(cfg Config) map[string]Result {
+		c := New(fetcher.NewStatic(fetcher.StaticConfig{}), cleaner.NewNoop(), ext, cfg)
+		byURL := make(map[string]Result)
+		for _, r := range collect(t, c.Crawl(context.Background(), seeds, schema.Schema{}), nil) {
+			if r.Error != nil && !r.Skipped {
+				t.Errorf("unexpected error for %s: %v", r.URL, r.Error)
+			}
+			byURL[r.URL] = r
+		}
+		return byURL
+	}
+
+	first := crawl(cfg)
+	if got := ext.calls.Load(); got != 3 {
+		t.Fatalf("first crawl extracted %d pages, want 3", got)
+	}
+
+	// Unchanged pages are reported with their previous data without being
+	// extracted again: one answers 304, the other has the same content
+	version.Add(1)
+	second := crawl(cfg)
+	if got := ext.calls.Load(); got != 4 {
+		t.Errorf("second crawl extracted %d pages, want only the changed one", got-3)
+	}
+	for _, path := range []string{"/etag", "/same"} {
+		r := second[srv.URL+path]
+		if !r.Unchanged || !reflect.DeepEqual(r.Data, first[srv.URL+path].Data) {
+			t.Errorf("%s: Unchanged = %v, Data = %v; want unchanged with the first crawl's data", path, r.Unchanged, r.Data)
+		}
+	}
+	if r := second[srv.URL+"/changing"]; r.Unchanged || r.Data == nil {
+		t.Errorf("/changing: Unchanged = %v, Data = %v; want it extracted again", r.Unchanged, r.Data)
+	}
+
+	// OmitUnchanged reports them as skipped, without data
+	version.Add(1)
+	cfg.OmitUnchanged = true
+	third := crawl(cfg)
+	for _, path := range []string{"/etag", "/same"} {
+		r := third[srv.URL+path]
+		if !r.Unchanged || !r.Skipped || !errors.Is(r.Error, ErrUnchanged) || r.Data != nil {
+			t.Errorf("%s: %+v; want it skipped with ErrUnchanged and no data", path, r)
+		}
+	}
+	if r := third[srv.URL+"/changing"]; r.Skipped || r.Data == nil {
+		t.Errorf("/changing: %+v; want it extracted again", r)
+	}
+	if got := ext.calls.Load(); got != 5 {
+		t.Errorf("third crawl extracted %d pages, want only the changed one", got-4)
+	}
+}
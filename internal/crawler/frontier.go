@@ -0,0 +1,288 @@
+package crawler
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+)
+
+// Strategy is the order in which a crawl visits the URLs it discovers.
+type Strategy string
+
+const (
+	// StrategyBFS crawls URLs in the order they were found (breadth-first).
+	StrategyBFS Strategy = "bfs"
+	// StrategyDFS crawls the most recently found URL first (depth-first).
+	StrategyDFS Strategy = "dfs"
+	// StrategyPriority crawls the highest-scoring URL first (see PriorityConfig).
+	StrategyPriority Strategy = "priority"
+)
+
+// ParseStrategy converts a string (e.g., from a CLI flag) to a Strategy.
+func ParseStrategy(s string) (Strategy, error) {
+	switch strategy := Strategy(strings.ToLower(strings.TrimSpace(s))); strategy {
+	case StrategyBFS, StrategyDFS, StrategyPriority:
+		return strategy, nil
+	case "":
+		return StrategyBFS, nil
+	default:
+		return "", fmt.Errorf("unknown crawl strategy: %s (use bfs, dfs or priority)", s)
+	}
+}
+
+// FrontierItem is a URL waiting to be crawled.
+type FrontierItem struct {
+	URL        string           // Normalized URL
+	Host       string           // Lowercased host:port of URL, as scheduled (set by URLQueue)
+	Depth      int              // Link depth from the seeds
+	Request    *fetcher.Request // Set unless the URL is fetched with a plain GET
+	AnchorText string           // Text of the link the URL was found through (empty for seeds and pagination)
+	Score      float64          // Priority score (set by PriorityFrontier)
+}
+
+// key returns the item's deduplication key (see requestKey).
+func (item FrontierItem) key() string {
+	if item.Request != nil {
+		return requestKey(item.URL, *item.Request)
+	}
+	return item.URL
+}
+
+// Frontier orders the URLs waiting to be crawled. URLQueue deduplicates URLs
+// before pushing them and serializes access, so implementations need not be
+// safe for concurrent use.
+type Frontier interface {
+	// Push adds an item.
+	Push(item FrontierItem)
+
+	// PopFunc removes and returns the next item, in the frontier's order, for
+	// which accept returns true. Items that are not accepted stay queued.
+	PopFunc(accept func(FrontierItem) bool) (FrontierItem, bool)
+
+	// Len returns the number of items.
+	Len() int
+
+	// Items returns the items in the order they were pushed, so pushing them
+	// into a new frontier recreates this one (see State).
+	Items() []FrontierItem
+}
+
+// NewFrontier creates the frontier for a strategy. The priority config is
+// only used by StrategyPriority.
+func NewFrontier(strategy Strategy, priority PriorityConfig) (Frontier, error) {
+	switch strategy {
+	case StrategyBFS, "":
+		return NewBFSFrontier(), nil
+	case StrategyDFS:
+		return NewDFSFrontier(), nil
+	case StrategyPriority:
+		return NewPriorityFrontier(priority), nil
+	default:
+		return nil, fmt.Errorf("unknown crawl strategy: %s", strategy)
+	}
+}
+
+// listFrontier is a FIFO (breadth-first) or LIFO (depth-first) frontier.
+type listFrontier struct {
+	items []FrontierItem
+	lifo  bool
+}
+
+// NewBFSFrontier creates a frontier that pops URLs in the order they were
+// pushed, so a crawl finishes each depth before starting the next.
+func NewBFSFrontier() Frontier {
+	return &listFrontier{}
+}
+
+// NewDFSFrontier creates a frontier that pops the most recently pushed URL
+// first, so a crawl follows links down before moving on to siblings.
+func NewDFSFrontier() Frontier {
+	return &listFrontier{lifo: true}
+}
+
+func (f *listFrontier) Push(item FrontierItem) {
+	f.items = append(f.items, item)
+}
+
+func (f *listFrontier) PopFunc(accept func(FrontierItem) bool) (FrontierItem, bool) {
+	for n := range f.items {
+		i := n
+		if f.lifo {
+			i = len(f.items) - 1 - n
+		}
+		if item := f.items[i]; accept(item) {
+			f.items = slices.Delete(f.items, i, i+1)
+			return item, true
+		}
+	}
+	return FrontierItem{}, false
+}
+
+func (f *listFrontier) Len() int {
+	return len(f.items)
+}
+
+func (f *listFrontier) Items() []FrontierItem {
+	return slices.Clone(f.items)
+}
+
+// URLScorer scores a URL for the priority strategy; higher scores are
+// crawled first.
+type URLScorer func(item FrontierItem) float64
+
+// PatternWeight adds Weight to the score of URLs matching Pattern.
+type PatternWeight struct {
+	Pattern *regexp.Regexp
+	Weight  float64
+}
+
+// PriorityConfig scores URLs for the priority strategy.
+type PriorityConfig struct {
+	DepthWeight  float64            // Subtracted from the score for each level of depth
+	URLPatterns  []PatternWeight    // Added to the score of URLs matching each pattern
+	Keywords     map[string]float64 // Added to the score of links whose anchor text contains the keyword (case-insensitive)
+	HostFairness float64            // Subtracted for each URL already crawled from the same host, so one large host doesn't starve the others
+	Scorer       URLScorer          // Replaces the built-in score (HostFairness still applies); it can build on BaseScore
+}
+
+// DefaultPriorityConfig returns a config that favours shallow URLs and spreads
+// the crawl across hosts.
+func DefaultPriorityConfig() PriorityConfig {
+	return PriorityConfig{
+		DepthWeight:  1,
+		HostFairness: 0.1,
+	}
+}
+
+// BaseScore is the built-in score of an item: its URL pattern and anchor text
+// keyword weights, less DepthWeight for each level of depth.
+func (c PriorityConfig) BaseScore(item FrontierItem) float64 {
+	score := -c.DepthWeight * float64(item.Depth)
+	for _, pw := range c.URLPatterns {
+		if pw.Pattern != nil && pw.Pattern.MatchString(item.URL) {
+			score += pw.Weight
+		}
+	}
+	if item.AnchorText != "" && len(c.Keywords) > 0 {
+		text := strings.ToLower(item.AnchorText)
+		for keyword, weight := range c.Keywords {
+			if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+				score += weight
+			}
+		}
+	}
+	return score
+}
+
+// score returns the item's score, from Scorer if set.
+func (c PriorityConfig) score(item FrontierItem) float64 {
+	if c.Scorer != nil {
+		return c.Scorer(item)
+	}
+	return c.BaseScore(item)
+}
+
+// PriorityFrontier pops the highest-scoring URL first. Scores are computed
+// when URLs are pushed; the per-host fairness penalty is applied at pop time,
+// as hosts are crawled. Equal scores pop in the order they were pushed.
+type PriorityFrontier struct {
+	config PriorityConfig
+	hosts  map[string]*hostFrontier
+	seq    uint64
+	len    int
+}
+
+// hostFrontier holds one host's queued URLs, best first.
+type hostFrontier struct {
+	items      []scoredItem
+	dispatched int // URLs popped from this host so far
+}
+
+// scoredItem is a frontier item with the order it was pushed in.
+type scoredItem struct {
+	FrontierItem
+	seq uint64
+}
+
+// compareScored orders items best first, then first pushed.
+func compareScored(a, b scoredItem) int {
+	return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.seq, b.seq))
+}
+
+// NewPriorityFrontier creates a priority frontier.
+func NewPriorityFrontier(cfg PriorityConfig) *PriorityFrontier {
+	return &PriorityFrontier{config: cfg, hosts: make(map[string]*hostFrontier)}
+}
+
+// Push scores the item and adds it.
+func (f *PriorityFrontier) Push(item FrontierItem) {
+	item.Score = f.config.score(item)
+	scored := scoredItem{FrontierItem: item, seq: f.seq}
+	f.seq++
+
+	host := cmp.Or(item.Host, hostKey(item.URL))
+	hf := f.hosts[host]
+	if hf == nil {
+		hf = &hostFrontier{}
+		f.hosts[host] = hf
+	}
+	i, _ := slices.BinarySearchFunc(hf.items, scored, compareScored)
+	hf.items = slices.Insert(hf.items, i, scored)
+	f.len++
+}
+
+// PopFunc removes and returns the best item that accept accepts. Hosts are
+// tried in order of their best item's score less the fairness penalty.
+func (f *PriorityFrontier) PopFunc(accept func(FrontierItem) bool) (FrontierItem, bool) {
+	type candidate struct {
+		hf    *hostFrontier
+		score float64
+		seq   uint64
+	}
+	candidates := make([]candidate, 0, len(f.hosts))
+	for _, hf := range f.hosts {
+		if len(hf.items) > 0 {
+			head := hf.items[0]
+			penalty := f.config.HostFairness * float64(hf.dispatched)
+			candidates = append(candidates, candidate{hf: hf, score: head.Score - penalty, seq: head.seq})
+		}
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), cmp.Compare(a.seq, b.seq))
+	})
+
+	for _, c := range candidates {
+		for i, item := range c.hf.items {
+			if accept(item.FrontierItem) {
+				c.hf.items = slices.Delete(c.hf.items, i, i+1)
+				c.hf.dispatched++
+				f.len--
+				return item.FrontierItem, true
+			}
+		}
+	}
+	return FrontierItem{}, false
+}
+
+// Len returns the number of queued URLs.
+func (f *PriorityFrontier) Len() int {
+	return f.len
+}
+
+// Items returns the queued URLs in the order they were pushed.
+func (f *PriorityFrontier) Items() []FrontierItem {
+	all := make([]scoredItem, 0, f.len)
+	for _, hf := range f.hosts {
+		all = append(all, hf.items...)
+	}
+	slices.SortFunc(all, func(a, b scoredItem) int { return cmp.Compare(a.seq, b.seq) })
+	items := make([]FrontierItem, len(all))
+	for i, item := range all {
+		items[i] = item.FrontierItem
+	}
+	return items
+}
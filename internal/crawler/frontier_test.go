@@ -0,0 +1,178 @@
+package crawler
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// popAll pops every URL from f, in order.
+func popAll(f Frontier) []string {
+	var urls []string
+	for {
+		item, ok := f.PopFunc(func(FrontierItem) bool { return true })
+		if !ok {
+			return urls
+		}
+		urls = append(urls, item.URL)
+	}
+}
+
+// pushURLs pushes each URL at the given depth.
+func pushURLs(f Frontier, depth int, urls ...string) {
+	for _, u := range urls {
+		f.Push(FrontierItem{URL: u, Depth: depth})
+	}
+}
+
+// --- Frontier Tests ---
+
+func TestParseStrategy(t *testing.T) {
+	for in, want := range map[string]Strategy{"": StrategyBFS, "BFS": StrategyBFS, "dfs": StrategyDFS, " priority ": StrategyPriority} {
+		if got, err := ParseStrategy(in); err != nil || got != want {
+			t.Errorf("ParseStrategy(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseStrategy("random"); err == nil {
+		t.Error("ParseStrategy() should reject an unknown strategy")
+	}
+}
+
+func TestFrontier_Order(t *testing.T) {
+	urls := []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}
+	reversed := slices.Clone(urls)
+	slices.Reverse(reversed)
+
+	tests := []struct {
+		strategy Strategy
+		want     []string
+	}{
+		{StrategyBFS, urls},
+		{StrategyDFS, reversed},
+		{StrategyPriority, urls}, // Equal scores pop in push order
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			f, err := NewFrontier(tt.strategy, PriorityConfig{})
+			if err != nil {
+				t.Fatalf("NewFrontier() error = %v", err)
+			}
+			pushURLs(f, 1, urls...)
+			if f.Len() != 3 {
+				t.Errorf("Len() = %d, want 3", f.Len())
+			}
+			if got := popAll(f); !slices.Equal(got, tt.want) {
+				t.Errorf("popped %v, want %v", got, tt.want)
+			}
+			if f.Len() != 0 {
+				t.Errorf("Len() after popping all = %d, want 0", f.Len())
+			}
+		})
+	}
+}
+
+func TestFrontier_PopFuncSkips(t *testing.T) {
+	for _, strategy := range []Strategy{StrategyBFS, StrategyDFS, StrategyPriority} {
+		t.Run(string(strategy), func(t *testing.T) {
+			f, _ := NewFrontier(strategy, PriorityConfig{})
+			pushURLs(f, 1, "https://a.example/1", "https://b.example/1", "https://a.example/2")
+
+			item, ok := f.PopFunc(func(item FrontierItem) bool { return strings.HasPrefix(item.URL, "https://b.") })
+			if !ok || item.URL != "https://b.example/1" {
+				t.Fatalf("PopFunc() = %q, %v; want the b.example URL", item.URL, ok)
+			}
+			if _, ok := f.PopFunc(func(FrontierItem) bool { return false }); ok {
+				t.Error("PopFunc() should pop nothing when nothing is accepted")
+			}
+			if f.Len() != 2 {
+				t.Errorf("Len() = %d, want the 2 skipped URLs", f.Len())
+			}
+		})
+	}
+}
+
+func TestPriorityFrontier_Scoring(t *testing.T) {
+	cfg := DefaultPriorityConfig()
+	cfg.HostFairness = 0
+	cfg.URLPatterns = []PatternWeight{{Pattern: regexp.MustCompile(`/listing/`), Weight: 5}}
+	cfg.Keywords = map[string]float64{"Floor Plan": 3}
+	f := NewPriorityFrontier(cfg)
+
+	f.Push(FrontierItem{URL: "https://example.com/about", Depth: 1})
+	f.Push(FrontierItem{URL: "https://example.com/deep", Depth: 3})
+	f.Push(FrontierItem{URL: "https://example.com/plans/1", Depth: 2, AnchorText: "View floor plan"})
+	f.Push(FrontierItem{URL: "https://example.com/listing/1", Depth: 2})
+	f.Push(FrontierItem{URL: "https://example.com/", Depth: 0})
+
+	want := []string{
+		"https://example.com/listing/1", // 5 - 2
+		"https://example.com/plans/1",   // 3 - 2
+		"https://example.com/",          // 0
+		"https://example.com/about",     // -1
+		"https://example.com/deep",      // -3
+	}
+	if got := popAll(f); !slices.Equal(got, want) {
+		t.Errorf("popped %v, want %v", got, want)
+	}
+}
+
+func TestPriorityFrontier_HostFairness(t *testing.T) {
+	cfg := PriorityConfig{HostFairness: 1}
+	f := NewPriorityFrontier(cfg)
+	pushURLs(f, 0, "https://big.example/1", "https://big.example/2", "https://big.example/3", "https://small.example/1")
+
+	// Without fairness big.example would be crawled first in full; with it,
+	// small.example gets a turn once big.example has been crawled once
+	want := []string{"https://big.example/1", "https://small.example/1", "https://big.example/2", "https://big.example/3"}
+	if got := popAll(f); !slices.Equal(got, want) {
+		t.Errorf("popped %v, want %v", got, want)
+	}
+}
+
+func TestPriorityFrontier_CustomScorer(t *testing.T) {
+	cfg := DefaultPriorityConfig()
+	cfg.Scorer = func(item FrontierItem) float64 {
+		score := cfg.BaseScore(item)
+		if strings.HasSuffix(item.URL, ".pdf") {
+			score -= 10
+		}
+		return score
+	}
+	f := NewPriorityFrontier(cfg)
+	pushURLs(f, 1, "https://example.com/report.pdf", "https://example.com/report")
+
+	item, _ := f.PopFunc(func(FrontierItem) bool { return true })
+	if item.URL != "https://example.com/report" || item.Score != -1 {
+		t.Errorf("popped %q with score %v, want the HTML report with score -1", item.URL, item.Score)
+	}
+}
+
+func TestPriorityFrontier_Items(t *testing.T) {
+	cfg := PriorityConfig{URLPatterns: []PatternWeight{{Pattern: regexp.MustCompile(`/best`), Weight: 1}}}
+	f := NewPriorityFrontier(cfg)
+	urls := []string{"https://a.example/x", "https://b.example/best", "https://a.example/best"}
+	pushURLs(f, 0, urls...)
+
+	var got []string
+	for _, item := range f.Items() {
+		got = append(got, item.URL)
+	}
+	if !slices.Equal(got, urls) {
+		t.Errorf("Items() = %v, want push order %v", got, urls)
+	}
+}
+
+func TestURLQueue_WithFrontier(t *testing.T) {
+	q := NewURLQueueWithFrontier(NewDFSFrontier())
+	q.Add("https://example.com/a", 1)
+	q.AddItem(FrontierItem{URL: "https://example.com/b/", Depth: 1, AnchorText: "B"})
+	if q.AddItem(FrontierItem{URL: "https://example.com/a#top", Depth: 2}) {
+		t.Error("AddItem() should reject a URL already queued")
+	}
+
+	item, ok := q.PopItemFunc(func(FrontierItem) bool { return true })
+	if !ok || item.URL != "https://example.com/b" || item.AnchorText != "B" {
+		t.Errorf("PopItemFunc() = %+v, want the normalized last URL with its anchor text", item)
+	}
+}
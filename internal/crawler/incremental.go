@@ -0,0 +1,162 @@
+package crawler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/refyne/pkg/schema"
+)
+
+// pagesFileName is the file page records are saved to in an incremental
+// crawl's directory.
+const pagesFileName = "pages.json"
+
+// ErrUnchanged is reported (on a skipped result) for a page whose content is
+// unchanged since the last crawl when Config.OmitUnchanged is set.
+var ErrUnchanged = errors.New("page unchanged since the last crawl")
+
+// PageRecord is what an incremental crawl remembers about a page it
+// extracted, to tell on the next crawl whether it needs extracting again.
+type PageRecord struct {
+	ContentHash  string          `json:"content_hash"`            // Digest of the schema and the extraction input (see ContentHash)
+	ETag         string          `json:"etag,omitempty"`          // Validator for a conditional request
+	LastModified string          `json:"last_modified,omitempty"` // Validator for a conditional request
+	Data         json.RawMessage `json:"data"`                    // Data last extracted from the page
+	ExtractedAt  time.Time       `json:"extracted_at"`
+	CheckedAt    time.Time       `json:"checked_at"` // Last crawl that found the page, changed or not
+}
+
+// PageStore keeps page records between crawls. It is safe for concurrent use.
+type PageStore struct {
+	path string
+
+	mu       sync.Mutex
+	pages    map[string]PageRecord
+	dirty    bool
+	lastSave time.Time
+}
+
+// OpenPageStore opens the page records in dir, creating the directory if
+// needed. A directory with no records yet opens an empty store.
+func OpenPageStore(dir string) (*PageStore, error) {
+	if dir == "" {
+		return nil, errors.New("incremental crawl directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create incremental crawl directory: %w", err)
+	}
+	s := &PageStore{
+		path:     filepath.Join(dir, pagesFileName),
+		pages:    make(map[string]PageRecord),
+		lastSave: time.Now(),
+	}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.pages); err != nil {
+		return nil, fmt.Errorf("corrupt page records %s: %w", s.path, err)
+	}
+	return s, nil
+}
+
+// Path returns the path of the records file.
+func (s *PageStore) Path() string {
+	return s.path
+}
+
+// Len returns the number of pages with records.
+func (s *PageStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pages)
+}
+
+// Get returns the record of the page with the given key (see FrontierItem).
+func (s *PageStore) Get(key string) (PageRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.pages[key]
+	return rec, ok
+}
+
+// Put sets the record of the page with the given key.
+func (s *PageStore) Put(key string, rec PageRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[key] = rec
+	s.dirty = true
+}
+
+// due reports whether records changed since they were saved more than
+// interval ago.
+func (s *PageStore) due(interval time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dirty && time.Since(s.lastSave) >= interval
+}
+
+// Save writes the records if they changed. The write is atomic: an
+// interrupted save leaves the previous records in place.
+func (s *PageStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSave = time.Now()
+	if !s.dirty {
+		return nil
+	}
+	data, err := json.Marshal(s.pages)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// ContentHash returns the digest a page is compared on between crawls: its
+// extraction input and the schema it is extracted with, so changing the
+// schema extracts every page again.
+func ContentHash(s schema.Schema, content string) string {
+	h := sha256.New()
+	if def, err := json.Marshal(s); err == nil {
+		h.Write(def)
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(content))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// decodeData returns the record's data as extracted (maps, slices, ...).
+func (rec PageRecord) decodeData() any {
+	var data any
+	if err := json.Unmarshal(rec.Data, &data); err != nil {
+		return nil
+	}
+	return data
+}
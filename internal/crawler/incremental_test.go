@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmylchreest/refyne/pkg/schema"
+)
+
+// --- Incremental Recrawl Tests ---
+
+func TestPageStore_SaveReopen(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "pages")
+	store, err := OpenPageStore(dir)
+	if err != nil {
+		t.Fatalf("OpenPageStore() error = %v", err)
+	}
+	if store.Len() != 0 {
+		t.Fatalf("Len() on a new store = %d, want 0", store.Len())
+	}
+
+	store.Put("https://example.com/a", PageRecord{
+		ContentHash: "abc",
+		ETag:        `"v1"`,
+		Data:        json.RawMessage(`{"title":"A"}`),
+	})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reopened, err := OpenPageStore(dir)
+	if err != nil {
+		t.Fatalf("OpenPageStore() after save error = %v", err)
+	}
+	rec, ok := reopened.Get("https://example.com/a")
+	if !ok || rec.ContentHash != "abc" || rec.ETag != `"v1"` {
+		t.Fatalf("Get() = %+v, %v; want the saved record", rec, ok)
+	}
+	if data, _ := rec.decodeData().(map[string]any); data["title"] != "A" {
+		t.Errorf("decodeData() = %v, want the saved data", rec.decodeData())
+	}
+}
+
+func TestOpenPageStore_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, pagesFileName), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenPageStore(dir); err == nil {
+		t.Error("OpenPageStore() with corrupt records should fail")
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	products := schema.Schema{Name: "products"}
+	articles := schema.Schema{Name: "articles"}
+
+	if ContentHash(products, "page") != ContentHash(products, "page") {
+		t.Error("ContentHash() should be stable")
+	}
+	if ContentHash(products, "page") == ContentHash(products, "page v2") {
+		t.Error("ContentHash() should change with the content")
+	}
+	if ContentHash(products, "page") == ContentHash(articles, "page") {
+		t.Error("ContentHash() should change with the schema")
+	}
+}
+
+func TestConditionalHeaders(t *testing.T) {
+	base := map[string]string{"Accept-Language": "en"}
+	headers := conditionalHeaders(base, PageRecord{ETag: `"v1"`, LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"})
+	if headers["If-None-Match"] != `"v1"` || headers["If-Modified-Since"] != "Mon, 02 Jan 2006 15:04:05 GMT" {
+		t.Errorf("conditionalHeaders() = %v, want both validators", headers)
+	}
+	if len(base) != 1 {
+		t.Errorf("conditionalHeaders() modified the caller's headers: %v", base)
+	}
+	if headers := conditionalHeaders(nil, PageRecord{}); len(headers) != 0 {
+		t.Errorf("conditionalHeaders() without validators = %v, want none", headers)
+	}
+}
@@ -0,0 +1,159 @@
+package crawler
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	refynecleaner "github.com/jmylchreest/refyne/pkg/cleaner/refyne"
+)
+
+// NormalizeConfig sets the rules URLs are normalized with before they are
+// queued and deduplicated. Fragments and trailing slashes are always removed;
+// the zero value applies no other rules.
+type NormalizeConfig struct {
+	StripTracking     bool     // Remove tracking parameters (utm_*, gclid, fbclid, ref, ...), as the cleaner does
+	SortQuery         bool     // Sort query parameters by name, so reordered queries match
+	LowercaseHost     bool     // Lowercase the scheme and host
+	RemoveDefaultPort bool     // Remove :80 from http and :443 from https URLs
+	IgnoreWWW         bool     // Treat www.example.com and example.com as the same host when deduplicating
+	IgnoreParams      []string // Query parameters to remove (case-insensitive); a trailing * matches a prefix, e.g. "session*"
+}
+
+// DefaultNormalizeConfig returns the crawler's default rules: everything
+// except IgnoreWWW, which can join hosts that serve different sites.
+func DefaultNormalizeConfig() NormalizeConfig {
+	return NormalizeConfig{
+		StripTracking:     true,
+		SortQuery:         true,
+		LowercaseHost:     true,
+		RemoveDefaultPort: true,
+	}
+}
+
+// URLNormalizer normalizes URLs with a NormalizeConfig.
+type URLNormalizer struct {
+	config         NormalizeConfig
+	ignore         map[string]bool
+	ignorePrefixes []string
+}
+
+// NewURLNormalizer creates a normalizer for cfg.
+func NewURLNormalizer(cfg NormalizeConfig) *URLNormalizer {
+	n := &URLNormalizer{config: cfg, ignore: make(map[string]bool)}
+	for _, p := range cfg.IgnoreParams {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			n.ignorePrefixes = append(n.ignorePrefixes, prefix)
+		} else if p != "" {
+			n.ignore[p] = true
+		}
+	}
+	return n
+}
+
+// plainNormalizer removes only fragments and trailing slashes.
+var plainNormalizer = NewURLNormalizer(NormalizeConfig{})
+
+// Normalize returns the normalized form of rawURL, which is the URL that is
+// crawled, or "" if it can't be parsed.
+func (n *URLNormalizer) Normalize(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	// Remove fragment
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+
+	// Remove trailing slash from path (unless it's just "/")
+	if len(parsed.Path) > 1 && parsed.Path[len(parsed.Path)-1] == '/' {
+		parsed.Path = parsed.Path[:len(parsed.Path)-1]
+		parsed.RawPath = ""
+	}
+
+	if n.config.LowercaseHost {
+		parsed.Scheme = strings.ToLower(parsed.Scheme)
+		parsed.Host = strings.ToLower(parsed.Host)
+	}
+	if n.config.RemoveDefaultPort {
+		port := parsed.Port()
+		if (port == "80" && strings.EqualFold(parsed.Scheme, "http")) || (port == "443" && strings.EqualFold(parsed.Scheme, "https")) {
+			parsed.Host = strings.TrimSuffix(parsed.Host, ":"+port)
+		}
+	}
+	if parsed.RawQuery != "" {
+		parsed.RawQuery = n.normalizeQuery(parsed.RawQuery)
+		parsed.ForceQuery = false
+	}
+
+	return parsed.String()
+}
+
+// normalizeQuery removes ignored parameters from a raw query and sorts it,
+// keeping each parameter's original encoding.
+func (n *URLNormalizer) normalizeQuery(rawQuery string) string {
+	params := strings.Split(rawQuery, "&")
+	kept := params[:0]
+	for _, param := range params {
+		if param == "" {
+			continue
+		}
+		if !n.ignoreParam(queryParamName(param)) {
+			kept = append(kept, param)
+		}
+	}
+	if n.config.SortQuery {
+		slices.SortStableFunc(kept, func(a, b string) int {
+			return strings.Compare(queryParamName(a), queryParamName(b))
+		})
+	}
+	return strings.Join(kept, "&")
+}
+
+// ignoreParam reports whether a query parameter is removed.
+func (n *URLNormalizer) ignoreParam(name string) bool {
+	if n.config.StripTracking && refynecleaner.IsTrackingParam(name) {
+		return true
+	}
+	lower := strings.ToLower(name)
+	if n.ignore[lower] {
+		return true
+	}
+	for _, prefix := range n.ignorePrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryParamName returns the decoded name of a raw "name=value" parameter.
+func queryParamName(param string) string {
+	name, _, _ := strings.Cut(param, "=")
+	if decoded, err := url.QueryUnescape(name); err == nil {
+		return decoded
+	}
+	return name
+}
+
+// Key returns the deduplication key of a normalized URL. It is the URL itself
+// unless IgnoreWWW is set, in which case a leading "www." is removed from the
+// host so both forms of a URL are crawled once.
+func (n *URLNormalizer) Key(normalized string) string {
+	if !n.config.IgnoreWWW {
+		return normalized
+	}
+	scheme, rest, ok := strings.Cut(normalized, "://")
+	if !ok {
+		return normalized
+	}
+	if host, ok := strings.CutPrefix(rest, "www."); ok {
+		return scheme + "://" + host
+	}
+	if host, ok := strings.CutPrefix(rest, "WWW."); ok {
+		return scheme + "://" + host
+	}
+	return normalized
+}
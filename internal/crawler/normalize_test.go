@@ -0,0 +1,101 @@
+package crawler
+
+import "testing"
+
+// --- URL Normalization Tests ---
+
+func TestURLNormalizer_Normalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   NormalizeConfig
+		input string
+		want  string
+	}{
+		{
+			name:  "zero config only strips fragment and slash",
+			input: "HTTPS://Example.com:443/a/?utm_source=x&b=2&a=1#top",
+			want:  "https://Example.com:443/a?utm_source=x&b=2&a=1",
+		},
+		{
+			name:  "tracking params",
+			cfg:   NormalizeConfig{StripTracking: true},
+			input: "https://example.com/p?id=7&utm_source=news&UTM_Medium=email&fbclid=abc&ref=home",
+			want:  "https://example.com/p?id=7",
+		},
+		{
+			name:  "sorted query keeps encoding and repeated values in order",
+			cfg:   NormalizeConfig{SortQuery: true},
+			input: "https://example.com/s?q=garden%20chairs&c=2&a=x&c=1",
+			want:  "https://example.com/s?a=x&c=2&c=1&q=garden%20chairs",
+		},
+		{
+			name:  "host and default port",
+			cfg:   NormalizeConfig{LowercaseHost: true, RemoveDefaultPort: true},
+			input: "HTTP://WWW.Example.COM:80/Path",
+			want:  "http://www.example.com/Path",
+		},
+		{
+			name:  "non-default port kept",
+			cfg:   NormalizeConfig{RemoveDefaultPort: true},
+			input: "https://example.com:8443/",
+			want:  "https://example.com:8443/",
+		},
+		{
+			name:  "ignored params and prefixes",
+			cfg:   NormalizeConfig{IgnoreParams: []string{"SessionID", "sort*"}},
+			input: "https://example.com/list?page=2&sessionid=abc&sort_by=price&sortdir=asc",
+			want:  "https://example.com/list?page=2",
+		},
+		{
+			name:  "empty query removed",
+			cfg:   DefaultNormalizeConfig(),
+			input: "https://example.com/p?utm_campaign=x",
+			want:  "https://example.com/p",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewURLNormalizer(tt.cfg).Normalize(tt.input); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLNormalizer_Key_IgnoreWWW(t *testing.T) {
+	n := NewURLNormalizer(NormalizeConfig{IgnoreWWW: true})
+	if a, b := n.Key("https://www.example.com/p"), n.Key("https://example.com/p"); a != b {
+		t.Errorf("Key() = %q and %q, want www and bare hosts to match", a, b)
+	}
+	plain := NewURLNormalizer(NormalizeConfig{})
+	if plain.Key("https://www.example.com/p") == plain.Key("https://example.com/p") {
+		t.Error("Key() should keep www without IgnoreWWW")
+	}
+}
+
+func TestURLQueue_Normalizer(t *testing.T) {
+	q := NewURLQueue()
+	cfg := DefaultNormalizeConfig()
+	cfg.IgnoreWWW = true
+	q.SetNormalizer(NewURLNormalizer(cfg))
+
+	if !q.Add("https://Example.com/product?id=1&colour=red&utm_source=ad", 1) {
+		t.Fatal("Add() should add a new URL")
+	}
+	for _, dup := range []string{
+		"https://example.com/product?colour=red&id=1",
+		"https://www.example.com:443/product?id=1&colour=red&gclid=x",
+	} {
+		if q.Add(dup, 1) {
+			t.Errorf("Add(%q) should be rejected as a duplicate", dup)
+		}
+	}
+	if q.MarkVisited("https://www.example.com/product?id=1&colour=red") {
+		t.Error("MarkVisited() should report an alias of a queued URL as seen")
+	}
+
+	url, _, _ := q.Pop()
+	if url != "https://example.com/product?colour=red&id=1" {
+		t.Errorf("Pop() = %q, want the normalized URL", url)
+	}
+}
@@ -3,69 +3,142 @@ package crawler
 
 import (
 	"net/url"
+	"slices"
 	"sync"
+
+	"github.com/jmylchreest/refyne/pkg/fetcher"
 )
 
-// URLQueue manages URLs to be crawled with deduplication.
+// URLQueue manages URLs to be crawled with deduplication. Requests with a
+// method or body (see AddRequest) are deduplicated on the whole request, so
+// different searches posted to the same URL are all crawled. The order URLs
+// are popped in is decided by the queue's Frontier.
 type URLQueue struct {
-	mu      sync.Mutex
-	queue   []queueItem
-	visited map[string]bool
+	mu         sync.Mutex
+	frontier   Frontier
+	normalizer *URLNormalizer
+	visited    map[string]bool
 }
 
-type queueItem struct {
-	URL   string
-	Depth int
+// NewURLQueue creates a new breadth-first URL queue.
+func NewURLQueue() *URLQueue {
+	return NewURLQueueWithFrontier(NewBFSFrontier())
 }
 
-// NewURLQueue creates a new URL queue.
-func NewURLQueue() *URLQueue {
+// NewURLQueueWithFrontier creates a URL queue that pops URLs in the order
+// decided by frontier.
+func NewURLQueueWithFrontier(frontier Frontier) *URLQueue {
 	return &URLQueue{
-		queue:   make([]queueItem, 0),
-		visited: make(map[string]bool),
+		frontier:   frontier,
+		normalizer: plainNormalizer,
+		visited:    make(map[string]bool),
+	}
+}
+
+// SetNormalizer sets the rules URLs are normalized and deduplicated with. It
+// must be called before any URLs are added.
+func (q *URLQueue) SetNormalizer(n *URLNormalizer) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.normalizer = n
+}
+
+// Normalize returns the normalized form of rawURL, as it would be queued.
+func (q *URLQueue) Normalize(rawURL string) string {
+	return q.normalizer.Normalize(rawURL)
+}
+
+// visitKey returns the deduplication key of a normalized item.
+func (q *URLQueue) visitKey(item FrontierItem) string {
+	key := q.normalizer.Key(item.URL)
+	if item.Request != nil {
+		return requestKey(key, *item.Request)
 	}
+	return key
 }
 
 // Add adds a URL to the queue if not already visited.
 func (q *URLQueue) Add(rawURL string, depth int) bool {
+	return q.AddRequest(fetcher.Request{URL: rawURL}, depth)
+}
+
+// AddRequest adds a request to the queue if the same request (method, URL,
+// body and headers) was not already visited.
+func (q *URLQueue) AddRequest(req fetcher.Request, depth int) bool {
+	item := FrontierItem{URL: req.URL, Depth: depth}
+	if !req.IsGet() {
+		item.Request = &req
+	}
+	return q.AddItem(item)
+}
+
+// AddItem adds an item to the queue if its URL (or request) was not already
+// visited. The item's URL is normalized and its Host set, once, so frontiers
+// and PopItemFunc callers can group items by host without parsing URLs. Its
+// Score is set by the frontier.
+func (q *URLQueue) AddItem(item FrontierItem) bool {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
 	// Normalize URL
-	normalized := normalizeURL(rawURL)
+	normalized := q.normalizer.Normalize(item.URL)
 	if normalized == "" {
 		return false
 	}
+	item.URL = normalized
+	item.Host = hostKey(normalized)
+	if item.Request != nil {
+		if item.Request.IsGet() {
+			item.Request = nil
+		} else {
+			req := *item.Request
+			req.URL = normalized
+			item.Request = &req
+		}
+	}
 
 	// Check if already visited or queued
-	if q.visited[normalized] {
+	key := q.visitKey(item)
+	if q.visited[key] {
 		return false
 	}
 
-	q.visited[normalized] = true
-	q.queue = append(q.queue, queueItem{URL: normalized, Depth: depth})
+	q.visited[key] = true
+	q.frontier.Push(item)
 	return true
 }
 
 // Pop removes and returns the next URL from the queue.
 func (q *URLQueue) Pop() (string, int, bool) {
-	q.mu.Lock()
-	defer q.mu.Unlock()
+	return q.PopFunc(func(string, int) bool { return true })
+}
 
-	if len(q.queue) == 0 {
-		return "", 0, false
-	}
+// PopFunc removes and returns the next queued URL for which accept returns true.
+// Items that are not accepted keep their position in the queue.
+func (q *URLQueue) PopFunc(accept func(url string, depth int) bool) (string, int, bool) {
+	url, depth, _, ok := q.PopRequestFunc(accept)
+	return url, depth, ok
+}
+
+// PopRequestFunc is PopFunc that also returns the request queued for the URL,
+// or nil if it is fetched with a plain GET.
+func (q *URLQueue) PopRequestFunc(accept func(url string, depth int) bool) (string, int, *fetcher.Request, bool) {
+	item, ok := q.PopItemFunc(func(item FrontierItem) bool { return accept(item.URL, item.Depth) })
+	return item.URL, item.Depth, item.Request, ok
+}
 
-	item := q.queue[0]
-	q.queue = q.queue[1:]
-	return item.URL, item.Depth, true
+// PopItemFunc is PopFunc that returns the whole queued item.
+func (q *URLQueue) PopItemFunc(accept func(FrontierItem) bool) (FrontierItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.frontier.PopFunc(accept)
 }
 
 // Len returns the number of items in the queue.
 func (q *URLQueue) Len() int {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	return len(q.queue)
+	return q.frontier.Len()
 }
 
 // TotalQueued returns the total number of URLs ever queued (including processed).
@@ -79,32 +152,64 @@ func (q *URLQueue) TotalQueued() int {
 func (q *URLQueue) IsVisited(rawURL string) bool {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	return q.visited[normalizeURL(rawURL)]
+	return q.visited[q.normalizer.Key(q.normalizer.Normalize(rawURL))]
 }
 
 // MarkVisited marks a URL as visited without adding to queue.
-func (q *URLQueue) MarkVisited(rawURL string) {
+// It returns true if the URL had not been seen before.
+func (q *URLQueue) MarkVisited(rawURL string) bool {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	q.visited[normalizeURL(rawURL)] = true
+	key := q.normalizer.Key(q.normalizer.Normalize(rawURL))
+	if q.visited[key] {
+		return false
+	}
+	q.visited[key] = true
+	return true
 }
 
-// normalizeURL normalizes a URL for comparison.
-func normalizeURL(rawURL string) string {
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
-		return ""
+// snapshot returns copies of the queued items and of the keys of every URL
+// and request seen, for saving the crawl's state.
+func (q *URLQueue) snapshot() ([]FrontierItem, []string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	visited := make([]string, 0, len(q.visited))
+	for key := range q.visited {
+		visited = append(visited, key)
 	}
+	slices.Sort(visited)
+	return q.frontier.Items(), visited
+}
 
-	// Remove fragment
-	parsed.Fragment = ""
-
-	// Remove trailing slash from path (unless it's just "/")
-	if len(parsed.Path) > 1 && parsed.Path[len(parsed.Path)-1] == '/' {
-		parsed.Path = parsed.Path[:len(parsed.Path)-1]
+// restore adds saved items to the queue, which must be empty, and replaces
+// its seen keys with saved ones.
+func (q *URLQueue) restore(items []FrontierItem, visited []string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, item := range items {
+		item.Host = hostKey(item.URL)
+		q.frontier.Push(item)
+	}
+	q.visited = make(map[string]bool, len(visited)+len(items))
+	for _, key := range visited {
+		q.visited[key] = true
 	}
+	for _, item := range items {
+		q.visited[q.visitKey(item)] = true
+	}
+}
 
-	return parsed.String()
+// requestKey identifies a request for deduplication: the normalized URL for a
+// plain GET, otherwise the method and URL plus a digest of the body and headers
+// (see fetcher.RequestKey).
+func requestKey(normalized string, req fetcher.Request) string {
+	return fetcher.RequestKey(normalized, req.Apply(fetcher.Options{}), req.Headers)
+}
+
+// normalizeURL normalizes a URL for comparison, removing only its fragment
+// and trailing slash.
+func normalizeURL(rawURL string) string {
+	return plainNormalizer.Normalize(rawURL)
 }
 
 // IsSameDomain checks if two URLs are on the same domain.
@@ -1,8 +1,11 @@
 package crawler
 
 import (
+	"strings"
 	"sync"
 	"testing"
+
+	"github.com/jmylchreest/refyne/pkg/fetcher"
 )
 
 // --- URLQueue Tests ---
@@ -114,6 +117,50 @@ func TestURLQueue_Pop_FIFO_Order(t *testing.T) {
 	}
 }
 
+func TestURLQueue_PopFunc_SkipsRejected(t *testing.T) {
+	q := NewURLQueue()
+	q.Add("https://a.example.com/1", 0)
+	q.Add("https://b.example.com/1", 1)
+	q.Add("https://a.example.com/2", 0)
+
+	url, depth, ok := q.PopFunc(func(url string, depth int) bool {
+		return strings.HasPrefix(url, "https://b.")
+	})
+	if !ok || url != "https://b.example.com/1" || depth != 1 {
+		t.Fatalf("PopFunc() = %q, %d, %v; want b.example.com/1, 1, true", url, depth, ok)
+	}
+
+	// Rejected items keep their order
+	if url, _, _ := q.Pop(); url != "https://a.example.com/1" {
+		t.Errorf("Pop() = %q, want a.example.com/1", url)
+	}
+	if _, _, ok := q.PopFunc(func(string, int) bool { return false }); ok {
+		t.Error("PopFunc() should return false when nothing is accepted")
+	}
+	if q.Len() != 1 {
+		t.Errorf("expected queue length 1, got %d", q.Len())
+	}
+}
+
+func TestURLQueue_PopItemFunc_SetsHost(t *testing.T) {
+	q := NewURLQueue()
+	q.Add("https://Shop.Example.com:8443/a", 0)
+
+	item, ok := q.PopItemFunc(func(item FrontierItem) bool {
+		return item.Host == "shop.example.com:8443"
+	})
+	if !ok {
+		t.Fatal("PopItemFunc() should offer items with their host set")
+	}
+
+	// Restored items have their host set too
+	restored := NewURLQueue()
+	restored.restore([]FrontierItem{{URL: item.URL}}, nil)
+	if item, _ := restored.PopItemFunc(func(FrontierItem) bool { return true }); item.Host != "shop.example.com:8443" {
+		t.Errorf("restored item Host = %q, want shop.example.com:8443", item.Host)
+	}
+}
+
 func TestURLQueue_Len(t *testing.T) {
 	q := NewURLQueue()
 
@@ -170,6 +217,17 @@ func TestURLQueue_MarkVisited(t *testing.T) {
 	}
 }
 
+func TestURLQueue_MarkVisited_ReportsFirstVisit(t *testing.T) {
+	q := NewURLQueue()
+
+	if !q.MarkVisited("https://example.com/page") {
+		t.Error("MarkVisited() should return true the first time")
+	}
+	if q.MarkVisited("https://example.com/page") {
+		t.Error("MarkVisited() should return false for an already-visited URL")
+	}
+}
+
 func TestURLQueue_MarkVisited_PreventsAdd(t *testing.T) {
 	q := NewURLQueue()
 
@@ -404,3 +462,35 @@ func TestURLQueue_NormalizesFragments(t *testing.T) {
 		t.Error("Add() should normalize fragments and detect duplicates")
 	}
 }
+
+func TestURLQueue_AddRequest_DedupOnRequest(t *testing.T) {
+	q := NewURLQueue()
+	search := func(query string) fetcher.Request {
+		return fetcher.Request{URL: "https://example.com/search", Form: map[string]string{"q": query}}
+	}
+
+	if !q.AddRequest(search("chairs"), 0) {
+		t.Fatal("AddRequest() should accept a new request")
+	}
+	if q.AddRequest(search("chairs"), 0) {
+		t.Error("AddRequest() should reject the same request twice")
+	}
+	if !q.AddRequest(search("tables"), 0) {
+		t.Error("AddRequest() should accept a different body for the same URL")
+	}
+	if !q.Add("https://example.com/search", 0) {
+		t.Error("Add() should accept a GET of a URL only posted to so far")
+	}
+	if q.AddRequest(fetcher.Request{URL: "https://example.com/search/", Method: "get"}, 1) {
+		t.Error("AddRequest() should treat an explicit GET like a plain URL")
+	}
+
+	url, _, req, ok := q.PopRequestFunc(func(string, int) bool { return true })
+	if !ok || url != "https://example.com/search" || req == nil || req.Form["q"] != "chairs" {
+		t.Fatalf("PopRequestFunc() = %q, %+v, %v; want the chairs search", url, req, ok)
+	}
+	q.Pop()
+	if _, _, req, _ := q.PopRequestFunc(func(string, int) bool { return true }); req != nil {
+		t.Errorf("PopRequestFunc() request = %+v, want nil for a plain URL", req)
+	}
+}
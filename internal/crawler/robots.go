@@ -0,0 +1,385 @@
+package crawler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+)
+
+// ErrRobotsDisallowed is reported for URLs that robots.txt does not allow us to crawl.
+var ErrRobotsDisallowed = errors.New("disallowed by robots.txt")
+
+// maxRobotsSize is the maximum robots.txt size we parse (RFC 9309 requires at least 500 KiB).
+const maxRobotsSize = 512 * 1024
+
+// DefaultRobotsAgent is the product token robots.txt groups are matched
+// against when the user agent is a browser's (see RobotsAgent).
+const DefaultRobotsAgent = "refyne"
+
+// robotsRetryInterval is how long an origin whose robots.txt couldn't be
+// fetched (server or network error) stays disallowed before it is fetched again.
+const robotsRetryInterval = time.Minute
+
+// robotsRule is a single Allow or Disallow line.
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+// robotsGroup is the set of rules that apply to one or more user agents.
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// RobotsRules holds a parsed robots.txt file.
+type RobotsRules struct {
+	groups []*robotsGroup
+	agents map[string]*robotsGroup // Every group naming an agent, merged, by lowercase agent
+
+	// allowAll/disallowAll short-circuit evaluation for missing or unreachable files.
+	allowAll    bool
+	disallowAll bool
+}
+
+// ParseRobots parses robots.txt content.
+// Unknown directives and malformed lines are ignored, as the spec requires.
+func ParseRobots(r io.Reader) *RobotsRules {
+	rules := &RobotsRules{}
+	var current *robotsGroup
+	inAgentLines := false
+
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share one group
+			if current == nil || !inAgentLines {
+				current = &robotsGroup{}
+				rules.groups = append(rules.groups, current)
+			}
+			if value != "*" {
+				value = productToken(value) // a version, as in "Googlebot/2.1", isn't part of the name
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			inAgentLines = true
+		case "allow", "disallow":
+			inAgentLines = false
+			if current == nil {
+				continue // rules before any user-agent line are ignored
+			}
+			if value == "" {
+				// "Disallow:" with no path allows everything; it adds no rule
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{pattern: value, allow: key == "allow"})
+		case "crawl-delay":
+			inAgentLines = false
+			if current == nil {
+				continue
+			}
+			if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+				current.crawlDelay = time.Duration(secs * float64(time.Second))
+			}
+		default:
+			// Sitemap and other non-group directives don't end the user-agent run
+		}
+	}
+
+	// Groups that name the same agent are combined (RFC 9309 section 2.2.1)
+	rules.agents = make(map[string]*robotsGroup)
+	for _, g := range rules.groups {
+		for _, agent := range g.agents {
+			merged := rules.agents[agent]
+			if merged == nil {
+				merged = &robotsGroup{agents: []string{agent}}
+				rules.agents[agent] = merged
+			}
+			merged.rules = append(merged.rules, g.rules...)
+			merged.crawlDelay = max(merged.crawlDelay, g.crawlDelay)
+		}
+	}
+
+	return rules
+}
+
+// RobotsAgent returns the product token of userAgent that robots.txt groups
+// are matched against: the first product in a "compatible" comment (as in
+// "Mozilla/5.0 (compatible; RefyneBot/1.0)"), or else the first product of
+// the user agent, lowercased. A browser's user agent, which names Mozilla
+// first, is matched as DefaultRobotsAgent rather than as any browser.
+func RobotsAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if _, comment, ok := strings.Cut(ua, "compatible;"); ok {
+		if token := productToken(comment); token != "" {
+			return token
+		}
+	}
+	if token := productToken(ua); token != "" && token != "mozilla" {
+		return token
+	}
+	return DefaultRobotsAgent
+}
+
+// productToken returns the product name at the start of s: the letters,
+// digits, "_" and "-" before its version or comment.
+func productToken(s string) string {
+	s = strings.TrimSpace(s)
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-')
+	})
+	if end < 0 {
+		return s
+	}
+	return s[:end]
+}
+
+// group returns the rules that apply to userAgent: those of the groups
+// naming its product token (see RobotsAgent), falling back to "*".
+func (r *RobotsRules) group(userAgent string) *robotsGroup {
+	if g, ok := r.agents[strings.ToLower(RobotsAgent(userAgent))]; ok {
+		return g
+	}
+	return r.agents["*"]
+}
+
+// Allowed reports whether userAgent may fetch the path (and query) of rawURL.
+// The most specific (longest) matching rule wins; Allow wins ties.
+func (r *RobotsRules) Allowed(userAgent, rawURL string) bool {
+	if r.allowAll {
+		return true
+	}
+	if r.disallowAll {
+		return false
+	}
+
+	g := r.group(userAgent)
+	if g == nil {
+		return true
+	}
+
+	path := "/"
+	if u, err := url.Parse(rawURL); err == nil {
+		path = u.EscapedPath()
+		if path == "" {
+			path = "/"
+		}
+		if u.RawQuery != "" {
+			path += "?" + u.RawQuery
+		}
+	}
+
+	allowed := true
+	matchLen := -1
+	for _, rule := range g.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		l := len(rule.pattern)
+		if l > matchLen || (l == matchLen && rule.allow) {
+			matchLen = l
+			allowed = rule.allow
+		}
+	}
+	return allowed
+}
+
+// CrawlDelay returns the Crawl-delay for userAgent, or 0 if none is set.
+func (r *RobotsRules) CrawlDelay(userAgent string) time.Duration {
+	if g := r.group(userAgent); g != nil {
+		return g.crawlDelay
+	}
+	return 0
+}
+
+// robotsMatch reports whether path matches a robots.txt pattern.
+// Patterns are prefix matches supporting "*" (any sequence) and a trailing "$" (end anchor).
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	// The first part must match at the start
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for i := 1; i < len(parts); i++ {
+		part := parts[i]
+		if i == len(parts)-1 && anchored {
+			// Last part must match at the end
+			return strings.HasSuffix(path[pos:], part)
+		}
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	return !anchored || pos == len(path)
+}
+
+// robotsEntry is a cached robots.txt for one origin.
+type robotsEntry struct {
+	ready   chan struct{}
+	rules   *RobotsRules // nil if the fetch was cancelled
+	expires time.Time    // Zero if the rules are kept for the whole crawl
+}
+
+// RobotsPolicy fetches, caches and evaluates robots.txt per origin.
+// It is safe for concurrent use.
+type RobotsPolicy struct {
+	userAgent     string
+	fetcher       fetcher.Fetcher
+	retryInterval time.Duration // How long fetch failures are cached
+
+	mu      sync.Mutex
+	entries map[string]*robotsEntry // scheme://host -> entry
+}
+
+// NewRobotsPolicy creates a policy that evaluates rules for userAgent and
+// fetches robots.txt with f, so it is cached, recorded and replayed like the
+// crawl's pages. If f is nil, a static fetcher with a 10s timeout is used.
+func NewRobotsPolicy(userAgent string, f fetcher.Fetcher) *RobotsPolicy {
+	if f == nil {
+		f = fetcher.NewStatic(fetcher.StaticConfig{Timeout: 10 * time.Second})
+	}
+	return &RobotsPolicy{
+		userAgent:     userAgent,
+		fetcher:       f,
+		retryInterval: robotsRetryInterval,
+		entries:       make(map[string]*robotsEntry),
+	}
+}
+
+// Allowed reports whether the configured user agent may crawl rawURL.
+// robots.txt is fetched once per origin on first use.
+func (p *RobotsPolicy) Allowed(ctx context.Context, rawURL string) bool {
+	rules := p.rules(ctx, rawURL)
+	if rules == nil {
+		return true // not an http(s) URL; nothing to evaluate
+	}
+	return rules.Allowed(p.userAgent, rawURL)
+}
+
+// CrawlDelay returns the Crawl-delay that applies to rawURL's origin.
+func (p *RobotsPolicy) CrawlDelay(ctx context.Context, rawURL string) time.Duration {
+	rules := p.rules(ctx, rawURL)
+	if rules == nil {
+		return 0
+	}
+	return rules.CrawlDelay(p.userAgent)
+}
+
+// rules returns the robots.txt rules for rawURL's origin, fetching them if
+// they aren't cached or have expired, or nil for a URL that isn't http(s).
+// A fetch that fails is cached for retryInterval; one cancelled by its
+// caller's ctx isn't cached, and callers waiting on it fetch again. Callers
+// whose own ctx is cancelled get rules that disallow everything.
+func (p *RobotsPolicy) rules(ctx context.Context, rawURL string) *RobotsRules {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil
+	}
+	origin := u.Scheme + "://" + strings.ToLower(u.Host)
+
+	for {
+		p.mu.Lock()
+		entry, ok := p.entries[origin]
+		if ok && !entry.expires.IsZero() && time.Now().After(entry.expires) {
+			ok = false
+		}
+		if !ok {
+			entry = &robotsEntry{ready: make(chan struct{})}
+			p.entries[origin] = entry
+		}
+		p.mu.Unlock()
+
+		if !ok {
+			rules, ttl := p.fetch(ctx, origin)
+			p.mu.Lock()
+			entry.rules = rules
+			switch {
+			case rules == nil:
+				if p.entries[origin] == entry {
+					delete(p.entries, origin)
+				}
+			case ttl > 0:
+				entry.expires = time.Now().Add(ttl)
+			}
+			p.mu.Unlock()
+			close(entry.ready)
+			if rules == nil {
+				return &RobotsRules{disallowAll: true}
+			}
+			return rules
+		}
+
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			return &RobotsRules{disallowAll: true}
+		}
+		if entry.rules != nil {
+			return entry.rules
+		}
+	}
+}
+
+// fetch retrieves and parses robots.txt for origin, following RFC 9309:
+// 4xx means no restrictions; 5xx or an unreachable server means full disallow.
+// A robots.txt missing from a replayed archive is treated as a 404.
+// It returns how long the rules may be cached (0 for the whole crawl), or nil
+// rules if ctx was cancelled.
+func (p *RobotsPolicy) fetch(ctx context.Context, origin string) (*RobotsRules, time.Duration) {
+	robotsURL := origin + "/robots.txt"
+	logger.Debug("fetching robots.txt", "url", robotsURL)
+
+	content, err := p.fetcher.Fetch(ctx, robotsURL, fetcher.Options{
+		UserAgent:           p.userAgent,
+		AllowedContentTypes: []string{"*/*"}, // whatever type it is served as
+	})
+	switch {
+	case ctx.Err() != nil:
+		return nil, 0
+	case errors.Is(err, fetcher.ErrNotArchived):
+		logger.Debug("robots.txt not archived, all paths allowed", "url", robotsURL)
+		return &RobotsRules{allowAll: true}, 0
+	case content.StatusCode >= 400 && content.StatusCode < 500:
+		logger.Debug("no robots.txt, all paths allowed", "url", robotsURL, "status", content.StatusCode)
+		return &RobotsRules{allowAll: true}, 0
+	case content.StatusCode >= 500:
+		logger.Warn("robots.txt server error, treating origin as disallowed", "url", robotsURL, "status", content.StatusCode, "retry_in", p.retryInterval)
+		return &RobotsRules{disallowAll: true}, p.retryInterval
+	case err != nil:
+		logger.Warn("robots.txt unreachable, treating origin as disallowed", "url", robotsURL, "error", err, "retry_in", p.retryInterval)
+		return &RobotsRules{disallowAll: true}, p.retryInterval
+	}
+
+	rules := ParseRobots(strings.NewReader(content.HTML))
+	logger.Debug("robots.txt loaded", "url", robotsURL, "groups", len(rules.groups), "from_cache", content.FromCache)
+	return rules, 0
+}
@@ -0,0 +1,329 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+)
+
+const testRobots = `
+# Comment line
+User-agent: refynebot
+Disallow: /private
+Allow: /private/public
+Crawl-delay: 2
+
+User-agent: otherbot
+User-agent: thirdbot
+Disallow: /
+
+User-agent: *
+Disallow: /admin
+Disallow: /*.pdf$
+Disallow: /search?*sort=
+Allow: /admin/help
+Crawl-delay: 0.5
+`
+
+// --- RobotsRules Tests ---
+
+func TestRobotsRules_Allowed(t *testing.T) {
+	rules := ParseRobots(strings.NewReader(testRobots))
+
+	tests := []struct {
+		agent string
+		url   string
+		want  bool
+	}{
+		// Specific group
+		{"Mozilla/5.0 (compatible; RefyneBot/1.0)", "https://example.com/", true},
+		{"Mozilla/5.0 (compatible; RefyneBot/1.0)", "https://example.com/private/data", false},
+		{"Mozilla/5.0 (compatible; RefyneBot/1.0)", "https://example.com/private/public/page", true},
+		{"Mozilla/5.0 (compatible; RefyneBot/1.0)", "https://example.com/admin", true}, // wildcard group doesn't apply
+
+		// Grouped user-agent lines
+		{"otherbot", "https://example.com/anything", false},
+		{"thirdbot", "https://example.com/anything", false},
+
+		// Wildcard group
+		{"Mozilla/5.0 Chrome/120", "https://example.com/admin/users", false},
+		{"Mozilla/5.0 Chrome/120", "https://example.com/admin/help", true},
+		{"Mozilla/5.0 Chrome/120", "https://example.com/files/spec.pdf", false},
+		{"Mozilla/5.0 Chrome/120", "https://example.com/files/spec.pdf?x=1", true}, // $ anchors the end
+		{"Mozilla/5.0 Chrome/120", "https://example.com/search?q=a&sort=price", false},
+		{"Mozilla/5.0 Chrome/120", "https://example.com/search?q=a", true},
+		{"Mozilla/5.0 Chrome/120", "https://example.com/products/1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agent+" "+tt.url, func(t *testing.T) {
+			if got := rules.Allowed(tt.agent, tt.url); got != tt.want {
+				t.Errorf("Allowed(%q, %q) = %v, want %v", tt.agent, tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRobotsRules_CrawlDelay(t *testing.T) {
+	rules := ParseRobots(strings.NewReader(testRobots))
+
+	if got := rules.CrawlDelay("RefyneBot/1.0"); got != 2*time.Second {
+		t.Errorf("CrawlDelay(refynebot) = %v, want 2s", got)
+	}
+	if got := rules.CrawlDelay("Chrome"); got != 500*time.Millisecond {
+		t.Errorf("CrawlDelay(*) = %v, want 500ms", got)
+	}
+	if got := rules.CrawlDelay("otherbot"); got != 0 {
+		t.Errorf("CrawlDelay(otherbot) = %v, want 0", got)
+	}
+}
+
+func TestRobotsRules_EmptyDisallowAllowsAll(t *testing.T) {
+	rules := ParseRobots(strings.NewReader("User-agent: *\nDisallow:\n"))
+	if !rules.Allowed("anybot", "https://example.com/anything") {
+		t.Error("empty Disallow should allow everything")
+	}
+}
+
+func TestRobotsAgent(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{"Mozilla/5.0 (compatible; RefyneBot/1.0; +https://example.com/bot)", "refynebot"},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1)", "googlebot"},
+		{"otherbot/2.0", "otherbot"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", DefaultRobotsAgent},
+		{"", DefaultRobotsAgent},
+	}
+	for _, tt := range tests {
+		if got := RobotsAgent(tt.userAgent); got != tt.want {
+			t.Errorf("RobotsAgent(%q) = %q, want %q", tt.userAgent, got, tt.want)
+		}
+	}
+}
+
+func TestRobotsRules_ProductTokenMatching(t *testing.T) {
+	rules := ParseRobots(strings.NewReader(`
+User-agent: Safari
+User-agent: Mozilla
+Disallow: /
+
+User-agent: refyne
+Disallow: /private
+
+User-agent: *
+Disallow: /admin
+`))
+	chrome := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+	// Tokens that merely appear in the user agent don't select a group
+	if !rules.Allowed(chrome, "https://example.com/page") {
+		t.Error("a browser user agent should not match the Safari or Mozilla group")
+	}
+	if rules.Allowed(chrome, "https://example.com/private") {
+		t.Error("a browser user agent should match the default agent's group")
+	}
+	if !rules.Allowed(chrome, "https://example.com/admin") {
+		t.Error("the wildcard group should not apply when a named group matches")
+	}
+}
+
+func TestRobotsRules_MergesGroups(t *testing.T) {
+	rules := ParseRobots(strings.NewReader(`
+User-agent: refynebot
+Disallow: /a
+Crawl-delay: 1
+
+User-agent: otherbot
+Disallow: /b
+
+User-agent: RefyneBot/2.0
+Disallow: /c
+Crawl-delay: 3
+`))
+	agent := "RefyneBot/1.0"
+	for _, path := range []string{"/a", "/c"} {
+		if rules.Allowed(agent, "https://example.com"+path) {
+			t.Errorf("%s should be disallowed by one of the merged groups", path)
+		}
+	}
+	if !rules.Allowed(agent, "https://example.com/b") {
+		t.Error("another agent's rules should not apply")
+	}
+	if got := rules.CrawlDelay(agent); got != 3*time.Second {
+		t.Errorf("CrawlDelay() = %v, want the largest of the merged groups (3s)", got)
+	}
+}
+
+// --- RobotsPolicy Tests ---
+
+func TestRobotsPolicy_FetchesOncePerHost(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			hits.Add(1)
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /blocked\n"))
+		}
+	}))
+	defer srv.Close()
+
+	p := NewRobotsPolicy("testbot", fetcher.NewStatic(fetcher.StaticConfig{}))
+	ctx := context.Background()
+
+	if !p.Allowed(ctx, srv.URL+"/ok") {
+		t.Error("expected /ok to be allowed")
+	}
+	if p.Allowed(ctx, srv.URL+"/blocked/page") {
+		t.Error("expected /blocked/page to be disallowed")
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("robots.txt fetched %d times, want 1", n)
+	}
+}
+
+func TestRobotsPolicy_StatusHandling(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   bool
+	}{
+		{"not found allows all", http.StatusNotFound, true},
+		{"forbidden allows all", http.StatusForbidden, true},
+		{"server error disallows all", http.StatusServiceUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			p := NewRobotsPolicy("testbot", fetcher.NewStatic(fetcher.StaticConfig{}))
+			if got := p.Allowed(context.Background(), srv.URL+"/page"); got != tt.want {
+				t.Errorf("Allowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRobotsPolicy_RetriesFailures(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /blocked\n"))
+	}))
+	defer srv.Close()
+
+	p := NewRobotsPolicy("testbot", fetcher.NewStatic(fetcher.StaticConfig{}))
+	p.retryInterval = 10 * time.Millisecond
+	ctx := context.Background()
+
+	if p.Allowed(ctx, srv.URL+"/page") {
+		t.Fatal("expected the origin to be disallowed while robots.txt fails")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if !p.Allowed(ctx, srv.URL+"/page") {
+		t.Error("expected robots.txt to be fetched again after the retry interval")
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("robots.txt fetched %d times, want 2", n)
+	}
+}
+
+func TestRobotsPolicy_CancelledFetchNotCached(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /blocked\n"))
+	}))
+	defer srv.Close()
+
+	p := NewRobotsPolicy("testbot", fetcher.NewStatic(fetcher.StaticConfig{}))
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if p.Allowed(cancelled, srv.URL+"/page") {
+		t.Error("expected a cancelled lookup to disallow")
+	}
+	if !p.Allowed(context.Background(), srv.URL+"/page") {
+		t.Error("a cancelled fetch should not be cached for later lookups")
+	}
+}
+
+func TestRobotsPolicy_WaiterContext(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte("User-agent: *\nDisallow:\n"))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	p := NewRobotsPolicy("testbot", fetcher.NewStatic(fetcher.StaticConfig{}))
+	go p.Allowed(context.Background(), srv.URL+"/page")
+	<-started
+
+	// A second lookup waits for the first fetch, but only as long as its own ctx
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	done := make(chan bool)
+	go func() { done <- p.Allowed(ctx, srv.URL+"/page") }()
+	select {
+	case allowed := <-done:
+		if allowed {
+			t.Error("expected a lookup whose ctx expired to disallow")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("lookup ignored its ctx while waiting for robots.txt")
+	}
+}
+
+// mapFetcher serves bodies from a map of URL to body, as a replayed archive
+// would; other URLs fail as absent from the archive.
+type mapFetcher struct {
+	bodies     map[string]string
+	userAgents []string
+}
+
+func (f *mapFetcher) Fetch(_ context.Context, url string, opts fetcher.Options) (fetcher.Content, error) {
+	f.userAgents = append(f.userAgents, opts.UserAgent)
+	body, ok := f.bodies[url]
+	if !ok {
+		return fetcher.Content{URL: url}, fmt.Errorf("%w: %s", fetcher.ErrNotArchived, url)
+	}
+	return fetcher.Content{URL: url, StatusCode: http.StatusOK, ContentType: "text/plain", HTML: body}, nil
+}
+
+func (f *mapFetcher) Close() error { return nil }
+func (f *mapFetcher) Type() string { return "replay" }
+
+func TestRobotsPolicy_UsesFetcher(t *testing.T) {
+	f := &mapFetcher{bodies: map[string]string{
+		"https://example.com/robots.txt": "User-agent: *\nDisallow: /private\n",
+	}}
+	p := NewRobotsPolicy("testbot", f)
+	ctx := context.Background()
+
+	if p.Allowed(ctx, "https://example.com/private/page") {
+		t.Error("expected the fetched rules to disallow /private")
+	}
+	if len(f.userAgents) != 1 || f.userAgents[0] != "testbot" {
+		t.Errorf("fetches = %v, want one with the policy's user agent", f.userAgents)
+	}
+
+	// A replayed crawl that didn't record robots.txt isn't blocked
+	if !p.Allowed(ctx, "https://other.example.com/page") {
+		t.Error("expected a robots.txt missing from the archive to allow everything")
+	}
+}
@@ -0,0 +1,286 @@
+package crawler
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+)
+
+// HostLimits configures per-host politeness.
+type HostLimits struct {
+	RequestsPerSecond float64 // Sustained request rate per host (0 = unlimited)
+	Burst             int     // Requests that may be made back-to-back after an idle period (min 1)
+	MaxInFlight       int     // Max concurrent requests per host (0 = unlimited)
+	MaxBackoff        float64 // Cap on the adaptive slowdown factor (default 32)
+}
+
+// Outcome describes how a request to a host went, for adaptive backoff.
+type Outcome struct {
+	StatusCode int
+	Latency    time.Duration
+	RetryAfter time.Duration // From a Retry-After header, if any
+}
+
+// Adaptive backoff tuning.
+const (
+	defaultMaxBackoff = 32.0
+	throttleFactor    = 2.0  // Slowdown applied on 429/503
+	slowFactor        = 1.5  // Slowdown applied when latency degrades
+	recoveryFactor    = 0.8  // Speed-up applied on each healthy response
+	slowThreshold     = 3    // Latency EWMA this many times the baseline counts as "slow"
+	latencyWeight     = 0.2  // EWMA weight of the newest latency sample
+	baselineDrift     = 0.05 // How quickly the baseline follows sustained latency changes
+)
+
+// hostState is the scheduler's view of a single host.
+type hostState struct {
+	tokens      float64
+	updated     time.Time
+	inFlight    int
+	backoff     float64       // >= 1; the effective rate is RequestsPerSecond / backoff
+	minInterval time.Duration // Floor on the per-request interval (e.g., robots.txt Crawl-delay)
+	notBefore   time.Time     // No requests before this time (Retry-After)
+	latency     time.Duration // EWMA of response latency
+	baseline    time.Duration // Slowly-tracking "normal" latency
+}
+
+// HostScheduler enforces per-host politeness: a token bucket per host, a cap on
+// concurrent requests per host, and adaptive backoff when a host returns
+// 429/503 or slows down. It is safe for concurrent use.
+type HostScheduler struct {
+	mu     sync.Mutex
+	limits HostLimits
+	hosts  map[string]*hostState
+	now    func() time.Time
+	ready  chan struct{}
+}
+
+// NewHostScheduler creates a scheduler with the given limits.
+func NewHostScheduler(limits HostLimits) *HostScheduler {
+	if limits.Burst < 1 {
+		limits.Burst = 1
+	}
+	if limits.MaxBackoff < 1 {
+		limits.MaxBackoff = defaultMaxBackoff
+	}
+	return &HostScheduler{
+		limits: limits,
+		hosts:  make(map[string]*hostState),
+		now:    time.Now,
+		ready:  make(chan struct{}, 1),
+	}
+}
+
+// Ready returns a channel that receives a value when a slot is released, so a
+// dispatcher blocked on per-host limits can retry without polling.
+func (s *HostScheduler) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// notify signals Ready without blocking.
+func (s *HostScheduler) notify() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// hostKey returns the scheduling key (lowercased host:port) for a URL.
+func hostKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// state returns the state for host, creating it with a full bucket. Caller holds s.mu.
+func (s *HostScheduler) state(host string, now time.Time) *hostState {
+	h, ok := s.hosts[host]
+	if !ok {
+		h = &hostState{
+			tokens:  float64(s.limits.Burst),
+			updated: now,
+			backoff: 1,
+		}
+		s.hosts[host] = h
+	}
+	return h
+}
+
+// interval returns the time to earn one token for h (0 = unlimited).
+func (s *HostScheduler) interval(h *hostState) time.Duration {
+	var interval time.Duration
+	if s.limits.RequestsPerSecond > 0 {
+		interval = time.Duration(float64(time.Second) / s.limits.RequestsPerSecond * h.backoff)
+	}
+	if h.minInterval > interval {
+		interval = h.minInterval
+	}
+	return interval
+}
+
+// burst returns the bucket size for h. A Crawl-delay forbids bursts.
+func (s *HostScheduler) burst(h *hostState) float64 {
+	if h.minInterval > 0 {
+		return 1
+	}
+	return float64(s.limits.Burst)
+}
+
+// refill adds the tokens earned since the last update. Caller holds s.mu.
+func (s *HostScheduler) refill(h *hostState, now time.Time) {
+	interval := s.interval(h)
+	burst := s.burst(h)
+	if interval <= 0 {
+		h.tokens = burst
+	} else if elapsed := now.Sub(h.updated); elapsed > 0 {
+		h.tokens += float64(elapsed) / float64(interval)
+	}
+	if h.tokens > burst {
+		h.tokens = burst
+	}
+	h.updated = now
+}
+
+// TryAcquire reserves a request slot for rawURL's host if one is available now.
+// Every successful TryAcquire must be followed by exactly one Done or Cancel.
+func (s *HostScheduler) TryAcquire(rawURL string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	h := s.state(hostKey(rawURL), now)
+	s.refill(h, now)
+
+	if now.Before(h.notBefore) {
+		return false
+	}
+	if s.limits.MaxInFlight > 0 && h.inFlight >= s.limits.MaxInFlight {
+		return false
+	}
+	if s.interval(h) > 0 {
+		if h.tokens < 1 {
+			return false
+		}
+		h.tokens--
+	}
+	h.inFlight++
+	return true
+}
+
+// Cancel releases a slot acquired with TryAcquire without making a request.
+func (s *HostScheduler) Cancel(rawURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := s.state(hostKey(rawURL), s.now())
+	if h.inFlight > 0 {
+		h.inFlight--
+	}
+	if burst := s.burst(h); h.tokens+1 <= burst {
+		h.tokens++
+	}
+	s.notify()
+}
+
+// Done releases a slot and adapts the host's rate to the outcome.
+func (s *HostScheduler) Done(rawURL string, o Outcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	host := hostKey(rawURL)
+	h := s.state(host, now)
+	if h.inFlight > 0 {
+		h.inFlight--
+	}
+	defer s.notify()
+
+	switch {
+	case o.StatusCode == http.StatusTooManyRequests || o.StatusCode == http.StatusServiceUnavailable:
+		s.refill(h, now)
+		h.backoff = min(h.backoff*throttleFactor, s.limits.MaxBackoff)
+		h.tokens = 0 // make the next request wait a full (slower) interval
+		if o.RetryAfter > 0 {
+			h.notBefore = now.Add(o.RetryAfter)
+		}
+		logger.Info("host throttling us, backing off",
+			"host", host,
+			"status", o.StatusCode,
+			"backoff", h.backoff,
+			"retry_after", o.RetryAfter)
+
+	case o.StatusCode >= 200 && o.StatusCode < 400 && o.Latency > 0:
+		if h.latency == 0 {
+			h.latency = o.Latency
+			h.baseline = o.Latency
+		} else {
+			h.latency = time.Duration((1-latencyWeight)*float64(h.latency) + latencyWeight*float64(o.Latency))
+			if h.latency < h.baseline {
+				h.baseline = h.latency
+			} else {
+				h.baseline += time.Duration(baselineDrift * float64(h.latency-h.baseline))
+			}
+		}
+
+		if h.latency > slowThreshold*h.baseline {
+			h.backoff = min(h.backoff*slowFactor, s.limits.MaxBackoff)
+			logger.Debug("host slowing down, backing off",
+				"host", host,
+				"latency", h.latency,
+				"baseline", h.baseline,
+				"backoff", h.backoff)
+		} else {
+			h.backoff = max(h.backoff*recoveryFactor, 1)
+		}
+	}
+}
+
+// SetMinInterval sets a floor on the interval between requests to rawURL's
+// host (e.g., from robots.txt Crawl-delay).
+func (s *HostScheduler) SetMinInterval(rawURL string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	h := s.state(hostKey(rawURL), now)
+	if d != h.minInterval {
+		s.refill(h, now)
+		h.minInterval = d
+	}
+}
+
+// NextReady returns how long until a rate-limited host earns its next token.
+// It returns fallback when no host is waiting on a token; hosts blocked only by
+// their in-flight limit become ready when a request completes, not with time.
+func (s *HostScheduler) NextReady(fallback time.Duration) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	next := fallback
+	for _, h := range s.hosts {
+		if s.limits.MaxInFlight > 0 && h.inFlight >= s.limits.MaxInFlight {
+			continue
+		}
+		s.refill(h, now)
+		var wait time.Duration
+		if now.Before(h.notBefore) {
+			wait = h.notBefore.Sub(now)
+		}
+		if h.tokens < 1 {
+			if tokenWait := time.Duration((1 - h.tokens) * float64(s.interval(h))); tokenWait > wait {
+				wait = tokenWait
+			}
+		}
+		if wait > 0 && wait < next {
+			next = wait
+		}
+	}
+	return next
+}
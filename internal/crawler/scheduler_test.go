@@ -0,0 +1,164 @@
+package crawler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTestScheduler returns a scheduler driven by a manual clock.
+func newTestScheduler(limits HostLimits) (*HostScheduler, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewHostScheduler(limits)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+// --- HostScheduler Tests ---
+
+func TestHostScheduler_TokenBucket(t *testing.T) {
+	s, now := newTestScheduler(HostLimits{RequestsPerSecond: 2, Burst: 2})
+	url := "https://example.com/page"
+
+	for i := 0; i < 2; i++ {
+		if !s.TryAcquire(url) {
+			t.Fatalf("TryAcquire() #%d should use the burst", i+1)
+		}
+		s.Done(url, Outcome{StatusCode: http.StatusOK})
+	}
+	if s.TryAcquire(url) {
+		t.Fatal("TryAcquire() should fail once the burst is spent")
+	}
+	if got := s.NextReady(time.Minute); got != 500*time.Millisecond {
+		t.Errorf("NextReady() = %v, want 500ms", got)
+	}
+
+	*now = now.Add(500 * time.Millisecond)
+	if !s.TryAcquire(url) {
+		t.Error("TryAcquire() should succeed after one interval")
+	}
+}
+
+func TestHostScheduler_HostsAreIndependent(t *testing.T) {
+	s, _ := newTestScheduler(HostLimits{RequestsPerSecond: 1})
+
+	if !s.TryAcquire("https://a.example.com/1") {
+		t.Fatal("first request to host a should be allowed")
+	}
+	if s.TryAcquire("https://a.example.com/2") {
+		t.Error("second request to host a should wait")
+	}
+	if !s.TryAcquire("https://b.example.com/1") {
+		t.Error("host b should not be limited by host a")
+	}
+}
+
+func TestHostScheduler_MaxInFlight(t *testing.T) {
+	s, _ := newTestScheduler(HostLimits{MaxInFlight: 1})
+	url := "https://example.com/page"
+
+	if !s.TryAcquire(url) {
+		t.Fatal("first TryAcquire() should succeed")
+	}
+	if s.TryAcquire(url) {
+		t.Fatal("TryAcquire() should fail while the host is at its in-flight limit")
+	}
+	s.Done(url, Outcome{StatusCode: http.StatusOK})
+
+	select {
+	case <-s.Ready():
+	default:
+		t.Error("Done() should signal Ready")
+	}
+	if !s.TryAcquire(url) {
+		t.Error("TryAcquire() should succeed after Done()")
+	}
+}
+
+func TestHostScheduler_BacksOffOnThrottle(t *testing.T) {
+	s, now := newTestScheduler(HostLimits{RequestsPerSecond: 10})
+	url := "https://example.com/page"
+
+	if !s.TryAcquire(url) {
+		t.Fatal("first TryAcquire() should succeed")
+	}
+	s.Done(url, Outcome{StatusCode: http.StatusTooManyRequests, RetryAfter: 5 * time.Second})
+
+	*now = now.Add(time.Second)
+	if s.TryAcquire(url) {
+		t.Fatal("TryAcquire() should honour Retry-After")
+	}
+	if got := s.NextReady(time.Minute); got != 4*time.Second {
+		t.Errorf("NextReady() = %v, want 4s", got)
+	}
+
+	*now = now.Add(4 * time.Second)
+	if !s.TryAcquire(url) {
+		t.Fatal("TryAcquire() should succeed once Retry-After has passed")
+	}
+	s.Done(url, Outcome{StatusCode: http.StatusServiceUnavailable})
+
+	// Backoff doubled twice: 10 rps -> 2.5 rps
+	*now = now.Add(300 * time.Millisecond)
+	if s.TryAcquire(url) {
+		t.Error("TryAcquire() should wait the backed-off interval")
+	}
+	*now = now.Add(100 * time.Millisecond)
+	if !s.TryAcquire(url) {
+		t.Error("TryAcquire() should succeed after the backed-off interval")
+	}
+}
+
+func TestHostScheduler_BacksOffWhenSlow(t *testing.T) {
+	s, _ := newTestScheduler(HostLimits{RequestsPerSecond: 10})
+	url := "https://example.com/page"
+	key := hostKey(url)
+
+	for i := 0; i < 3; i++ {
+		s.TryAcquire(url)
+		s.Done(url, Outcome{StatusCode: http.StatusOK, Latency: 100 * time.Millisecond})
+	}
+	for i := 0; i < 10; i++ {
+		s.TryAcquire(url)
+		s.Done(url, Outcome{StatusCode: http.StatusOK, Latency: 5 * time.Second})
+	}
+	if b := s.hosts[key].backoff; b <= 1 {
+		t.Fatalf("backoff = %v, want > 1 after sustained slow responses", b)
+	}
+
+	slow := s.hosts[key].backoff
+	for i := 0; i < 50; i++ {
+		s.TryAcquire(url)
+		s.Done(url, Outcome{StatusCode: http.StatusOK, Latency: 100 * time.Millisecond})
+	}
+	if b := s.hosts[key].backoff; b >= slow {
+		t.Errorf("backoff = %v, want recovery below %v", b, slow)
+	}
+}
+
+func TestHostScheduler_MinIntervalOverridesRate(t *testing.T) {
+	s, now := newTestScheduler(HostLimits{RequestsPerSecond: 100, Burst: 5})
+	url := "https://example.com/page"
+	s.SetMinInterval(url, 2*time.Second)
+
+	if !s.TryAcquire(url) {
+		t.Fatal("first TryAcquire() should succeed")
+	}
+	*now = now.Add(time.Second)
+	if s.TryAcquire(url) {
+		t.Error("Crawl-delay should prevent bursting")
+	}
+	*now = now.Add(time.Second)
+	if !s.TryAcquire(url) {
+		t.Error("TryAcquire() should succeed after the Crawl-delay")
+	}
+}
+
+func TestHostScheduler_Unlimited(t *testing.T) {
+	s, _ := newTestScheduler(HostLimits{})
+	for i := 0; i < 100; i++ {
+		if !s.TryAcquire("https://example.com/page") {
+			t.Fatalf("TryAcquire() #%d should succeed without limits", i+1)
+		}
+	}
+}
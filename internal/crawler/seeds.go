@@ -0,0 +1,317 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+)
+
+// maxSeedDocumentSize caps a sitemap or feed after decompression (the sitemap
+// protocol limit is 50 MiB).
+const maxSeedDocumentSize = 50 * 1024 * 1024
+
+// maxSitemapDepth bounds how deeply sitemap indexes may nest.
+const maxSitemapDepth = 3
+
+// SeedSource is a sitemap, sitemap index or RSS/Atom feed whose entries are
+// added to a crawl as seed URLs.
+type SeedSource struct {
+	URL     string    // sitemap.xml (optionally gzipped), sitemap index, or RSS/Atom feed
+	Since   time.Time // Skip entries last modified/published before this (zero = no limit)
+	Until   time.Time // Skip entries last modified/published after this (zero = no limit)
+	Pattern string    // Only keep URLs matching this regex (empty = all)
+}
+
+// seedEntry is one URL listed in a sitemap or feed.
+type seedEntry struct {
+	loc  string
+	date string // lastmod, pubDate, updated or published; may be empty
+}
+
+// SeedExpander fetches seed sources and expands them into URLs.
+type SeedExpander struct {
+	userAgent string
+	fetcher   fetcher.Fetcher
+}
+
+// NewSeedExpander creates an expander that fetches with userAgent through f,
+// so sitemaps and feeds are cached, recorded and replayed like the crawl's
+// pages. If f is nil, a static fetcher with a 30s timeout is used.
+func NewSeedExpander(userAgent string, f fetcher.Fetcher) *SeedExpander {
+	if f == nil {
+		f = fetcher.NewStatic(fetcher.StaticConfig{Timeout: 30 * time.Second})
+	}
+	return &SeedExpander{userAgent: userAgent, fetcher: f}
+}
+
+// Expand returns the URLs listed by src that fall inside its date window and
+// match its pattern, in document order without duplicates. Sitemap indexes are
+// followed; entries without a date are always kept.
+func (e *SeedExpander) Expand(ctx context.Context, src SeedSource) ([]string, error) {
+	var pattern *regexp.Regexp
+	if src.Pattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(src.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid seed pattern: %w", err)
+		}
+	}
+
+	var urls []string
+	seen := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	var expand func(docURL string, depth int) error
+	expand = func(docURL string, depth int) error {
+		if visited[docURL] {
+			return nil
+		}
+		visited[docURL] = true
+
+		entries, children, err := e.fetch(ctx, docURL)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if seen[entry.loc] || !inWindow(entry.date, src.Since, src.Until) {
+				continue
+			}
+			if pattern != nil && !pattern.MatchString(entry.loc) {
+				continue
+			}
+			seen[entry.loc] = true
+			urls = append(urls, entry.loc)
+		}
+
+		for _, child := range children {
+			if !inWindow(child.date, src.Since, time.Time{}) {
+				continue // nothing in this sitemap changed since the window opened
+			}
+			if depth >= maxSitemapDepth {
+				logger.Warn("sitemap index nested too deeply, skipping", "url", child.loc)
+				continue
+			}
+			if err := expand(child.loc, depth+1); err != nil {
+				// One broken child sitemap shouldn't lose the rest of the index
+				logger.Warn("failed to expand child sitemap", "url", child.loc, "error", err)
+			}
+		}
+		return nil
+	}
+
+	if err := expand(src.URL, 0); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// fetch downloads and parses one sitemap or feed, returning its page entries
+// and, for sitemap indexes, the child sitemaps.
+func (e *SeedExpander) fetch(ctx context.Context, docURL string) (entries, children []seedEntry, err error) {
+	logger.Debug("fetching seed source", "url", docURL)
+
+	content, err := e.fetcher.Fetch(ctx, docURL, fetcher.Options{
+		UserAgent:           e.userAgent,
+		MaxBodyBytes:        maxSeedDocumentSize,
+		AllowedContentTypes: []string{"*/*"}, // whatever type it is served as
+	})
+	if err != nil {
+		if content.StatusCode >= 400 {
+			return nil, nil, fmt.Errorf("%s returned status %d", docURL, content.StatusCode)
+		}
+		return nil, nil, err
+	}
+
+	// Gzipped sitemaps (.xml.gz) are usually served as-is rather than with
+	// Content-Encoding, so sniff the gzip magic number.
+	body := []byte(content.HTML)
+	var r io.Reader = bytes.NewReader(body)
+	decoded := content.Charset != "" // The fetcher converted the body to UTF-8
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid gzip: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+		decoded = false
+	}
+
+	base, _ := url.Parse(content.PageURL())
+	entries, children, err = parseSeedDocument(io.LimitReader(r, maxSeedDocumentSize), base, decoded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", docURL, err)
+	}
+	return entries, children, nil
+}
+
+// seedDocument matches the elements of sitemaps, sitemap indexes, RSS 2.0,
+// RSS 1.0 (RDF) and Atom. Namespaces are ignored.
+type seedDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapURL `xml:"url"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+	Channel  struct {
+		Items []feedItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []feedItem  `xml:"item"` // RSS 1.0 items are siblings of the channel
+	Entries []atomEntry `xml:"entry"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+type feedItem struct {
+	Links   []string `xml:"link"`
+	GUID    string   `xml:"guid"`
+	PubDate string   `xml:"pubDate"`
+	Date    string   `xml:"date"` // dc:date
+}
+
+type atomEntry struct {
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Updated   string `xml:"updated"`
+	Published string `xml:"published"`
+}
+
+// parseSeedDocument parses a sitemap or feed, resolving relative links against
+// base. If decoded is set, r is already UTF-8 whatever encoding the document
+// declares; otherwise it is decoded from the declared encoding.
+func parseSeedDocument(r io.Reader, base *url.URL, decoded bool) (entries, children []seedEntry, err error) {
+	var doc seedDocument
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		if decoded {
+			return input, nil
+		}
+		return charset.NewReaderLabel(label, input)
+	}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, nil, err
+	}
+
+	add := func(list *[]seedEntry, loc, date string) {
+		loc = strings.TrimSpace(loc)
+		if loc == "" {
+			return
+		}
+		if ref, err := url.Parse(loc); err == nil && base != nil {
+			loc = base.ResolveReference(ref).String()
+		}
+		*list = append(*list, seedEntry{loc: loc, date: strings.TrimSpace(date)})
+	}
+
+	switch doc.XMLName.Local {
+	case "urlset":
+		for _, u := range doc.URLs {
+			add(&entries, u.Loc, u.LastMod)
+		}
+	case "sitemapindex":
+		for _, s := range doc.Sitemaps {
+			add(&children, s.Loc, s.LastMod)
+		}
+	case "rss", "RDF":
+		for _, item := range append(doc.Channel.Items, doc.Items...) {
+			add(&entries, item.link(), coalesce(item.PubDate, item.Date))
+		}
+	case "feed":
+		for _, entry := range doc.Entries {
+			add(&entries, entry.link(), coalesce(entry.Updated, entry.Published))
+		}
+	default:
+		return nil, nil, fmt.Errorf("unrecognised root element <%s> (want urlset, sitemapindex, rss or feed)", doc.XMLName.Local)
+	}
+	return entries, children, nil
+}
+
+// link returns the item's link, falling back to a permalink GUID.
+func (i feedItem) link() string {
+	for _, l := range i.Links {
+		if l = strings.TrimSpace(l); l != "" {
+			return l
+		}
+	}
+	if strings.HasPrefix(i.GUID, "http") {
+		return i.GUID
+	}
+	return ""
+}
+
+// link returns the entry's alternate (page) link.
+func (e atomEntry) link() string {
+	for _, l := range e.Links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+// seedDateLayouts are the date formats used by sitemaps (W3C datetime) and
+// feeds (RFC 822/1123 and RFC 3339).
+var seedDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+}
+
+// parseSeedDate parses a sitemap or feed date.
+func parseSeedDate(s string) (time.Time, bool) {
+	for _, layout := range seedDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// inWindow reports whether date falls within [since, until]. Missing or
+// unparseable dates are treated as in the window.
+func inWindow(date string, since, until time.Time) bool {
+	if date == "" || (since.IsZero() && until.IsZero()) {
+		return true
+	}
+	t, ok := parseSeedDate(date)
+	if !ok {
+		logger.Debug("unparseable seed date, keeping entry", "date", date)
+		return true
+	}
+	return (since.IsZero() || !t.Before(since)) && (until.IsZero() || !t.After(until))
+}
+
+// coalesce returns the first non-empty string.
+func coalesce(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
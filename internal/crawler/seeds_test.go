@@ -0,0 +1,274 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSitemapIndex = `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>/sitemap-products.xml.gz</loc><lastmod>2025-03-01</lastmod></sitemap>
+  <sitemap><loc>/sitemap-archive.xml</loc><lastmod>2019-01-01</lastmod></sitemap>
+</sitemapindex>`
+
+const testSitemapProducts = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://shop.example.com/products/widget</loc><lastmod>2025-02-20T10:00:00+00:00</lastmod></url>
+  <url><loc>https://shop.example.com/products/gadget</loc><lastmod>2024-06-01</lastmod></url>
+  <url><loc>https://shop.example.com/about</loc></url>
+  <url><loc>https://shop.example.com/products/widget</loc></url>
+</urlset>`
+
+const testSitemapArchive = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://shop.example.com/products/old</loc><lastmod>2018-05-05</lastmod></url>
+</urlset>`
+
+const testRSS = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <title>News</title>
+    <link>https://news.example.com/</link>
+    <atom:link href="https://news.example.com/feed" rel="self"/>
+    <item><title>New</title><link>https://news.example.com/new</link><pubDate>Mon, 03 Mar 2025 09:00:00 GMT</pubDate></item>
+    <item><title>Old</title><link>https://news.example.com/old</link><pubDate>Fri, 01 Dec 2023 09:00:00 +0000</pubDate></item>
+    <item><title>Permalink</title><guid>https://news.example.com/guid</guid></item>
+  </channel>
+</rss>`
+
+const testAtom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Blog</title>
+  <link href="https://blog.example.com/" rel="alternate"/>
+  <entry>
+    <link href="https://blog.example.com/feed.xml" rel="self"/>
+    <link href="https://blog.example.com/posts/1"/>
+    <updated>2025-01-10T12:00:00Z</updated>
+  </entry>
+  <entry>
+    <link rel="alternate" href="/posts/2"/>
+    <published>2025-02-10T12:00:00Z</published>
+  </entry>
+</feed>`
+
+// testLatin1RSS declares ISO-8859-1 and is encoded in it ("\xe9" is é).
+const testLatin1RSS = "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" +
+	"<rss version=\"2.0\"><channel><title>Caf\xe9</title>" +
+	"<item><title>Cr\xe8me</title><link>https://news.example.com/caf\xe9</link></item>" +
+	"</channel></rss>"
+
+func gzipBytes(s string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(s))
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func newSeedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gz := gzipBytes(testSitemapProducts)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			_, _ = w.Write([]byte(testSitemapIndex))
+		case "/sitemap-products.xml.gz":
+			w.Header().Set("Content-Type", "application/gzip")
+			_, _ = w.Write(gz)
+		case "/sitemap-products":
+			// Gzipped but served as XML with no Content-Encoding
+			w.Header().Set("Content-Type", "application/xml")
+			_, _ = w.Write(gz)
+		case "/sitemap-archive.xml":
+			_, _ = w.Write([]byte(testSitemapArchive))
+		case "/rss.xml":
+			_, _ = w.Write([]byte(testRSS))
+		case "/atom.xml":
+			_, _ = w.Write([]byte(testAtom))
+		case "/latin1.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			_, _ = w.Write([]byte(testLatin1RSS))
+		case "/latin1.xml.gz":
+			w.Header().Set("Content-Type", "application/gzip")
+			_, _ = w.Write(gzipBytes(testLatin1RSS))
+		case "/page.html":
+			_, _ = w.Write([]byte("<html><body>not a feed</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func date(s string) time.Time {
+	t, _ := time.Parse(time.DateOnly, s)
+	return t
+}
+
+// --- SeedExpander Tests ---
+
+func TestSeedExpander_Expand(t *testing.T) {
+	srv := newSeedServer(t)
+	e := NewSeedExpander("refynebot", nil)
+
+	tests := []struct {
+		name string
+		src  SeedSource
+		want []string
+	}{
+		{
+			name: "sitemap index with gzipped child",
+			src:  SeedSource{URL: srv.URL + "/sitemap.xml"},
+			want: []string{
+				"https://shop.example.com/products/widget",
+				"https://shop.example.com/products/gadget",
+				"https://shop.example.com/about",
+				"https://shop.example.com/products/old",
+			},
+		},
+		{
+			name: "lastmod window skips stale child sitemaps",
+			src:  SeedSource{URL: srv.URL + "/sitemap.xml", Since: date("2025-01-01")},
+			want: []string{
+				"https://shop.example.com/products/widget",
+				"https://shop.example.com/about", // undated entries are kept
+			},
+		},
+		{
+			name: "url pattern",
+			src:  SeedSource{URL: srv.URL + "/sitemap.xml", Pattern: `/products/`},
+			want: []string{
+				"https://shop.example.com/products/widget",
+				"https://shop.example.com/products/gadget",
+				"https://shop.example.com/products/old",
+			},
+		},
+		{
+			name: "rss pubDate window",
+			src:  SeedSource{URL: srv.URL + "/rss.xml", Since: date("2025-01-01"), Until: date("2025-12-31")},
+			want: []string{
+				"https://news.example.com/new",
+				"https://news.example.com/guid",
+			},
+		},
+		{
+			name: "atom updated window",
+			src:  SeedSource{URL: srv.URL + "/atom.xml", Until: date("2025-01-31")},
+			want: []string{
+				"https://blog.example.com/posts/1",
+			},
+		},
+		{
+			name: "gzipped sitemap served as XML",
+			src:  SeedSource{URL: srv.URL + "/sitemap-products"},
+			want: []string{
+				"https://shop.example.com/products/widget",
+				"https://shop.example.com/products/gadget",
+				"https://shop.example.com/about",
+			},
+		},
+		{
+			name: "feed declaring ISO-8859-1",
+			src:  SeedSource{URL: srv.URL + "/latin1.xml"},
+			want: []string{"https://news.example.com/caf%C3%A9"},
+		},
+		{
+			name: "gzipped feed declaring ISO-8859-1",
+			src:  SeedSource{URL: srv.URL + "/latin1.xml.gz"},
+			want: []string{"https://news.example.com/caf%C3%A9"},
+		},
+		{
+			name: "atom resolves relative links",
+			src:  SeedSource{URL: srv.URL + "/atom.xml"},
+			want: []string{
+				"https://blog.example.com/posts/1",
+				srv.URL + "/posts/2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.Expand(context.Background(), tt.src)
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Expand() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestSeedExpander_UsesFetcher(t *testing.T) {
+	f := &mapFetcher{bodies: map[string]string{
+		"https://shop.example.com/sitemap.xml": testSitemapArchive,
+	}}
+	e := NewSeedExpander("refynebot", f)
+
+	urls, err := e.Expand(context.Background(), SeedSource{URL: "https://shop.example.com/sitemap.xml"})
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if len(urls) != 1 || urls[0] != "https://shop.example.com/products/old" {
+		t.Errorf("Expand() = %v, want the archived sitemap's URL", urls)
+	}
+	if len(f.userAgents) != 1 || f.userAgents[0] != "refynebot" {
+		t.Errorf("fetches = %v, want one with the expander's user agent", f.userAgents)
+	}
+
+	if _, err := e.Expand(context.Background(), SeedSource{URL: "https://shop.example.com/missing.xml"}); err == nil {
+		t.Error("Expand() of a source the fetcher can't serve should fail")
+	}
+}
+
+func TestSeedExpander_Errors(t *testing.T) {
+	srv := newSeedServer(t)
+	e := NewSeedExpander("refynebot", nil)
+
+	tests := []struct {
+		name string
+		src  SeedSource
+	}{
+		{"not found", SeedSource{URL: srv.URL + "/missing.xml"}},
+		{"not a sitemap", SeedSource{URL: srv.URL + "/page.html"}},
+		{"bad pattern", SeedSource{URL: srv.URL + "/sitemap.xml", Pattern: "("}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := e.Expand(context.Background(), tt.src); err == nil {
+				t.Error("Expand() should return an error")
+			}
+		})
+	}
+}
+
+func TestParseSeedDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"2025-02-20", "2025-02-20T00:00:00Z"},
+		{"2025-02-20T10:30:00+01:00", "2025-02-20T09:30:00Z"},
+		{"2025-02-20T10:30+01:00", "2025-02-20T09:30:00Z"},
+		{"Mon, 03 Mar 2025 09:00:00 GMT", "2025-03-03T09:00:00Z"},
+		{"Mon, 3 Mar 2025 09:00:00 +0000", "2025-03-03T09:00:00Z"},
+	}
+	for _, tt := range tests {
+		got, ok := parseSeedDate(tt.in)
+		if !ok || got.UTC().Format(time.RFC3339) != tt.want {
+			t.Errorf("parseSeedDate(%q) = %v, %v; want %s", tt.in, got.UTC(), ok, tt.want)
+		}
+	}
+	if _, ok := parseSeedDate("last tuesday"); ok {
+		t.Error("parseSeedDate should reject free text")
+	}
+}
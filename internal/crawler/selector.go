@@ -54,8 +54,28 @@ func normalizeCSSSelector(selector string) string {
 	return strings.Join(parts, ", ")
 }
 
+// Link is a link found in a page.
+type Link struct {
+	URL  string // Absolute URL, without fragment
+	Text string // Anchor text with whitespace collapsed (or the link's title if it has no text)
+}
+
 // ExtractLinks extracts matching links from HTML content.
 func (ls *LinkSelector) ExtractLinks(html string, baseURL string) ([]string, error) {
+	anchors, err := ls.ExtractAnchors(html, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]string, len(anchors))
+	for i, a := range anchors {
+		links[i] = a.URL
+	}
+	return links, nil
+}
+
+// ExtractAnchors is ExtractLinks that also returns each link's anchor text.
+// A URL linked more than once is returned once, with the text of its first link.
+func (ls *LinkSelector) ExtractAnchors(html string, baseURL string) ([]Link, error) {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
 		return nil, err
@@ -66,7 +86,7 @@ func (ls *LinkSelector) ExtractLinks(html string, baseURL string) ([]string, err
 		return nil, err
 	}
 
-	var links []string
+	var links []Link
 	seen := make(map[string]bool)
 
 	selector := ls.CSSSelector
@@ -111,7 +131,11 @@ func (ls *LinkSelector) ExtractLinks(html string, baseURL string) ([]string, err
 		}
 		seen[fullURL] = true
 
-		links = append(links, fullURL)
+		text := strings.Join(strings.Fields(s.Text()), " ")
+		if text == "" {
+			text = strings.TrimSpace(s.AttrOr("title", ""))
+		}
+		links = append(links, Link{URL: fullURL, Text: text})
 	})
 
 	return links, nil
@@ -171,3 +195,27 @@ func (ps *PaginationSelector) FindNextPage(html string, baseURL string) (string,
 
 	return nextURL, nextURL != ""
 }
+
+// FindCanonical returns the absolute URL declared by the page's
+// <link rel="canonical"> element, if it has one.
+func FindCanonical(html string, baseURL string) (string, bool) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return "", false
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", false
+	}
+
+	href := strings.TrimSpace(doc.Find(`link[rel~="canonical" i][href]`).First().AttrOr("href", ""))
+	if href == "" {
+		return "", false
+	}
+	canonical, err := base.Parse(href)
+	if err != nil || (canonical.Scheme != "http" && canonical.Scheme != "https") {
+		return "", false
+	}
+	return canonical.String(), true
+}
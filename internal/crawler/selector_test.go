@@ -408,3 +408,50 @@ func TestPaginationSelector_FindNextPage_InvalidBaseURL(t *testing.T) {
 		t.Error("expected not to find next page with invalid base URL")
 	}
 }
+
+func TestLinkSelector_ExtractAnchors(t *testing.T) {
+	html := `<a href="/a">  Floor
+		plan </a><a href="/b" title="Photos"><img src="x.jpg"></a><a href="/a">Again</a>`
+
+	ls, _ := NewLinkSelector("", "")
+	links, err := ls.ExtractAnchors(html, "https://example.com/")
+	if err != nil {
+		t.Fatalf("ExtractAnchors() error = %v", err)
+	}
+
+	want := []Link{{URL: "https://example.com/a", Text: "Floor plan"}, {URL: "https://example.com/b", Text: "Photos"}}
+	if len(links) != len(want) {
+		t.Fatalf("expected %d links, got %d: %v", len(want), len(links), links)
+	}
+	for i := range want {
+		if links[i] != want[i] {
+			t.Errorf("links[%d] = %+v, want %+v", i, links[i], want[i])
+		}
+	}
+}
+
+// --- FindCanonical Tests ---
+
+func TestFindCanonical(t *testing.T) {
+	tests := []struct {
+		name      string
+		html      string
+		want      string
+		wantFound bool
+	}{
+		{"relative", `<head><link rel="canonical" href="/product/1"></head>`, "https://example.com/product/1", true},
+		{"absolute", `<head><link rel="canonical" href="https://shop.example.com/p/1"></head>`, "https://shop.example.com/p/1", true},
+		{"case and multiple rels", `<head><LINK REL="alternate Canonical" HREF="/p/2"></head>`, "https://example.com/p/2", true},
+		{"none", `<head><link rel="alternate" href="/fr/p/1"></head>`, "", false},
+		{"empty href", `<head><link rel="canonical" href=" "></head>`, "", false},
+		{"not http", `<head><link rel="canonical" href="javascript:void(0)"></head>`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := FindCanonical(tt.html, "https://example.com/items?id=1")
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("FindCanonical() = %q, %v; want %q, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package crawler
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// shingleSize is the number of consecutive words hashed together by SimHash,
+// so word order counts and not just the vocabulary.
+const shingleSize = 3
+
+// minFingerprintWords is the fewest words a page must have to be
+// fingerprinted; shorter texts are too alike to compare meaningfully.
+const minFingerprintWords = 20
+
+// SimHash returns a 64-bit SimHash fingerprint of text. Texts that share most
+// of their word sequences have fingerprints that differ in few bits (see
+// Similarity). Case and punctuation are ignored.
+func SimHash(text string) uint64 {
+	return simHashWords(fingerprintWords(text))
+}
+
+// fingerprintWords splits text into lowercase words.
+func fingerprintWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func simHashWords(words []string) uint64 {
+	if len(words) == 0 {
+		return 0
+	}
+	var weights [64]int
+	for i := range max(len(words)-shingleSize+1, 1) {
+		h := fnv.New64a()
+		for _, w := range words[i:min(i+shingleSize, len(words))] {
+			_, _ = h.Write([]byte(w))
+			_, _ = h.Write([]byte{0})
+		}
+		sum := h.Sum64()
+		for bit := range 64 {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	var fp uint64
+	for bit, w := range weights {
+		if w > 0 {
+			fp |= 1 << bit
+		}
+	}
+	return fp
+}
+
+// Similarity returns how alike two SimHash fingerprints are, from 0 to 1
+// (identical).
+func Similarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/64
+}
+
+// FingerprintIndex remembers the fingerprints of a crawl's extracted pages
+// so near-duplicates of them can be skipped. It is safe for concurrent use.
+type FingerprintIndex struct {
+	maxDistance int // Most bits a near-duplicate's fingerprint may differ in
+
+	mu      sync.Mutex
+	entries []fingerprintEntry
+}
+
+type fingerprintEntry struct {
+	url  string
+	hash uint64
+}
+
+// NewFingerprintIndex creates an index that treats pages whose fingerprints
+// are at least similarity (0-1) alike as duplicates.
+func NewFingerprintIndex(similarity float64) *FingerprintIndex {
+	distance := math.Floor((1-min(max(similarity, 0), 1))*64 + 1e-9)
+	return &FingerprintIndex{maxDistance: int(distance)}
+}
+
+// Match looks for a page in the index that text nearly duplicates. If there
+// is one, it returns that page's URL and true. Text too short to fingerprint
+// is never a duplicate.
+func (ix *FingerprintIndex) Match(text string) (string, bool) {
+	words := fingerprintWords(text)
+	if len(words) < minFingerprintWords {
+		return "", false
+	}
+	hash := simHashWords(words)
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	best, bestDistance := "", ix.maxDistance+1
+	for _, e := range ix.entries {
+		if d := bits.OnesCount64(e.hash ^ hash); d < bestDistance {
+			best, bestDistance = e.url, d
+		}
+	}
+	return best, best != ""
+}
+
+// Add adds url with text's fingerprint, so later pages can be matched
+// against it. The crawler adds pages once they are extracted, so a page whose
+// extraction failed doesn't cause its duplicates to be skipped. Text too short
+// to fingerprint is not added.
+func (ix *FingerprintIndex) Add(url, text string) {
+	words := fingerprintWords(text)
+	if len(words) < minFingerprintWords {
+		return
+	}
+	hash := simHashWords(words)
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.entries = append(ix.entries, fingerprintEntry{url: url, hash: hash})
+}
+
+// Len returns the number of pages in the index.
+func (ix *FingerprintIndex) Len() int {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return len(ix.entries)
+}
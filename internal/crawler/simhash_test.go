@@ -0,0 +1,85 @@
+package crawler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// listingText returns a property listing of about 200 words whose details
+// depend on id, as a stand-in for a cleaned detail page.
+func listingText(id int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Listing %d\n\n", id)
+	for i := range 40 {
+		fmt.Fprintf(&b, "Room %d of property %d measures %d square metres with feature %d. ", i, id, (id*7+i*13)%90+10, (id*31+i)%97)
+	}
+	return b.String()
+}
+
+// --- SimHash Tests ---
+
+func TestSimHash_Similarity(t *testing.T) {
+	page := listingText(1)
+	variant := "Print version. " + strings.Replace(page, "Room 12 of", "Bedroom 12 of", 1) + " Share this page."
+
+	if got := Similarity(SimHash(page), SimHash(page)); got != 1 {
+		t.Errorf("Similarity(identical) = %v, want 1", got)
+	}
+	if got := Similarity(SimHash(page), SimHash(strings.ToUpper(page))); got != 1 {
+		t.Errorf("Similarity(case change) = %v, want 1", got)
+	}
+	if got := Similarity(SimHash(page), SimHash(variant)); got < 0.9 {
+		t.Errorf("Similarity(variant) = %v, want at least 0.9", got)
+	}
+	if got := Similarity(SimHash(page), SimHash(listingText(2))); got > 0.8 {
+		t.Errorf("Similarity(different listing) = %v, want at most 0.8", got)
+	}
+}
+
+func TestFingerprintIndex_MatchAdd(t *testing.T) {
+	ix := NewFingerprintIndex(0.9)
+	page := listingText(1)
+
+	if _, dup := ix.Match(page); dup {
+		t.Fatal("Match() on an empty index should find no duplicate")
+	}
+	ix.Add("https://example.com/listing/1", page)
+	if original, dup := ix.Match(page + " AMP"); !dup || original != "https://example.com/listing/1" {
+		t.Errorf("Match(variant) = %q, %v; want a duplicate of the first page", original, dup)
+	}
+	if _, dup := ix.Match(listingText(2)); dup {
+		t.Error("a different listing should not be a duplicate")
+	}
+	ix.Add("https://example.com/listing/2", listingText(2))
+	if ix.Len() != 2 {
+		t.Errorf("Len() = %d, want the 2 distinct pages", ix.Len())
+	}
+
+	// Short texts are too alike to compare, so are never added or duplicates
+	ix.Add("https://example.com/a", "Not found")
+	if _, dup := ix.Match("Not found"); dup {
+		t.Error("short text should not be a duplicate")
+	}
+	if ix.Len() != 2 {
+		t.Errorf("Len() = %d after adding short text, want 2", ix.Len())
+	}
+}
+
+func TestNewFingerprintIndex_Threshold(t *testing.T) {
+	tests := []struct {
+		similarity float64
+		want       int
+	}{
+		{1, 0},
+		{0.95, 3},
+		{0.9, 6},
+		{1.5, 0},
+		{-1, 64},
+	}
+	for _, tt := range tests {
+		if got := NewFingerprintIndex(tt.similarity).maxDistance; got != tt.want {
+			t.Errorf("NewFingerprintIndex(%v) max distance = %d, want %d", tt.similarity, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package crawler
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+)
+
+// DefaultCheckpointInterval is how often crawl state is saved when
+// Config.CheckpointInterval is not set.
+const DefaultCheckpointInterval = 30 * time.Second
+
+// stateFileName is the file crawl state is saved to in a state directory.
+const stateFileName = "crawl-state.json"
+
+// stateVersion is the version of the State format written by this package.
+const stateVersion = 1
+
+// Outcome statuses recorded for each finished URL.
+const (
+	OutcomeExtracted = "extracted" // Data was extracted
+	OutcomeFetched   = "fetched"   // Fetched for its links only
+	OutcomeUnchanged = "unchanged" // Unchanged since the last incremental crawl; not extracted again
+	OutcomeFailed    = "failed"    // Fetch or extraction failed
+	OutcomeSkipped   = "skipped"   // Deliberately not processed (robots.txt, duplicate redirect, ...)
+)
+
+// State is a snapshot of a crawl's progress: everything needed to continue
+// it where it stopped without refetching or re-extracting finished pages.
+type State struct {
+	Version         int                   `json:"version"`
+	SavedAt         time.Time             `json:"saved_at"`
+	Pending         []PendingURL          `json:"pending"`            // Queued or in flight when saved, in crawl order
+	Visited         []string              `json:"visited"`            // Every URL (or request) queued so far, for deduplication
+	URLsProcessed   int                   `json:"urls_processed"`     // Counted against MaxURLs
+	PaginationPages int                   `json:"pagination_pages"`   // Counted against MaxPages
+	Outcomes        map[string]URLOutcome `json:"outcomes,omitempty"` // Reported or finished URLs, by URL (or request) dedup key
+}
+
+// PendingURL is a URL waiting to be crawled.
+type PendingURL struct {
+	URL        string           `json:"url"`
+	Depth      int              `json:"depth"`
+	Request    *fetcher.Request `json:"request,omitempty"`     // Set unless the URL is fetched with a plain GET
+	AnchorText string           `json:"anchor_text,omitempty"` // Text of the link the URL was found through
+}
+
+// URLOutcome records how a URL's processing ended.
+type URLOutcome struct {
+	Status       string    `json:"status"` // One of the Outcome* constants
+	Depth        int       `json:"depth"`
+	Error        string    `json:"error,omitempty"`
+	FinishedAt   time.Time `json:"finished_at"`
+	CanonicalURL string    `json:"canonical_url,omitempty"` // URL the page declared canonical, if it isn't its own
+
+	interrupted bool // Failed because the crawl was cancelled; crawled again on resume
+}
+
+// StateStore saves crawl state to a directory so the crawl can be resumed.
+type StateStore struct {
+	dir string
+}
+
+// NewStateStore creates a state store in dir, creating it if needed.
+func NewStateStore(dir string) (*StateStore, error) {
+	if dir == "" {
+		return nil, errors.New("state directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return &StateStore{dir: dir}, nil
+}
+
+// Path returns the path of the state file.
+func (s *StateStore) Path() string {
+	return filepath.Join(s.dir, stateFileName)
+}
+
+// Load reads the saved state. It returns false if none has been saved.
+func (s *StateStore) Load() (*State, bool, error) {
+	data, err := os.ReadFile(s.Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, false, fmt.Errorf("corrupt crawl state %s: %w", s.Path(), err)
+	}
+	if state.Version != stateVersion {
+		return nil, false, fmt.Errorf("unsupported crawl state version %d in %s", state.Version, s.Path())
+	}
+	return &state, true, nil
+}
+
+// Save writes the state. The write is atomic: an interrupted save leaves the
+// previous state in place.
+func (s *StateStore) Save(state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path())
+}
+
+// checkpointer tracks a crawl's in-flight URLs and outcomes and periodically
+// saves them, with the queue, to a StateStore.
+type checkpointer struct {
+	store    *StateStore
+	interval time.Duration
+	lastSave time.Time
+
+	mu       sync.Mutex
+	inFlight map[string]FrontierItem // Dispatched but not finished, by dedup key
+	outcomes map[string]URLOutcome   // Reported or finished, by dedup key
+}
+
+// newCheckpointer creates a checkpointer, continuing the outcomes of a
+// resumed crawl's state (which may be nil).
+func newCheckpointer(store *StateStore, interval time.Duration, resumed *State) *checkpointer {
+	cp := &checkpointer{
+		store:    store,
+		interval: cmp.Or(interval, DefaultCheckpointInterval),
+		lastSave: time.Now(),
+		inFlight: make(map[string]FrontierItem),
+		outcomes: make(map[string]URLOutcome),
+	}
+	if resumed != nil {
+		maps.Copy(cp.outcomes, resumed.Outcomes)
+	}
+	return cp
+}
+
+// forward passes each result from in on to out, recording the item's own
+// result as its outcome once it has been passed on. Results reported while
+// ctx is cancelled are marked interrupted. If the item's result was already
+// reported before the crawl was resumed, it is dropped rather than reported
+// again; results for other URLs (links disallowed by robots.txt) always pass.
+func (cp *checkpointer) forward(ctx context.Context, item FrontierItem, reported bool, in <-chan Result, out chan<- Result) {
+	for r := range in {
+		if r.URL != item.URL {
+			out <- r
+			continue
+		}
+		if reported {
+			continue
+		}
+		outcome := URLOutcome{Status: OutcomeExtracted, Depth: r.Depth, CanonicalURL: r.CanonicalURL}
+		switch {
+		case r.Unchanged:
+			outcome.Status = OutcomeUnchanged
+		case r.Skipped:
+			outcome.Status = OutcomeSkipped
+		case r.Error != nil:
+			outcome.Status = OutcomeFailed
+			outcome.interrupted = ctx.Err() != nil
+		}
+		if r.Error != nil {
+			outcome.Error = r.Error.Error()
+		}
+		out <- r
+		outcome.FinishedAt = time.Now()
+		cp.mu.Lock()
+		cp.outcomes[item.key()] = outcome
+		cp.mu.Unlock()
+	}
+}
+
+// track runs process, recording the results it reports on the way to results,
+// then records item as finished. The results are recorded before item is
+// finished, so an interrupted item can be told from a completed one.
+//
+// An item whose result was reported before the crawl was resumed is only
+// fetched again for its links; process is told so through linksOnly. process
+// returns false if the crawl was cancelled before it queued all the item's
+// links, which keeps the item in flight.
+func (cp *checkpointer) track(ctx context.Context, item FrontierItem, results chan<- Result, process func(results chan<- Result, linksOnly bool) bool) {
+	reported := cp.reported(item)
+	tracked := make(chan Result)
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		cp.forward(ctx, item, reported, tracked, results)
+	}()
+	linksQueued := process(tracked, reported)
+	close(tracked)
+	<-forwarded
+	cp.finish(item, linksQueued)
+}
+
+// reported reports whether item's result has already been reported, as it
+// has for an item saved in flight after reporting it.
+func (cp *checkpointer) reported(item FrontierItem) bool {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	outcome, ok := cp.outcomes[item.key()]
+	return ok && !outcome.interrupted
+}
+
+// start records that item has been dispatched.
+func (cp *checkpointer) start(item FrontierItem) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.inFlight[item.key()] = item
+}
+
+// finish records that item has been processed. An item interrupted by the
+// crawl being cancelled stays in flight, so it is saved as pending. So does
+// one cancelled before it queued all its links, keeping any outcome it
+// reported, so on resume it is refetched for its links only.
+func (cp *checkpointer) finish(item FrontierItem, linksQueued bool) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	key := item.key()
+	outcome, ok := cp.outcomes[key]
+	if ok && outcome.interrupted {
+		delete(cp.outcomes, key)
+		return
+	}
+	if !linksQueued {
+		return
+	}
+	if !ok {
+		cp.outcomes[key] = URLOutcome{Status: OutcomeFetched, Depth: item.Depth, FinishedAt: time.Now()}
+	}
+	delete(cp.inFlight, key)
+}
+
+// due reports whether a periodic checkpoint is due.
+func (cp *checkpointer) due() bool {
+	return time.Since(cp.lastSave) >= cp.interval
+}
+
+// save writes the crawl's state. URLs still in flight are saved as pending,
+// ahead of the queue, and are not counted as processed. One whose result was
+// already reported keeps its outcome, so on resume it is fetched again only
+// for the links it may not have queued, not extracted and reported twice.
+//
+// The in-flight URLs are snapshotted before the queue. A URL that finishes in
+// between is then saved as pending, so its links are followed again on
+// resume, rather than as finished without the links it queued.
+func (cp *checkpointer) save(queue *URLQueue, urlsProcessed, paginationPages int) {
+	cp.mu.Lock()
+	inFlight := make([]FrontierItem, 0, len(cp.inFlight))
+	for _, item := range cp.inFlight {
+		inFlight = append(inFlight, item)
+		urlsProcessed--
+		if item.Depth == 0 {
+			paginationPages--
+		}
+	}
+	outcomes := make(map[string]URLOutcome, len(cp.outcomes))
+	for key, outcome := range cp.outcomes {
+		if !outcome.interrupted {
+			outcomes[key] = outcome
+		}
+	}
+	cp.mu.Unlock()
+
+	items, visited := queue.snapshot()
+
+	slices.SortFunc(inFlight, func(a, b FrontierItem) int {
+		return strings.Compare(a.key(), b.key())
+	})
+	state := &State{
+		Version:         stateVersion,
+		SavedAt:         time.Now(),
+		Pending:         make([]PendingURL, 0, len(inFlight)+len(items)),
+		Visited:         visited,
+		URLsProcessed:   max(urlsProcessed, 0),
+		PaginationPages: max(paginationPages, 0),
+		Outcomes:        outcomes,
+	}
+	for _, item := range slices.Concat(inFlight, items) {
+		state.Pending = append(state.Pending, PendingURL{URL: item.URL, Depth: item.Depth, Request: item.Request, AnchorText: item.AnchorText})
+	}
+
+	cp.lastSave = time.Now()
+	if err := cp.store.Save(state); err != nil {
+		logger.Warn("failed to save crawl state", "path", cp.store.Path(), "error", err)
+		return
+	}
+	logger.Debug("crawl state saved", "path", cp.store.Path(), "pending", len(state.Pending), "visited", len(visited))
+}
+
+// restoreQueue fills queue with a saved state's pending URLs and visited set.
+func restoreQueue(queue *URLQueue, state *State) {
+	items := make([]FrontierItem, 0, len(state.Pending))
+	for _, p := range state.Pending {
+		items = append(items, FrontierItem{URL: p.URL, Depth: p.Depth, Request: p.Request, AnchorText: p.AnchorText})
+	}
+	queue.restore(items, state.Visited)
+}
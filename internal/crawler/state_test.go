@@ -0,0 +1,266 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+)
+
+// --- Crawl State Tests ---
+
+func TestStateStore_SaveLoad(t *testing.T) {
+	store, err := NewStateStore(filepath.Join(t.TempDir(), "state"))
+	if err != nil {
+		t.Fatalf("NewStateStore() error = %v", err)
+	}
+
+	if _, found, err := store.Load(); found || err != nil {
+		t.Fatalf("Load() on an empty store = found %v, error %v; want nothing", found, err)
+	}
+
+	want := &State{
+		Version: stateVersion,
+		Pending: []PendingURL{
+			{URL: "https://example.com/a", Depth: 1},
+			{URL: "https://example.com/search", Depth: 0, Request: &fetcher.Request{URL: "https://example.com/search", Form: map[string]string{"q": "chairs"}}},
+		},
+		Visited:         []string{"https://example.com", "https://example.com/a"},
+		URLsProcessed:   7,
+		PaginationPages: 2,
+		Outcomes:        map[string]URLOutcome{"https://example.com": {Status: OutcomeFetched}},
+	}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, found, err := store.Load()
+	if err != nil || !found {
+		t.Fatalf("Load() = found %v, error %v", found, err)
+	}
+	if len(got.Pending) != 2 || got.Pending[1].Request == nil || got.Pending[1].Request.Form["q"] != "chairs" {
+		t.Errorf("Pending = %+v, want both URLs with the search request", got.Pending)
+	}
+	if got.URLsProcessed != 7 || got.PaginationPages != 2 || len(got.Visited) != 2 {
+		t.Errorf("counters = %d/%d, visited = %d; want 7/2 and 2", got.URLsProcessed, got.PaginationPages, len(got.Visited))
+	}
+	if got.Outcomes["https://example.com"].Status != OutcomeFetched {
+		t.Errorf("Outcomes = %v, want the seed fetched", got.Outcomes)
+	}
+}
+
+func TestStateStore_Load_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewStateStore(dir)
+
+	if err := os.WriteFile(store.Path(), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Load(); err == nil {
+		t.Error("Load() should fail on a corrupt state file")
+	}
+
+	if err := os.WriteFile(store.Path(), []byte(`{"version": 99}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Load(); err == nil {
+		t.Error("Load() should fail on an unknown state version")
+	}
+}
+
+func TestCheckpointer_SaveAndRestore(t *testing.T) {
+	store, _ := NewStateStore(t.TempDir())
+	cp := newCheckpointer(store, 0, nil)
+
+	queue := NewURLQueue()
+	queue.Add("https://example.com/list", 0)
+	queue.Add("https://example.com/item/1", 1)
+	queue.Add("https://example.com/item/2", 1)
+	queue.Add("https://example.com/item/3", 1)
+	queue.AddRequest(fetcher.Request{URL: "https://example.com/search", Body: `{"q": "chairs"}`}, 0)
+
+	// Dispatch the listing, which finishes, and two items, one of which is
+	// interrupted when the crawl is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan Result, 10)
+	pop := func() FrontierItem {
+		url, depth, req, _ := queue.PopRequestFunc(func(string, int) bool { return true })
+		item := FrontierItem{URL: url, Depth: depth, Request: req}
+		cp.start(item)
+		return item
+	}
+	list, item1, item2 := pop(), pop(), pop()
+	cp.track(ctx, list, results, func(chan<- Result, bool) bool { return true }) // fetched for links only, no result
+	cp.track(ctx, item1, results, func(out chan<- Result, _ bool) bool {
+		out <- Result{URL: item1.URL, Depth: 1, Data: map[string]any{"ok": true}}
+		return true
+	})
+	cancel()
+	cp.track(ctx, item2, results, func(out chan<- Result, _ bool) bool {
+		out <- Result{URL: item2.URL, Depth: 1, Error: errors.New("fetch error: context canceled")}
+		return true
+	})
+	if len(results) != 2 {
+		t.Errorf("forwarded %d results, want 2", len(results))
+	}
+
+	cp.save(queue, 3, 1)
+
+	state, found, err := store.Load()
+	if err != nil || !found {
+		t.Fatalf("Load() = found %v, error %v", found, err)
+	}
+	if len(state.Pending) != 3 || state.Pending[0].URL != item2.URL {
+		t.Fatalf("Pending = %+v, want the interrupted item first, then the queue", state.Pending)
+	}
+	if state.URLsProcessed != 2 || state.PaginationPages != 1 {
+		t.Errorf("URLsProcessed = %d, PaginationPages = %d; want 2 and 1", state.URLsProcessed, state.PaginationPages)
+	}
+	if got := state.Outcomes[list.URL].Status; got != OutcomeFetched {
+		t.Errorf("listing outcome = %q, want %q", got, OutcomeFetched)
+	}
+	if got := state.Outcomes[item1.URL].Status; got != OutcomeExtracted {
+		t.Errorf("item outcome = %q, want %q", got, OutcomeExtracted)
+	}
+	if _, ok := state.Outcomes[item2.URL]; ok {
+		t.Error("interrupted item should have no outcome")
+	}
+
+	// A resumed queue has the pending URLs and still rejects seen ones
+	resumed := NewURLQueue()
+	restoreQueue(resumed, state)
+	if resumed.Len() != 3 {
+		t.Errorf("resumed Len() = %d, want 3", resumed.Len())
+	}
+	if resumed.Add(item1.URL, 1) || resumed.Add(list.URL, 0) {
+		t.Error("resumed queue should not re-add finished URLs")
+	}
+	if !resumed.Add("https://example.com/item/4", 1) {
+		t.Error("resumed queue should add new URLs")
+	}
+	_, _, req, _ := resumed.PopRequestFunc(func(url string, _ int) bool { return url == "https://example.com/search" })
+	if req == nil || req.Body != `{"q": "chairs"}` {
+		t.Errorf("resumed request = %+v, want the saved body", req)
+	}
+}
+
+func TestCheckpointer_RequestOutcomes(t *testing.T) {
+	store, _ := NewStateStore(t.TempDir())
+	cp := newCheckpointer(store, 0, nil)
+
+	// Two searches against the same endpoint keep separate outcomes
+	chairs := FrontierItem{URL: "https://example.com/search", Request: &fetcher.Request{URL: "https://example.com/search", Method: "POST", Form: map[string]string{"q": "chairs"}}}
+	tables := FrontierItem{URL: "https://example.com/search", Request: &fetcher.Request{URL: "https://example.com/search", Method: "POST", Form: map[string]string{"q": "tables"}}}
+	results := make(chan Result, 2)
+	cp.start(chairs)
+	cp.start(tables)
+	cp.track(context.Background(), chairs, results, func(out chan<- Result, _ bool) bool {
+		out <- Result{URL: chairs.URL, Data: map[string]any{"ok": true}}
+		return true
+	})
+	cp.track(context.Background(), tables, results, func(out chan<- Result, _ bool) bool {
+		out <- Result{URL: tables.URL, Error: errors.New("extraction error")}
+		return true
+	})
+
+	cp.save(NewURLQueue(), 2, 2)
+	state, _, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := state.Outcomes[chairs.key()].Status; got != OutcomeExtracted {
+		t.Errorf("chairs outcome = %q, want %q", got, OutcomeExtracted)
+	}
+	if got := state.Outcomes[tables.key()].Status; got != OutcomeFailed {
+		t.Errorf("tables outcome = %q, want %q", got, OutcomeFailed)
+	}
+}
+
+func TestCheckpointer_ReportedInFlight(t *testing.T) {
+	store, _ := NewStateStore(t.TempDir())
+	cp := newCheckpointer(store, 0, nil)
+
+	// A checkpoint is taken after the item's result was reported but before
+	// it finished queuing its links
+	item := FrontierItem{URL: "https://example.com/item/1", Depth: 1}
+	results := make(chan Result, 10)
+	cp.start(item)
+	cp.track(context.Background(), item, results, func(out chan<- Result, linksOnly bool) bool {
+		if linksOnly {
+			t.Error("a new item should be processed in full")
+		}
+		out <- Result{URL: item.URL, Depth: 1, Data: map[string]any{"ok": true}}
+		for !cp.reported(item) {
+			time.Sleep(time.Millisecond) // recorded once passed on
+		}
+		cp.save(NewURLQueue(), 1, 0)
+		return true
+	})
+
+	state, _, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(state.Pending) != 1 || state.Pending[0].URL != item.URL {
+		t.Fatalf("Pending = %+v, want the in-flight item", state.Pending)
+	}
+	if got := state.Outcomes[item.URL].Status; got != OutcomeExtracted {
+		t.Errorf("in-flight item outcome = %q, want %q", got, OutcomeExtracted)
+	}
+
+	// On resume it is fetched for its links only, and its result isn't
+	// reported again; results for its links still are
+	resumed := newCheckpointer(store, 0, state)
+	results = make(chan Result, 10)
+	resumed.start(item)
+	resumed.track(context.Background(), item, results, func(out chan<- Result, linksOnly bool) bool {
+		if !linksOnly {
+			t.Error("an item already reported should be processed for its links only")
+		}
+		out <- Result{URL: item.URL, Depth: 1}
+		out <- Result{URL: "https://example.com/private", Depth: 2, Skipped: true, Error: ErrRobotsDisallowed}
+		return true
+	})
+	if len(results) != 1 || (<-results).URL != "https://example.com/private" {
+		t.Error("resumed item should report only its links' results")
+	}
+	resumed.save(NewURLQueue(), 1, 0)
+	state, _, _ = store.Load()
+	if len(state.Pending) != 0 || state.Outcomes[item.URL].Status != OutcomeExtracted {
+		t.Errorf("after resuming, Pending = %+v, outcome = %+v; want none pending and the outcome kept", state.Pending, state.Outcomes[item.URL])
+	}
+}
+
+func TestCheckpointer_LinksCutShort(t *testing.T) {
+	store, _ := NewStateStore(t.TempDir())
+	cp := newCheckpointer(store, 0, nil)
+
+	// The crawl is cancelled after the listing's result was reported, before
+	// robots.txt could be checked for all of its links
+	list := FrontierItem{URL: "https://example.com/list"}
+	results := make(chan Result, 1)
+	cp.start(list)
+	cp.track(context.Background(), list, results, func(out chan<- Result, _ bool) bool {
+		out <- Result{URL: list.URL, Data: map[string]any{"ok": true}}
+		return false
+	})
+	cp.save(NewURLQueue(), 1, 1)
+
+	state, _, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(state.Pending) != 1 || state.Pending[0].URL != list.URL {
+		t.Errorf("Pending = %+v, want the listing kept in flight", state.Pending)
+	}
+	if got := state.Outcomes[list.URL].Status; got != OutcomeExtracted {
+		t.Errorf("listing outcome = %q, want %q so it isn't extracted again", got, OutcomeExtracted)
+	}
+	if state.URLsProcessed != 0 || state.PaginationPages != 0 {
+		t.Errorf("URLsProcessed = %d, PaginationPages = %d; want the listing not counted", state.URLsProcessed, state.PaginationPages)
+	}
+}
@@ -0,0 +1,45 @@
+package crawler
+
+import (
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+	"github.com/jmylchreest/refyne/pkg/llm"
+	"github.com/jmylchreest/refyne/pkg/schema"
+)
+
+// ScreenshotOptions returns the screenshot options for a page extracted with
+// s. A schema that uses vision turns screenshots on (with defaults if cfg is
+// nil) and may add page images; otherwise cfg is returned as is.
+func ScreenshotOptions(cfg *fetcher.ScreenshotConfig, s schema.Schema) *fetcher.ScreenshotConfig {
+	if s.Vision == nil {
+		return cfg
+	}
+	var opts fetcher.ScreenshotConfig
+	if cfg != nil {
+		opts = *cfg
+	}
+	opts.Images = opts.Images || s.Vision.Images
+	return &opts
+}
+
+// VisionInput returns the text and images to extract from for a page. Without
+// vision, or when no images were captured (e.g., a static fetch), it is the
+// cleaned content alone.
+func VisionInput(content fetcher.Content, cleaned string, v *schema.Vision) (string, []llm.Image) {
+	if v == nil {
+		return cleaned, nil
+	}
+
+	var images []llm.Image
+	if content.Screenshot != nil {
+		images = append(images, llm.Image{MediaType: content.Screenshot.MediaType, Data: content.Screenshot.Data})
+	}
+	if v.Images {
+		for _, img := range content.Images {
+			images = append(images, llm.Image{MediaType: img.MediaType, Data: img.Data})
+		}
+	}
+	if len(images) == 0 || !v.ImagesOnly() {
+		return cleaned, images
+	}
+	return "", images
+}
@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/jmylchreest/refyne/pkg/fetcher"
+	"github.com/jmylchreest/refyne/pkg/schema"
+)
+
+// --- Vision Tests ---
+
+func TestScreenshotOptions(t *testing.T) {
+	configured := &fetcher.ScreenshotConfig{Quality: 60}
+
+	if got := ScreenshotOptions(nil, schema.Schema{}); got != nil {
+		t.Errorf("text-only schema: got %+v, want no screenshots", got)
+	}
+	if got := ScreenshotOptions(configured, schema.Schema{}); got != configured {
+		t.Errorf("text-only schema should keep explicit settings, got %+v", got)
+	}
+
+	got := ScreenshotOptions(nil, schema.Schema{Vision: &schema.Vision{}})
+	if got == nil || got.Images {
+		t.Errorf("vision schema: got %+v, want a screenshot without page images", got)
+	}
+
+	got = ScreenshotOptions(configured, schema.Schema{Vision: &schema.Vision{Images: true}})
+	if got == nil || got.Quality != 60 || !got.Images {
+		t.Errorf("vision schema with images: got %+v, want configured quality and page images", got)
+	}
+	if configured.Images {
+		t.Error("ScreenshotOptions should not modify the configured settings")
+	}
+}
+
+func TestVisionInput(t *testing.T) {
+	content := fetcher.Content{
+		Screenshot: &fetcher.Image{MediaType: "image/jpeg", Data: []byte("page")},
+		Images:     []fetcher.Image{{URL: "https://example.com/plan.png", MediaType: "image/jpeg", Data: []byte("plan")}},
+	}
+	const cleaned = "# Flat for sale"
+
+	tests := []struct {
+		name       string
+		content    fetcher.Content
+		vision     *schema.Vision
+		wantText   string
+		wantImages int
+		firstImage string
+	}{
+		{"text only", content, nil, cleaned, 0, ""},
+		{"screenshot appended", content, &schema.Vision{}, cleaned, 1, "page"},
+		{"with page images", content, &schema.Vision{Images: true}, cleaned, 2, "page"},
+		{"images only", content, &schema.Vision{Mode: schema.VisionOnly}, "", 1, "page"},
+		{"nothing captured", fetcher.Content{}, &schema.Vision{Mode: schema.VisionOnly}, cleaned, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, images := VisionInput(tt.content, cleaned, tt.vision)
+			if text != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+			if len(images) != tt.wantImages {
+				t.Fatalf("images = %d, want %d", len(images), tt.wantImages)
+			}
+			if tt.firstImage != "" && string(images[0].Data) != tt.firstImage {
+				t.Errorf("first image = %q, want the %s", images[0].Data, tt.firstImage)
+			}
+		})
+	}
+}
@@ -634,6 +634,15 @@ var trackingParams = map[string]bool{
 	"_ga": true, "_gl": true, // Google
 }
 
+// IsTrackingParam reports whether a URL query parameter is a tracking
+// parameter (utm_*, gclid, fbclid, ...) that the cleaner strips from links.
+// The check is case-insensitive.
+func IsTrackingParam(name string) bool {
+	nameLower := strings.ToLower(name)
+	// Also check utm_ prefix for any we might have missed
+	return trackingParams[nameLower] || strings.HasPrefix(nameLower, "utm_")
+}
+
 // stripTrackingParams removes UTM and common tracking parameters from URLs.
 func (c *Cleaner) stripTrackingParams(doc *goquery.Document, result *Result, phase *PhaseStats) {
 	// Process href attributes on links
@@ -698,13 +707,7 @@ func (c *Cleaner) cleanURL(rawURL string) string {
 		if eqIdx := strings.Index(param, "="); eqIdx != -1 {
 			key = param[:eqIdx]
 		}
-		// Check against tracking params (case-insensitive for utm_*)
-		keyLower := strings.ToLower(key)
-		if trackingParams[keyLower] {
-			continue
-		}
-		// Also check utm_ prefix for any we might have missed
-		if strings.HasPrefix(keyLower, "utm_") {
+		if IsTrackingParam(key) {
 			continue
 		}
 		kept = append(kept, param)
@@ -1,6 +1,7 @@
 package extractor
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/jmylchreest/refyne/pkg/llm"
@@ -111,6 +112,37 @@ func BuildPrompt(content string, s schema.Schema, previousErr error, maxContentS
 	return prompt.String()
 }
 
+// BuildImagePrompt creates the extraction prompt for a request with images of
+// the page attached. content may be empty when only the images are sent.
+func BuildImagePrompt(content string, imageCount int, s schema.Schema, previousErr error, maxContentSize int) string {
+	var prompt strings.Builder
+
+	if imageCount == 1 {
+		prompt.WriteString("Extract structured data from the webpage shown in the attached image.")
+	} else {
+		fmt.Fprintf(&prompt, "Extract structured data from the webpage shown in the %d attached images.", imageCount)
+	}
+	prompt.WriteString(" Read text, numbers and labels from the images where the information only appears visually.\n\n")
+	prompt.WriteString(s.ToPromptDescription())
+
+	// Include previous errors for self-correction
+	if previousErr != nil {
+		prompt.WriteString("\n## Previous Attempt Errors\n")
+		prompt.WriteString("The previous extraction attempt had these errors that need to be fixed:\n")
+		prompt.WriteString(previousErr.Error())
+		prompt.WriteString("\n\nPlease correct these errors in your response.\n")
+	}
+
+	if content != "" {
+		prompt.WriteString("\n## Webpage Content\n")
+		prompt.WriteString("```\n")
+		prompt.WriteString(TruncateContent(content, maxContentSize))
+		prompt.WriteString("\n```\n")
+	}
+
+	return prompt.String()
+}
+
 // TruncateContent limits content size to avoid token limits.
 // maxLen of 0 means no limit.
 func TruncateContent(content string, maxLen int) string {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/jmylchreest/refyne/pkg/llm"
 	"github.com/jmylchreest/refyne/pkg/schema"
 )
 
@@ -28,6 +29,12 @@ func NewFallback(extractors ...Extractor) *FallbackExtractor {
 
 // Extract tries each extractor in order until one succeeds.
 func (f *FallbackExtractor) Extract(ctx context.Context, content string, s schema.Schema) (*Result, error) {
+	return f.ExtractWithImages(ctx, content, nil, s)
+}
+
+// ExtractWithImages tries each extractor in order until one succeeds, sending
+// the images along with the content.
+func (f *FallbackExtractor) ExtractWithImages(ctx context.Context, content string, images []llm.Image, s schema.Schema) (*Result, error) {
 	var lastErr error
 	var tried []string
 
@@ -37,7 +44,7 @@ func (f *FallbackExtractor) Extract(ctx context.Context, content string, s schem
 		}
 
 		tried = append(tried, ext.Name())
-		result, err := ext.Extract(ctx, content, s)
+		result, err := ExtractWithImages(ctx, ext, content, images, s)
 		if err == nil {
 			return result, nil
 		}
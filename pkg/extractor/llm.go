@@ -51,10 +51,22 @@ func NewBaseLLMExtractor(name string, provider llm.Provider, cfg *LLMConfig) *Ba
 
 // Extract performs LLM-based data extraction with retry logic.
 func (e *BaseLLMExtractor) Extract(ctx context.Context, content string, s schema.Schema) (*Result, error) {
+	return e.extract(ctx, content, nil, s)
+}
+
+// ExtractWithImages performs extraction from content and images of the page.
+// The provider's model must support vision.
+func (e *BaseLLMExtractor) ExtractWithImages(ctx context.Context, content string, images []llm.Image, s schema.Schema) (*Result, error) {
+	return e.extract(ctx, content, images, s)
+}
+
+// extract runs extraction attempts until one validates or retries run out.
+func (e *BaseLLMExtractor) extract(ctx context.Context, content string, images []llm.Image, s schema.Schema) (*Result, error) {
 	logger.Debug("extractor starting",
 		"extractor", e.name,
 		"schema", s.Name,
 		"content_size", len(content),
+		"images", len(images),
 		"max_retries", e.config.MaxRetries)
 
 	var lastErr error
@@ -66,7 +78,7 @@ func (e *BaseLLMExtractor) Extract(ctx context.Context, content string, s schema
 		logger.Debug("extractor attempt", "attempt", attempt+1, "max_attempts", e.config.MaxRetries+1)
 
 		start := time.Now()
-		result, err := e.extractOnceWithAttempt(ctx, content, s, lastErr, attempt)
+		result, err := e.extractOnceWithAttempt(ctx, content, images, s, lastErr, attempt)
 		lastResult = result // Preserve for FinishReason access on failure
 		duration := time.Since(start)
 		totalDuration += duration
@@ -143,12 +155,15 @@ func (e *BaseLLMExtractor) Extract(ctx context.Context, content string, s schema
 }
 
 // extractOnceWithAttempt performs a single extraction attempt with attempt tracking for observer.
-func (e *BaseLLMExtractor) extractOnceWithAttempt(ctx context.Context, content string, s schema.Schema, previousErr error, attempt int) (*Result, error) {
+func (e *BaseLLMExtractor) extractOnceWithAttempt(ctx context.Context, content string, images []llm.Image, s schema.Schema, previousErr error, attempt int) (*Result, error) {
 	logger.Debug("extractor building prompt",
 		"has_previous_error", previousErr != nil,
 		"max_content_size", e.config.MaxContentSize)
 
 	prompt := BuildPrompt(content, s, previousErr, e.config.MaxContentSize)
+	if len(images) > 0 {
+		prompt = BuildImagePrompt(content, len(images), s, previousErr, e.config.MaxContentSize)
+	}
 	logger.Debug("extractor prompt built", "prompt_size", len(prompt))
 
 	jsonSchema, err := s.ToJSONSchema()
@@ -164,7 +179,7 @@ func (e *BaseLLMExtractor) extractOnceWithAttempt(ctx context.Context, content s
 	}
 	messages := []llm.Message{
 		systemMsg,
-		{Role: llm.RoleUser, Content: prompt},
+		{Role: llm.RoleUser, Content: prompt, Images: images},
 	}
 
 	logger.Debug("extractor calling LLM",
@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 
+	"github.com/jmylchreest/refyne/pkg/llm"
 	"github.com/jmylchreest/refyne/pkg/schema"
 )
 
@@ -23,6 +24,12 @@ func NewPipeline(extractors ...Extractor) *PipelineExtractor {
 // Extract runs each extractor in sequence.
 // The final result is returned. Token usage and duration are accumulated.
 func (p *PipelineExtractor) Extract(ctx context.Context, content string, s schema.Schema) (*Result, error) {
+	return p.ExtractWithImages(ctx, content, nil, s)
+}
+
+// ExtractWithImages runs each extractor in sequence, sending the images along
+// with the content.
+func (p *PipelineExtractor) ExtractWithImages(ctx context.Context, content string, images []llm.Image, s schema.Schema) (*Result, error) {
 	var finalResult *Result
 	var totalUsage Usage
 	var totalRetries int
@@ -32,7 +39,7 @@ func (p *PipelineExtractor) Extract(ctx context.Context, content string, s schem
 			continue
 		}
 
-		result, err := ext.Extract(ctx, content, s)
+		result, err := ExtractWithImages(ctx, ext, content, images, s)
 		if err != nil {
 			return nil, err
 		}
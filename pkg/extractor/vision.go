@@ -0,0 +1,43 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jmylchreest/refyne/pkg/llm"
+	"github.com/jmylchreest/refyne/pkg/schema"
+)
+
+// ErrImagesNotSupported is returned when images are passed to an extractor
+// that cannot send them to its model.
+var ErrImagesNotSupported = errors.New("extractor does not support images")
+
+// ImageExtractor is an optional interface for extractors that can send images
+// of the page (screenshots, charts, floor plans) with the content to a
+// vision-capable model. LLM extractors, fallback chains and pipelines implement it.
+type ImageExtractor interface {
+	// ExtractWithImages performs extraction from content and images.
+	// content may be empty when only the images are sent.
+	ExtractWithImages(ctx context.Context, content string, images []llm.Image, s schema.Schema) (*Result, error)
+}
+
+// ExtractWithImages extracts from content and images using ext. Without
+// images it is the same as ext.Extract.
+func ExtractWithImages(ctx context.Context, ext Extractor, content string, images []llm.Image, s schema.Schema) (*Result, error) {
+	if len(images) == 0 {
+		return ext.Extract(ctx, content, s)
+	}
+	ie, ok := ext.(ImageExtractor)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrImagesNotSupported, ext.Name())
+	}
+	return ie.ExtractWithImages(ctx, content, images, s)
+}
+
+// Ensure the built-in extractors can send images
+var (
+	_ ImageExtractor = (*BaseLLMExtractor)(nil)
+	_ ImageExtractor = (*FallbackExtractor)(nil)
+	_ ImageExtractor = (*PipelineExtractor)(nil)
+)
@@ -0,0 +1,201 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ActionType names a step in a browser action script.
+type ActionType string
+
+const (
+	// ActionNavigate loads URL (resolved against the page being scripted).
+	ActionNavigate ActionType = "navigate"
+	// ActionFill types Value into the element matching Selector.
+	ActionFill ActionType = "fill"
+	// ActionClick clicks the element matching Selector. With Times > 1 it keeps
+	// clicking (e.g., a "Load more" button), pausing Duration between clicks,
+	// until the element disappears.
+	ActionClick ActionType = "click"
+	// ActionSelect chooses the option with value Value in the <select> matching Selector.
+	ActionSelect ActionType = "select"
+	// ActionScroll scrolls to the bottom of the page up to Times times, pausing
+	// Duration after each scroll, and stops early once no new elements (matching
+	// Selector, if set) appear.
+	ActionScroll ActionType = "scroll"
+	// ActionWait waits until an element matching Selector is present.
+	ActionWait ActionType = "wait"
+	// ActionNetworkIdle waits until no requests have been in flight for Duration.
+	ActionNetworkIdle ActionType = "network_idle"
+	// ActionEval evaluates Script in the page, awaiting it if it returns a promise.
+	ActionEval ActionType = "eval"
+	// ActionSleep pauses for Duration.
+	ActionSleep ActionType = "sleep"
+)
+
+// Action is one step of a browser action script.
+type Action struct {
+	Type     ActionType    `yaml:"action" json:"action"`
+	URL      string        `yaml:"url,omitempty" json:"url,omitempty"`
+	Selector string        `yaml:"selector,omitempty" json:"selector,omitempty"`
+	Value    string        `yaml:"value,omitempty" json:"value,omitempty"`
+	Script   string        `yaml:"script,omitempty" json:"script,omitempty"`
+	Times    int           `yaml:"times,omitempty" json:"times,omitempty"`       // click, scroll: maximum repetitions (default 1)
+	Duration time.Duration `yaml:"duration,omitempty" json:"duration,omitempty"` // sleep; pause after click/scroll; quiet period for network_idle
+	Optional bool          `yaml:"optional,omitempty" json:"optional,omitempty"` // click, fill, select: skip if the element is missing
+}
+
+// Validate checks that the action has the fields its type needs.
+func (a Action) Validate() error {
+	switch a.Type {
+	case ActionNavigate:
+		if a.URL == "" {
+			return fmt.Errorf("%s action requires a url", a.Type)
+		}
+	case ActionFill, ActionClick, ActionSelect, ActionWait:
+		if a.Selector == "" {
+			return fmt.Errorf("%s action requires a selector", a.Type)
+		}
+	case ActionEval:
+		if a.Script == "" {
+			return fmt.Errorf("%s action requires a script", a.Type)
+		}
+	case ActionSleep:
+		if a.Duration <= 0 {
+			return fmt.Errorf("%s action requires a positive duration", a.Type)
+		}
+	case ActionScroll, ActionNetworkIdle:
+	default:
+		return fmt.Errorf("unknown action %q (use navigate, fill, click, select, scroll, wait, network_idle, eval or sleep)", a.Type)
+	}
+	if a.Times < 0 || a.Duration < 0 {
+		return fmt.Errorf("%s action: times and duration must not be negative", a.Type)
+	}
+	return nil
+}
+
+// ParseAction parses the compact "type:argument" form used on the command line:
+//
+//	click:button.load-more    fill:#search=laptops    select:#sort=price
+//	scroll:10                 wait:.results           sleep:2s
+//	network_idle              eval:window.scrollTo(0, 0)
+//
+// For fill and select the value follows the last "=" of the argument.
+func ParseAction(s string) (Action, error) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(s), ":")
+	a := Action{Type: ActionType(strings.ReplaceAll(strings.ToLower(name), "-", "_"))}
+	switch a.Type {
+	case ActionNavigate:
+		a.URL = arg
+	case ActionFill, ActionSelect:
+		i := strings.LastIndex(arg, "=")
+		if i < 0 {
+			return Action{}, fmt.Errorf("%s action %q must be selector=value", a.Type, s)
+		}
+		a.Selector, a.Value = arg[:i], arg[i+1:]
+	case ActionClick, ActionWait:
+		a.Selector = arg
+	case ActionScroll:
+		if arg != "" {
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				return Action{}, fmt.Errorf("scroll action %q: times must be a number", s)
+			}
+			a.Times = n
+		}
+	case ActionEval:
+		a.Script = arg
+	case ActionSleep, ActionNetworkIdle:
+		if arg != "" {
+			d, err := time.ParseDuration(arg)
+			if err != nil {
+				return Action{}, fmt.Errorf("%s action %q: %w", a.Type, s, err)
+			}
+			a.Duration = d
+		}
+	}
+	if err := a.Validate(); err != nil {
+		return Action{}, err
+	}
+	return a, nil
+}
+
+// ActionRunner is implemented by fetchers that can drive a browser through an
+// action script. Cookies set during the script must end up in the fetcher's jar.
+type ActionRunner interface {
+	RunActions(ctx context.Context, baseURL string, actions []Action) error
+}
+
+// ActionRule applies an action script to pages whose URL matches Pattern.
+type ActionRule struct {
+	Pattern string   `yaml:"pattern" json:"pattern"` // Regex matched against the page URL
+	Actions []Action `yaml:"actions" json:"actions"`
+}
+
+// ActionConfig selects the browser actions run on each page after it loads
+// (dynamic fetchers only). Actions run on every page, followed by the actions
+// of the first rule in URLs whose pattern matches the page.
+type ActionConfig struct {
+	Actions []Action     `yaml:"actions,omitempty" json:"actions,omitempty"`
+	URLs    []ActionRule `yaml:"urls,omitempty" json:"urls,omitempty"`
+}
+
+// LoadActionConfig reads an action config from a YAML file.
+func LoadActionConfig(path string) (ActionConfig, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- caller-specified actions config
+	if err != nil {
+		return ActionConfig{}, fmt.Errorf("failed to read actions config: %w", err)
+	}
+	var cfg ActionConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return ActionConfig{}, fmt.Errorf("failed to parse actions config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return ActionConfig{}, err
+	}
+	return cfg, nil
+}
+
+// Validate checks every action and URL pattern.
+func (c ActionConfig) Validate() error {
+	for i, a := range c.Actions {
+		if err := a.Validate(); err != nil {
+			return fmt.Errorf("action %d: %w", i+1, err)
+		}
+	}
+	for _, rule := range c.URLs {
+		if rule.Pattern == "" {
+			return errors.New("url rule requires a pattern")
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("url rule %q: invalid pattern: %w", rule.Pattern, err)
+		}
+		for i, a := range rule.Actions {
+			if err := a.Validate(); err != nil {
+				return fmt.Errorf("url rule %q: action %d: %w", rule.Pattern, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// For returns the actions to run on targetURL, or nil if there are none.
+func (c ActionConfig) For(targetURL string) []Action {
+	actions := c.Actions
+	for _, rule := range c.URLs {
+		if matched, _ := regexp.MatchString(rule.Pattern, targetURL); matched {
+			actions = slices.Concat(actions, rule.Actions)
+			break
+		}
+	}
+	return slices.Clip(actions)
+}
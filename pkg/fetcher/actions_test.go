@@ -0,0 +1,125 @@
+package fetcher
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// --- Browser Action Tests ---
+
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Action
+	}{
+		{"click:button.load-more", Action{Type: ActionClick, Selector: "button.load-more"}},
+		{"click:li:nth-child(2) > a", Action{Type: ActionClick, Selector: "li:nth-child(2) > a"}},
+		{"fill:input[name=q]=red shoes", Action{Type: ActionFill, Selector: "input[name=q]", Value: "red shoes"}},
+		{"select:#sort=price-asc", Action{Type: ActionSelect, Selector: "#sort", Value: "price-asc"}},
+		{"scroll", Action{Type: ActionScroll}},
+		{"scroll:10", Action{Type: ActionScroll, Times: 10}},
+		{"network-idle:1s", Action{Type: ActionNetworkIdle, Duration: time.Second}},
+		{"sleep:250ms", Action{Type: ActionSleep, Duration: 250 * time.Millisecond}},
+		{"eval:window.scrollTo(0, 0)", Action{Type: ActionEval, Script: "window.scrollTo(0, 0)"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseAction(tt.in)
+		if err != nil {
+			t.Errorf("ParseAction(%q) error = %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseAction(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"click", "fill:#q", "scroll:lots", "sleep", "hover:.menu", "eval"} {
+		if _, err := ParseAction(bad); err == nil {
+			t.Errorf("ParseAction(%q) should fail", bad)
+		}
+	}
+}
+
+func TestActionConfig_For(t *testing.T) {
+	consent := Action{Type: ActionClick, Selector: "#accept", Optional: true}
+	scroll := Action{Type: ActionScroll, Times: 20}
+	loadMore := Action{Type: ActionClick, Selector: ".more", Times: 5}
+	cfg := ActionConfig{
+		Actions: []Action{consent},
+		URLs: []ActionRule{
+			{Pattern: `/search\?`, Actions: []Action{scroll}},
+			{Pattern: `/search`, Actions: []Action{loadMore}},
+		},
+	}
+
+	tests := []struct {
+		url  string
+		want []Action
+	}{
+		{"https://shop.example.com/item/1", []Action{consent}},
+		{"https://shop.example.com/search?q=shoes", []Action{consent, scroll}}, // first matching rule only
+		{"https://shop.example.com/search", []Action{consent, loadMore}},
+	}
+	for _, tt := range tests {
+		if got := cfg.For(tt.url); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("For(%q) = %+v, want %+v", tt.url, got, tt.want)
+		}
+	}
+
+	if got := (ActionConfig{}).For("https://example.com"); got != nil {
+		t.Errorf("empty config For() = %+v, want nil", got)
+	}
+}
+
+func TestLoadActionConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "actions.yaml")
+	yamlCfg := `actions:
+  - action: click
+    selector: "#cookie-accept"
+    optional: true
+urls:
+  - pattern: "/listings/"
+    actions:
+      - action: scroll
+        times: 15
+        duration: 2s
+      - action: network_idle
+`
+	if err := os.WriteFile(path, []byte(yamlCfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadActionConfig(path)
+	if err != nil {
+		t.Fatalf("LoadActionConfig() error = %v", err)
+	}
+	got := cfg.For("https://homes.example.com/listings/london")
+	want := []Action{
+		{Type: ActionClick, Selector: "#cookie-accept", Optional: true},
+		{Type: ActionScroll, Times: 15, Duration: 2 * time.Second},
+		{Type: ActionNetworkIdle},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("For() = %+v, want %+v", got, want)
+	}
+
+	bad := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(bad, []byte("urls:\n  - pattern: \"(\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadActionConfig(bad); err == nil {
+		t.Error("LoadActionConfig() should reject an invalid pattern")
+	}
+}
+
+func TestCacheKey_Actions(t *testing.T) {
+	plain := CacheKey("https://example.com/list", Options{})
+	scrolled := CacheKey("https://example.com/list", Options{Actions: []Action{{Type: ActionScroll, Times: 5}}})
+	if plain == scrolled {
+		t.Error("pages fetched with actions should be cached separately")
+	}
+}
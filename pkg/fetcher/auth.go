@@ -0,0 +1,359 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+)
+
+// ErrLoginFailed indicates the auth step could not establish a session.
+var ErrLoginFailed = errors.New("login failed")
+
+// FormLogin describes a login performed by POSTing a form.
+type FormLogin struct {
+	// PageURL is an optional login page fetched first. Hidden inputs of the form
+	// matching Selector (CSRF tokens and the like) are submitted along with Fields,
+	// and the form's action is used when URL is empty.
+	PageURL  string `yaml:"page_url,omitempty" json:"page_url,omitempty"`
+	Selector string `yaml:"selector,omitempty" json:"selector,omitempty"` // Default: "form"
+
+	// URL the form is posted to.
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+
+	// Fields maps form field names to values. $VAR and ${VAR} are expanded from
+	// the environment so credentials can stay out of config files.
+	Fields map[string]string `yaml:"fields" json:"fields"`
+}
+
+// AuthConfig is a declarative login step run before a host is crawled.
+// Exactly one of Form or Script must be set. Relative URLs are resolved
+// against the root of the host being logged into.
+type AuthConfig struct {
+	Form   *FormLogin `yaml:"form,omitempty" json:"form,omitempty"`
+	Script []Action   `yaml:"script,omitempty" json:"script,omitempty"` // Requires a fetcher implementing ActionRunner
+
+	// Hosts limits login to these hosts (default: every host fetched).
+	Hosts []string `yaml:"hosts,omitempty" json:"hosts,omitempty"`
+
+	// Re-login triggers: a page matching LoggedOutSelector or returning one of
+	// LoggedOutStatus means the session has expired.
+	LoggedOutSelector string `yaml:"logged_out_selector,omitempty" json:"logged_out_selector,omitempty"`
+	LoggedOutStatus   []int  `yaml:"logged_out_status,omitempty" json:"logged_out_status,omitempty"`
+}
+
+// LoadAuthConfig reads an AuthConfig from a YAML or JSON file.
+func LoadAuthConfig(path string) (AuthConfig, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- caller-specified auth config
+	if err != nil {
+		return AuthConfig{}, fmt.Errorf("failed to read auth config: %w", err)
+	}
+	var cfg AuthConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return AuthConfig{}, fmt.Errorf("failed to parse auth config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return AuthConfig{}, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that the config describes exactly one login method.
+func (c AuthConfig) Validate() error {
+	switch {
+	case c.Form == nil && len(c.Script) == 0:
+		return errors.New("auth config requires a form or a script")
+	case c.Form != nil && len(c.Script) > 0:
+		return errors.New("auth config must not set both form and script")
+	case c.Form != nil && c.Form.URL == "" && c.Form.PageURL == "":
+		return errors.New("form login requires a url or page_url")
+	}
+	for i, a := range c.Script {
+		if err := a.Validate(); err != nil {
+			return fmt.Errorf("script step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// AuthFetcher wraps another Fetcher and logs in once per host before the first
+// fetch, storing the session in the wrapped fetcher's cookie jar. When a page
+// looks logged out it logs in again and retries the fetch once.
+type AuthFetcher struct {
+	next   Fetcher
+	config AuthConfig
+
+	mu    sync.Mutex
+	hosts map[string]*authState
+}
+
+// authState tracks the session for one host.
+type authState struct {
+	mu     sync.Mutex
+	logins int   // Successful logins so far
+	err    error // Sticky login failure; the host is not retried
+}
+
+// NewAuthenticating wraps next with the login step in cfg.
+// The wrapped fetcher (or one it wraps) must expose a cookie jar, and script
+// logins need an ActionRunner.
+func NewAuthenticating(next Fetcher, cfg AuthConfig) *AuthFetcher {
+	return &AuthFetcher{next: next, config: cfg, hosts: make(map[string]*authState)}
+}
+
+// Fetch logs in to the URL's host if needed, then fetches through the wrapped fetcher.
+func (f *AuthFetcher) Fetch(ctx context.Context, targetURL string, opts Options) (Content, error) {
+	u, err := url.Parse(targetURL)
+	if err != nil || !f.covers(u.Hostname()) {
+		return f.next.Fetch(ctx, targetURL, opts)
+	}
+
+	logins, err := f.login(ctx, u, opts, 0)
+	if err != nil {
+		return Content{URL: targetURL}, err
+	}
+
+	content, err := f.next.Fetch(ctx, targetURL, opts)
+	if !f.loggedOut(content) {
+		return content, err
+	}
+
+	logger.Info("session logged out, logging in again", "url", targetURL, "status", content.StatusCode)
+	if _, err := f.login(ctx, u, opts, logins); err != nil {
+		return content, err
+	}
+	return f.next.Fetch(ctx, targetURL, opts)
+}
+
+// Login runs the login step for the URL's host unless it has already run.
+func (f *AuthFetcher) Login(ctx context.Context, targetURL string, opts Options) error {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if !f.covers(u.Hostname()) {
+		return nil
+	}
+	_, err = f.login(ctx, u, opts, 0)
+	return err
+}
+
+// login logs in to u's host unless more than seen logins have already
+// succeeded, in which case another worker has refreshed the session.
+// It returns the number of successful logins.
+func (f *AuthFetcher) login(ctx context.Context, u *url.URL, opts Options, seen int) (int, error) {
+	f.mu.Lock()
+	state, ok := f.hosts[u.Host]
+	if !ok {
+		state = &authState{}
+		f.hosts[u.Host] = state
+	}
+	f.mu.Unlock()
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.err != nil {
+		return state.logins, state.err
+	}
+	if state.logins > seen {
+		return state.logins, nil
+	}
+
+	base := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}
+	logger.Info("logging in", "host", u.Host)
+	var err error
+	if f.config.Form != nil {
+		err = f.formLogin(ctx, base, opts)
+	} else {
+		err = f.scriptLogin(ctx, base)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			state.err = err
+		}
+		logger.Warn("login failed", "host", u.Host, "error", err)
+		return state.logins, err
+	}
+	state.logins++
+	logger.Debug("login complete", "host", u.Host, "logins", state.logins)
+	return state.logins, nil
+}
+
+// formLogin posts the configured form using the wrapped fetcher's cookie jar.
+func (f *AuthFetcher) formLogin(ctx context.Context, base *url.URL, opts Options) error {
+	jar, ok := unwrapAs[interface{ CookieJar() *CookieJar }](f.next)
+	if !ok {
+		return fmt.Errorf("%w: %s fetcher has no cookie jar", ErrLoginFailed, f.next.Type())
+	}
+
+	form := f.config.Form
+	client := &http.Client{Jar: jar.CookieJar(), Timeout: opts.Timeout}
+	if client.Timeout == 0 {
+		client.Timeout = DefaultStaticConfig().Timeout
+	}
+	send := func(req *http.Request) (*http.Response, error) {
+		req.Header.Set("User-Agent", coalesce(opts.UserAgent, defaultUserAgent))
+		for k, v := range opts.Headers {
+			req.Header.Set(k, v)
+		}
+		return client.Do(req)
+	}
+
+	values := url.Values{}
+	action := ""
+	if form.PageURL != "" {
+		pageURL := resolveURL(base, form.PageURL)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrLoginFailed, err)
+		}
+		resp, err := send(req)
+		if err != nil {
+			return fmt.Errorf("%w: failed to load login page: %w", ErrLoginFailed, err)
+		}
+		doc, err := goquery.NewDocumentFromReader(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("%w: failed to parse login page: %w", ErrLoginFailed, err)
+		}
+
+		selector := coalesce(form.Selector, "form")
+		formSel := doc.Find(selector).First()
+		if formSel.Length() == 0 {
+			return fmt.Errorf("%w: no form matching %q on %s", ErrLoginFailed, selector, pageURL)
+		}
+		formSel.Find("input[type=hidden][name]").Each(func(_ int, s *goquery.Selection) {
+			name, _ := s.Attr("name")
+			value, _ := s.Attr("value")
+			values.Set(name, value)
+		})
+		action = pageURL.String()
+		if attr, ok := formSel.Attr("action"); ok && attr != "" {
+			action = resolveURL(resp.Request.URL, attr).String()
+		}
+	}
+	if form.URL != "" {
+		action = resolveURL(base, form.URL).String()
+	}
+	for name, value := range form.Fields {
+		values.Set(name, os.ExpandEnv(value))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, action, strings.NewReader(values.Encode()))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrLoginFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := send(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrLoginFailed, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 || slices.Contains(f.config.LoggedOutStatus, resp.StatusCode) {
+		return fmt.Errorf("%w: %s returned status %d", ErrLoginFailed, action, resp.StatusCode)
+	}
+	if f.config.LoggedOutSelector != "" {
+		doc, err := goquery.NewDocumentFromReader(resp.Body)
+		if err == nil && doc.Find(f.config.LoggedOutSelector).Length() > 0 {
+			return fmt.Errorf("%w: still logged out after posting to %s", ErrLoginFailed, action)
+		}
+	}
+	return nil
+}
+
+// scriptLogin runs the configured browser script on the wrapped fetcher.
+func (f *AuthFetcher) scriptLogin(ctx context.Context, base *url.URL) error {
+	runner, ok := unwrapAs[ActionRunner](f.next)
+	if !ok {
+		return fmt.Errorf("%w: %s fetcher cannot run browser scripts", ErrLoginFailed, f.next.Type())
+	}
+
+	actions := make([]Action, len(f.config.Script))
+	for i, a := range f.config.Script {
+		a.URL = os.ExpandEnv(a.URL)
+		a.Value = os.ExpandEnv(a.Value)
+		actions[i] = a
+	}
+	if err := runner.RunActions(ctx, base.String(), actions); err != nil {
+		return fmt.Errorf("%w: %w", ErrLoginFailed, err)
+	}
+	return nil
+}
+
+// covers reports whether host needs logging in to.
+func (f *AuthFetcher) covers(host string) bool {
+	if len(f.config.Hosts) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(f.config.Hosts, func(h string) bool {
+		return strings.EqualFold(h, host)
+	})
+}
+
+// loggedOut reports whether content shows the session has expired.
+func (f *AuthFetcher) loggedOut(content Content) bool {
+	if content.StatusCode != 0 && slices.Contains(f.config.LoggedOutStatus, content.StatusCode) {
+		return true
+	}
+	if f.config.LoggedOutSelector == "" || content.HTML == "" {
+		return false
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content.HTML))
+	return err == nil && doc.Find(f.config.LoggedOutSelector).Length() > 0
+}
+
+// Unwrap returns the wrapped fetcher.
+func (f *AuthFetcher) Unwrap() Fetcher {
+	return f.next
+}
+
+// Close closes the wrapped fetcher.
+func (f *AuthFetcher) Close() error {
+	return f.next.Close()
+}
+
+// Type returns the wrapped fetcher's type; authentication is transparent.
+func (f *AuthFetcher) Type() string {
+	return f.next.Type()
+}
+
+// unwrapAs returns the first fetcher in the wrapper tree rooted at f that
+// implements T. Wrappers expose their inner fetchers with Unwrap, returning
+// either a Fetcher or a []Fetcher (searched in order).
+func unwrapAs[T any](f Fetcher) (T, bool) {
+	if t, ok := f.(T); ok {
+		return t, true
+	}
+	switch w := f.(type) {
+	case interface{ Unwrap() Fetcher }:
+		return unwrapAs[T](w.Unwrap())
+	case interface{ Unwrap() []Fetcher }:
+		for _, inner := range w.Unwrap() {
+			if t, ok := unwrapAs[T](inner); ok {
+				return t, true
+			}
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// resolveURL resolves ref against base, returning base if ref is invalid.
+func resolveURL(base *url.URL, ref string) *url.URL {
+	r, err := url.Parse(ref)
+	if err != nil {
+		return base
+	}
+	return base.ResolveReference(r)
+}
@@ -0,0 +1,193 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newLoginServer serves a login form with a CSRF token and a /private page
+// that needs the session cookie. Sessions are invalidated by bumping expire.
+func newLoginServer(t *testing.T, logins, expire *atomic.Int32) string {
+	t.Helper()
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			if r.Method == http.MethodGet {
+				http.SetCookie(w, &http.Cookie{Name: "csrf", Value: "tok", Path: "/"})
+				_, _ = w.Write([]byte(`<html><body><form action="/session" method="post">
+					<input type="hidden" name="csrf" value="tok">
+					<input name="user"><input name="pass" type="password">
+				</form></body></html>`))
+			}
+		case "/session":
+			csrf, err := r.Cookie("csrf")
+			if err != nil || csrf.Value != r.PostFormValue("csrf") || r.PostFormValue("pass") != "hunter2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			n := logins.Add(1)
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: strconv.Itoa(int(n)), Path: "/"})
+			_, _ = w.Write([]byte("<html><body>welcome</body></html>"))
+		case "/private":
+			session, err := r.Cookie("session")
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if n, _ := strconv.Atoi(session.Value); n <= int(expire.Load()) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte("<html><body>secret</body></html>"))
+		}
+	})
+	return srv.URL
+}
+
+// --- AuthFetcher Tests ---
+
+func TestAuthFetcher_FormLogin(t *testing.T) {
+	var logins, expire atomic.Int32
+	base := newLoginServer(t, &logins, &expire)
+	t.Setenv("REFYNE_TEST_PASS", "hunter2")
+
+	f := NewAuthenticating(NewStatic(StaticConfig{}), AuthConfig{
+		Form: &FormLogin{
+			PageURL: "/login",
+			Fields:  map[string]string{"user": "alice", "pass": "${REFYNE_TEST_PASS}"},
+		},
+		LoggedOutStatus: []int{http.StatusUnauthorized},
+	})
+
+	for range 3 {
+		content, err := f.Fetch(context.Background(), base+"/private", Options{})
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if !strings.Contains(content.Text, "secret") {
+			t.Fatalf("Fetch() = %q, want the private page", content.Text)
+		}
+	}
+	if got := logins.Load(); got != 1 {
+		t.Errorf("logged in %d times, want once per host", got)
+	}
+}
+
+func TestAuthFetcher_RelogsInWhenLoggedOut(t *testing.T) {
+	var logins, expire atomic.Int32
+	base := newLoginServer(t, &logins, &expire)
+
+	f := NewAuthenticating(NewStatic(StaticConfig{}), AuthConfig{
+		Form: &FormLogin{
+			PageURL: "/login",
+			Fields:  map[string]string{"user": "alice", "pass": "hunter2"},
+		},
+		LoggedOutStatus: []int{http.StatusUnauthorized},
+	})
+
+	if _, err := f.Fetch(context.Background(), base+"/private", Options{}); err != nil {
+		t.Fatalf("first Fetch() error = %v", err)
+	}
+	expire.Store(1) // server drops the first session
+
+	content, err := f.Fetch(context.Background(), base+"/private", Options{})
+	if err != nil {
+		t.Fatalf("Fetch() after expiry error = %v", err)
+	}
+	if !strings.Contains(content.Text, "secret") {
+		t.Errorf("Fetch() = %q, want the private page after re-login", content.Text)
+	}
+	if got := logins.Load(); got != 2 {
+		t.Errorf("logged in %d times, want 2", got)
+	}
+}
+
+func TestAuthFetcher_LoginFailureIsSticky(t *testing.T) {
+	var logins, expire atomic.Int32
+	base := newLoginServer(t, &logins, &expire)
+
+	f := NewAuthenticating(NewStatic(StaticConfig{}), AuthConfig{
+		Form: &FormLogin{
+			PageURL: "/login",
+			Fields:  map[string]string{"user": "alice", "pass": "wrong"},
+		},
+	})
+
+	for range 2 {
+		_, err := f.Fetch(context.Background(), base+"/private", Options{})
+		if !errors.Is(err, ErrLoginFailed) {
+			t.Fatalf("Fetch() error = %v, want ErrLoginFailed", err)
+		}
+	}
+}
+
+func TestAuthFetcher_SkipsOtherHosts(t *testing.T) {
+	srv, hits := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html><body>public</body></html>"))
+	})
+
+	f := NewAuthenticating(NewStatic(StaticConfig{}), AuthConfig{
+		Form:  &FormLogin{URL: "/login"},
+		Hosts: []string{"members.example.com"},
+	})
+	if _, err := f.Fetch(context.Background(), srv.URL, Options{}); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("server hit %d times, want 1 (no login)", got)
+	}
+}
+
+func TestAuthFetcher_ScriptNeedsActionRunner(t *testing.T) {
+	f := NewAuthenticating(NewStatic(StaticConfig{}), AuthConfig{
+		Script: []Action{{Type: ActionNavigate, URL: "/login"}},
+	})
+	err := f.Login(context.Background(), "https://example.com/", Options{})
+	if !errors.Is(err, ErrLoginFailed) {
+		t.Errorf("Login() error = %v, want ErrLoginFailed", err)
+	}
+}
+
+func TestLoadAuthConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{
+			name: "form",
+			yaml: "form:\n  url: /login\n  fields:\n    user: alice\nlogged_out_status: [401, 403]\n",
+		},
+		{
+			name: "script",
+			yaml: "script:\n  - action: navigate\n    url: /login\n  - action: fill\n    selector: '#user'\n    value: alice\n  - action: sleep\n    duration: 2s\n",
+		},
+		{name: "empty", yaml: "hosts: [example.com]\n", wantErr: true},
+		{name: "both", yaml: "form:\n  url: /login\nscript:\n  - action: click\n    selector: button\n", wantErr: true},
+		{name: "unknown action", yaml: "script:\n  - action: hover\n    selector: a\n", wantErr: true},
+		{name: "click without selector", yaml: "script:\n  - action: click\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "auth.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadAuthConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadAuthConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.name == "script" && cfg.Script[2].Duration.Seconds() != 2 {
+				t.Errorf("sleep duration = %v, want 2s", cfg.Script[2].Duration)
+			}
+		})
+	}
+}
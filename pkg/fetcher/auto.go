@@ -0,0 +1,164 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+	"github.com/jmylchreest/refyne/pkg/cleaner"
+)
+
+// AutoConfig controls when an AutoFetcher escalates to its dynamic fetcher.
+type AutoConfig struct {
+	// MinContentSize escalates pages whose cleaned content is smaller than this
+	// many bytes (default: 200, negative disables the size check).
+	MinContentSize int
+
+	// Cleaner measures content the way the extraction pipeline will see it.
+	// If nil, the fetcher's readable text (Content.Text) is measured instead.
+	Cleaner cleaner.Cleaner
+}
+
+// defaultAutoMinContentSize matches the crawler's default MinContentSize.
+const defaultAutoMinContentSize = 200
+
+// spaRootSelector matches the mount points of common JavaScript frameworks.
+const spaRootSelector = "#root, #app, #__next, #__nuxt, #___gatsby, [ng-app], [data-reactroot], app-root"
+
+// noscriptNotice matches <noscript> messages asking the user to enable JavaScript.
+var noscriptNotice = regexp.MustCompile(`(?i)(enable|turn on|requires?|need)\s+javascript|javascript\s+(is\s+)?(required|disabled|must be enabled)`)
+
+// maxNoscriptShellText is the readable text size below which a page with a
+// noscript notice is treated as a JavaScript shell. Larger pages usually carry
+// the notice only for optional widgets.
+const maxNoscriptShellText = 1000
+
+// AutoFetcher tries a static fetch first and escalates to a dynamic (browser)
+// fetch when the page looks like it needs JavaScript: too little content, an
+// empty single-page-app root, or a noscript notice. Once a host has been
+// escalated, its later pages go straight to the dynamic fetcher, as do pages
+// with browser actions.
+type AutoFetcher struct {
+	static  Fetcher
+	dynamic Fetcher
+	config  AutoConfig
+
+	mu           sync.Mutex
+	dynamicHosts map[string]bool
+}
+
+// NewAuto creates a fetcher that escalates from static to dynamic.
+// The AutoFetcher takes ownership of both fetchers and closes them on Close.
+func NewAuto(static, dynamic Fetcher, cfg AutoConfig) *AutoFetcher {
+	if cfg.MinContentSize == 0 {
+		cfg.MinContentSize = defaultAutoMinContentSize
+	}
+	return &AutoFetcher{
+		static:       static,
+		dynamic:      dynamic,
+		config:       cfg,
+		dynamicHosts: make(map[string]bool),
+	}
+}
+
+// Fetch retrieves the page statically, refetching it with the dynamic fetcher
+// if it needs JavaScript. Content.FetchedBy records which fetcher produced it.
+func (f *AutoFetcher) Fetch(ctx context.Context, targetURL string, opts Options) (Content, error) {
+	host := ""
+	if u, err := url.Parse(targetURL); err == nil {
+		host = strings.ToLower(u.Host)
+	}
+
+	f.mu.Lock()
+	escalated := f.dynamicHosts[host]
+	f.mu.Unlock()
+	if escalated || len(opts.Actions) > 0 || opts.Screenshot != nil {
+		return f.fetchDynamic(ctx, targetURL, opts, nil) // only a browser can run page actions or take screenshots
+	}
+
+	content, err := f.static.Fetch(ctx, targetURL, opts)
+	content.FetchedBy = f.static.Type()
+	if err != nil {
+		return content, err
+	}
+
+	reason := f.needsBrowser(content)
+	if reason == "" {
+		return content, nil
+	}
+
+	logger.Info("escalating to dynamic fetch", "url", targetURL, "host", host, "reason", reason)
+	f.mu.Lock()
+	f.dynamicHosts[host] = true
+	f.mu.Unlock()
+	return f.fetchDynamic(ctx, targetURL, opts, content.Attempts)
+}
+
+// fetchDynamic fetches with the dynamic fetcher, prepending any static attempts.
+func (f *AutoFetcher) fetchDynamic(ctx context.Context, targetURL string, opts Options, staticAttempts []Attempt) (Content, error) {
+	content, err := f.dynamic.Fetch(ctx, targetURL, opts)
+	content.FetchedBy = f.dynamic.Type()
+	if len(staticAttempts) > 0 {
+		content.Attempts = append(staticAttempts[:len(staticAttempts):len(staticAttempts)], content.Attempts...)
+	}
+	return content, err
+}
+
+// needsBrowser returns why a statically fetched page needs a browser, or "" if it doesn't.
+func (f *AutoFetcher) needsBrowser(content Content) string {
+	if content.Document != nil || content.HTML == "" {
+		return "" // PDFs and other documents don't render differently; 304s have no body
+	}
+
+	if f.config.MinContentSize > 0 && f.contentSize(content) < f.config.MinContentSize {
+		return "insufficient content"
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content.HTML))
+	if err != nil {
+		return ""
+	}
+	emptyRoot := false
+	doc.Find(spaRootSelector).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		emptyRoot = strings.TrimSpace(s.Text()) == ""
+		return !emptyRoot
+	})
+	if emptyRoot {
+		return "empty app root"
+	}
+	if len(content.Text) < maxNoscriptShellText && noscriptNotice.MatchString(doc.Find("noscript").Text()) {
+		return "noscript notice"
+	}
+	return ""
+}
+
+// contentSize returns the size of the page's content as the extractor would see it.
+func (f *AutoFetcher) contentSize(content Content) int {
+	if f.config.Cleaner != nil {
+		if cleaned, err := f.config.Cleaner.Clean(content.HTML); err == nil {
+			return len(cleaned)
+		}
+	}
+	return len(content.Text)
+}
+
+// Unwrap returns the static and dynamic fetchers.
+func (f *AutoFetcher) Unwrap() []Fetcher {
+	return []Fetcher{f.static, f.dynamic}
+}
+
+// Close closes both fetchers.
+func (f *AutoFetcher) Close() error {
+	return errors.Join(f.static.Close(), f.dynamic.Close())
+}
+
+// Type returns "auto".
+func (f *AutoFetcher) Type() string {
+	return "auto"
+}
@@ -0,0 +1,159 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// stubFetcher stands in for the browser-based dynamic fetcher.
+type stubFetcher struct {
+	calls atomic.Int32
+}
+
+func (s *stubFetcher) Fetch(_ context.Context, url string, _ Options) (Content, error) {
+	s.calls.Add(1)
+	return Content{URL: url, HTML: "<html><body>rendered</body></html>", Text: "rendered"}, nil
+}
+
+func (s *stubFetcher) Close() error { return nil }
+func (s *stubFetcher) Type() string { return "dynamic" }
+
+var autoArticle = "<p>" + strings.Repeat("Plenty of server-rendered article text. ", 20) + "</p>"
+
+// --- AutoFetcher Tests ---
+
+func TestAutoFetcher_Escalation(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		escalate bool
+	}{
+		{"server-rendered page", "<html><body>" + autoArticle + "</body></html>", false},
+		{"insufficient content", "<html><body><p>Loading...</p></body></html>", true},
+		{"empty app root", `<html><body><header>` + autoArticle + `</header><div id="root"></div></body></html>`, true},
+		{"populated app root", `<html><body><div id="root">` + autoArticle + `</div></body></html>`, false},
+		{"noscript notice", `<html><body><noscript>You need to enable JavaScript to run this app.</noscript><p>` + strings.Repeat("Shell text. ", 30) + `</p></body></html>`, true},
+		{"noscript on large page", `<html><body><noscript>Please enable JavaScript for comments.</noscript>` + strings.Repeat(autoArticle, 3) + `</body></html>`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			})
+			dynamic := &stubFetcher{}
+			f := NewAuto(NewStatic(StaticConfig{}), dynamic, AutoConfig{})
+
+			content, err := f.Fetch(context.Background(), srv.URL, Options{})
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			want := "static"
+			if tt.escalate {
+				want = "dynamic"
+			}
+			if content.FetchedBy != want {
+				t.Errorf("FetchedBy = %q, want %q", content.FetchedBy, want)
+			}
+			if escalated := dynamic.calls.Load() > 0; escalated != tt.escalate {
+				t.Errorf("escalated = %v, want %v", escalated, tt.escalate)
+			}
+		})
+	}
+}
+
+func TestAutoFetcher_EscalationIsPerHost(t *testing.T) {
+	srv, hits := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/app" {
+			_, _ = w.Write([]byte(`<html><body><div id="app"></div></body></html>`))
+			return
+		}
+		_, _ = w.Write([]byte("<html><body>" + autoArticle + "</body></html>"))
+	})
+	dynamic := &stubFetcher{}
+	f := NewAuto(NewStatic(StaticConfig{}), dynamic, AutoConfig{})
+
+	first, err := f.Fetch(context.Background(), srv.URL+"/app", Options{})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(first.Attempts) == 0 {
+		t.Error("escalated fetch should keep the static attempt")
+	}
+
+	// The article would pass statically, but its host has already been escalated
+	second, err := f.Fetch(context.Background(), srv.URL+"/article", Options{})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if second.FetchedBy != "dynamic" {
+		t.Errorf("FetchedBy = %q, want dynamic", second.FetchedBy)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("static fetches = %d, want 1", got)
+	}
+	if got := dynamic.calls.Load(); got != 2 {
+		t.Errorf("dynamic fetches = %d, want 2", got)
+	}
+}
+
+func TestAutoFetcher_DocumentsStayStatic(t *testing.T) {
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	})
+	dynamic := &stubFetcher{}
+	f := NewAuto(NewStatic(StaticConfig{}), dynamic, AutoConfig{})
+
+	content, err := f.Fetch(context.Background(), srv.URL, Options{})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if content.Document == nil || dynamic.calls.Load() != 0 {
+		t.Errorf("small JSON document should not be escalated (FetchedBy = %q)", content.FetchedBy)
+	}
+}
+
+func TestAutoFetcher_ActionsUseBrowser(t *testing.T) {
+	srv, hits := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html><body>" + autoArticle + "</body></html>"))
+	})
+	dynamic := &stubFetcher{}
+	f := NewAuto(NewStatic(StaticConfig{}), dynamic, AutoConfig{})
+
+	opts := Options{Actions: []Action{{Type: ActionScroll, Times: 3}}}
+	if _, err := f.Fetch(context.Background(), srv.URL+"/list", opts); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if hits.Load() != 0 || dynamic.calls.Load() != 1 {
+		t.Errorf("page with actions: static fetches = %d, dynamic = %d; want 0, 1", hits.Load(), dynamic.calls.Load())
+	}
+
+	// Pages without actions on the same host are still fetched statically
+	content, err := f.Fetch(context.Background(), srv.URL+"/item", Options{})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if content.FetchedBy != "static" {
+		t.Errorf("FetchedBy = %q, want static", content.FetchedBy)
+	}
+}
+
+func TestAutoFetcher_ScreenshotsUseBrowser(t *testing.T) {
+	srv, hits := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html><body>" + autoArticle + "</body></html>"))
+	})
+	dynamic := &stubFetcher{}
+	f := NewAuto(NewStatic(StaticConfig{}), dynamic, AutoConfig{})
+
+	opts := Options{Screenshot: &ScreenshotConfig{}}
+	if _, err := f.Fetch(context.Background(), srv.URL+"/listing", opts); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if hits.Load() != 0 || dynamic.calls.Load() != 1 {
+		t.Errorf("page with screenshot: static fetches = %d, dynamic = %d; want 0, 1", hits.Load(), dynamic.calls.Load())
+	}
+}
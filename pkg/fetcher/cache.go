@@ -0,0 +1,434 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+)
+
+// CacheMode controls how a CachingFetcher uses its store.
+type CacheMode string
+
+const (
+	// CacheOff bypasses the cache entirely.
+	CacheOff CacheMode = "off"
+	// CacheRead serves and revalidates cached entries but never writes new ones.
+	CacheRead CacheMode = "read"
+	// CacheWrite always fetches from the network and stores the responses.
+	CacheWrite CacheMode = "write"
+	// CacheReadWrite serves cached entries and stores new responses.
+	CacheReadWrite CacheMode = "readwrite"
+)
+
+// ParseCacheMode converts a string (e.g., from a CLI flag) to a CacheMode.
+func ParseCacheMode(s string) (CacheMode, error) {
+	switch mode := CacheMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case CacheOff, CacheRead, CacheWrite, CacheReadWrite:
+		return mode, nil
+	case "":
+		return CacheOff, nil
+	default:
+		return "", fmt.Errorf("unknown cache mode: %s (use off, read, write or readwrite)", s)
+	}
+}
+
+func (m CacheMode) canRead() bool  { return m == CacheRead || m == CacheReadWrite }
+func (m CacheMode) canWrite() bool { return m == CacheWrite || m == CacheReadWrite }
+
+// CacheEntry is a stored response along with the data needed to revalidate it.
+type CacheEntry struct {
+	Content      Content   `json:"content"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+	ExpiresAt    time.Time `json:"expires_at"` // Zero or past means the entry must be revalidated
+}
+
+// Fresh reports whether the entry can be served without contacting the origin.
+func (e *CacheEntry) Fresh(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.Before(e.ExpiresAt)
+}
+
+// CacheStore persists cache entries.
+// Implementations must be safe for concurrent use.
+type CacheStore interface {
+	// Get returns the entry for key. ok is false if there is no entry.
+	Get(key string) (entry *CacheEntry, ok bool, err error)
+
+	// Set stores or replaces the entry for key.
+	Set(key string, entry *CacheEntry) error
+
+	// Delete removes the entry for key. Deleting a missing key is not an error.
+	Delete(key string) error
+}
+
+// DiskCache is a CacheStore that keeps one JSON file per entry under a directory.
+// Files are sharded by the first two characters of the key to keep directories small.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache creates a disk-backed cache store rooted at dir.
+// The directory is created if it does not exist.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if dir == "" {
+		return nil, errors.New("cache directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	shard := key
+	if len(shard) > 2 {
+		shard = shard[:2]
+	}
+	return filepath.Join(d.dir, shard, key+".json")
+}
+
+// Get reads an entry from disk.
+func (d *DiskCache) Get(key string) (*CacheEntry, bool, error) {
+	data, err := os.ReadFile(d.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false, fmt.Errorf("corrupt cache entry %s: %w", key, err)
+	}
+	return &entry, true, nil
+}
+
+// Set writes an entry to disk. The write is atomic: readers never see a partial file.
+func (d *DiskCache) Set(key string, entry *CacheEntry) error {
+	path := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete removes an entry from disk.
+func (d *DiskCache) Delete(key string) error {
+	err := os.Remove(d.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// CacheConfig holds configuration for the caching fetcher.
+type CacheConfig struct {
+	Store CacheStore
+	Mode  CacheMode
+	// TTL overrides the freshness lifetime derived from response headers.
+	// When set, responses are cached for TTL regardless of Cache-Control
+	// (including no-store), which is what you want when iterating on a schema
+	// against pages that have already been fetched.
+	TTL time.Duration
+}
+
+// CachingFetcher wraps another Fetcher with an HTTP-semantics response cache.
+// Fresh entries are served without a network round-trip; stale entries with an
+// ETag or Last-Modified validator are revalidated with a conditional request.
+type CachingFetcher struct {
+	next   Fetcher
+	config CacheConfig
+	now    func() time.Time
+}
+
+// NewCaching wraps next with a response cache.
+func NewCaching(next Fetcher, cfg CacheConfig) *CachingFetcher {
+	if cfg.Mode == "" {
+		cfg.Mode = CacheReadWrite
+	}
+	return &CachingFetcher{next: next, config: cfg, now: time.Now}
+}
+
+// Fetch returns a cached response when possible, otherwise fetches from the wrapped fetcher.
+func (f *CachingFetcher) Fetch(ctx context.Context, targetURL string, opts Options) (Content, error) {
+	if f.config.Mode == CacheOff || f.config.Store == nil {
+		return f.next.Fetch(ctx, targetURL, opts)
+	}
+
+	key := CacheKey(targetURL, opts)
+	now := f.now()
+
+	var cached *CacheEntry
+	if f.config.Mode.canRead() {
+		entry, ok, err := f.config.Store.Get(key)
+		if err != nil {
+			logger.Debug("cache read failed", "url", targetURL, "error", err)
+		} else if ok {
+			cached = entry
+		}
+	}
+
+	if cached != nil && cached.Fresh(now) {
+		logger.Debug("cache hit", "url", targetURL, "expires_at", cached.ExpiresAt)
+		content := cached.Content
+		content.FromCache = true
+		content.Attempts = nil // no request was made
+		return content, nil
+	}
+
+	// Stale entry: revalidate with a conditional request if we have validators
+	fetchOpts := opts
+	if cached != nil && (cached.ETag != "" || cached.LastModified != "") {
+		fetchOpts.Headers = make(map[string]string, len(opts.Headers)+2)
+		for k, v := range opts.Headers {
+			fetchOpts.Headers[k] = v
+		}
+		if cached.ETag != "" {
+			fetchOpts.Headers["If-None-Match"] = cached.ETag
+		}
+		if cached.LastModified != "" {
+			fetchOpts.Headers["If-Modified-Since"] = cached.LastModified
+		}
+		logger.Debug("cache revalidating", "url", targetURL, "etag", cached.ETag, "last_modified", cached.LastModified)
+	}
+
+	content, err := f.next.Fetch(ctx, targetURL, fetchOpts)
+	if err != nil {
+		return content, err
+	}
+
+	if content.StatusCode == http.StatusNotModified {
+		if cached == nil {
+			if isConditional(opts.Headers) {
+				// The caller's own conditional request; the 304 is theirs to handle
+				return content, nil
+			}
+			// We didn't ask for a conditional response; nothing to merge with.
+			return content, fmt.Errorf("unexpected 304 Not Modified for %s", targetURL)
+		}
+		logger.Debug("cache revalidated", "url", targetURL)
+		cached.ExpiresAt = f.expiresAt(content.Headers, now)
+		cached.StoredAt = now
+		if f.config.Mode.canWrite() {
+			f.store(key, cached)
+		}
+		revalidated := cached.Content
+		revalidated.FromCache = true
+		revalidated.Attempts = content.Attempts
+		return revalidated, nil
+	}
+
+	if f.config.Mode.canWrite() && f.cacheable(content) {
+		f.store(key, &CacheEntry{
+			Content:      content,
+			ETag:         content.Headers.Get("ETag"),
+			LastModified: content.Headers.Get("Last-Modified"),
+			StoredAt:     now,
+			ExpiresAt:    f.expiresAt(content.Headers, now),
+		})
+	}
+
+	return content, nil
+}
+
+// isConditional reports whether headers make a request conditional.
+func isConditional(headers map[string]string) bool {
+	for name := range headers {
+		if strings.EqualFold(name, "If-None-Match") || strings.EqualFold(name, "If-Modified-Since") {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *CachingFetcher) store(key string, entry *CacheEntry) {
+	if err := f.config.Store.Set(key, entry); err != nil {
+		logger.Debug("cache write failed", "key", key, "error", err)
+	}
+}
+
+// cacheable reports whether a response may be stored.
+func (f *CachingFetcher) cacheable(content Content) bool {
+	if content.StatusCode != http.StatusOK {
+		return false
+	}
+	if f.config.TTL > 0 {
+		return true
+	}
+	return !parseCacheControl(content.Headers.Get("Cache-Control")).has("no-store")
+}
+
+// expiresAt computes when a response stops being fresh.
+// A zero result means the response must be revalidated before reuse.
+func (f *CachingFetcher) expiresAt(h http.Header, now time.Time) time.Time {
+	if f.config.TTL > 0 {
+		return now.Add(f.config.TTL)
+	}
+
+	cc := parseCacheControl(h.Get("Cache-Control"))
+	if cc.has("no-cache") || cc.has("no-store") {
+		return time.Time{}
+	}
+	if maxAge, ok := cc.seconds("max-age"); ok {
+		if age, err := strconv.Atoi(h.Get("Age")); err == nil && age > 0 {
+			maxAge -= age
+		}
+		if maxAge <= 0 {
+			return time.Time{}
+		}
+		return now.Add(time.Duration(maxAge) * time.Second)
+	}
+	if expires := h.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil || !t.After(now) {
+			return time.Time{}
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// Close releases the wrapped fetcher's resources.
+func (f *CachingFetcher) Close() error {
+	return f.next.Close()
+}
+
+// Unwrap returns the wrapped fetcher.
+func (f *CachingFetcher) Unwrap() Fetcher {
+	return f.next
+}
+
+// Type returns the wrapped fetcher's type; caching is transparent.
+func (f *CachingFetcher) Type() string {
+	return f.next.Type()
+}
+
+// cacheControl holds parsed Cache-Control directives.
+type cacheControl map[string]string
+
+func parseCacheControl(header string) cacheControl {
+	cc := cacheControl{}
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, "=")
+		cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return cc
+}
+
+func (cc cacheControl) has(directive string) bool {
+	_, ok := cc[directive]
+	return ok
+}
+
+func (cc cacheControl) seconds(directive string) (int, bool) {
+	v, ok := cc[directive]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// CacheKey derives a stable cache key from a URL and the options that affect
+// the response body. Timeouts are deliberately excluded.
+func CacheKey(targetURL string, opts Options) string {
+	var sb strings.Builder
+	sb.WriteString(normalizeCacheURL(targetURL))
+	sb.WriteString("\nua=")
+	sb.WriteString(opts.UserAgent)
+	sb.WriteString("\nwait=")
+	sb.WriteString(opts.WaitForSelector)
+	sb.WriteString("|")
+	sb.WriteString(opts.WaitDuration.String())
+	if method, contentType, body := opts.RequestBody(); method != http.MethodGet || body != nil {
+		fmt.Fprintf(&sb, "\nrequest=%s %s %x", method, contentType, sha256.Sum256(body))
+	}
+	if len(opts.Actions) > 0 {
+		fmt.Fprintf(&sb, "\nactions=%+v", opts.Actions)
+	}
+	if opts.Capture != nil {
+		fmt.Fprintf(&sb, "\ncapture=%+v", *opts.Capture)
+	}
+	if opts.Screenshot != nil {
+		fmt.Fprintf(&sb, "\nscreenshot=%+v", *opts.Screenshot)
+	}
+
+	headerNames := make([]string, 0, len(opts.Headers))
+	for k := range opts.Headers {
+		headerNames = append(headerNames, k)
+	}
+	sort.Strings(headerNames)
+	for _, k := range headerNames {
+		sb.WriteString("\nh:")
+		sb.WriteString(http.CanonicalHeaderKey(k))
+		sb.WriteString("=")
+		sb.WriteString(opts.Headers[k])
+	}
+
+	cookies := make([]string, 0, len(opts.Cookies))
+	for _, c := range opts.Cookies {
+		cookies = append(cookies, c.Domain+";"+c.Name+"="+c.Value)
+	}
+	sort.Strings(cookies)
+	for _, c := range cookies {
+		sb.WriteString("\nc:")
+		sb.WriteString(c)
+	}
+
+	sum := sha256.Sum256([]byte(sb.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeCacheURL lowercases the scheme and host, drops the fragment and
+// sorts query parameters so equivalent URLs share a cache entry.
+func normalizeCacheURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	if u.RawQuery != "" {
+		u.RawQuery = u.Query().Encode() // Encode sorts by key
+	}
+	return u.String()
+}
@@ -0,0 +1,209 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newCountingServer returns a test server that counts requests and lets the
+// handler decide the response.
+func newCountingServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func newTestCache(t *testing.T) *DiskCache {
+	t.Helper()
+	store, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+	return store
+}
+
+func TestCachingFetcher_FreshHitSkipsNetwork(t *testing.T) {
+	srv, hits := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=3600")
+		_, _ = w.Write([]byte("<html><body>hello</body></html>"))
+	})
+
+	f := NewCaching(NewStatic(StaticConfig{}), CacheConfig{Store: newTestCache(t), Mode: CacheReadWrite})
+
+	first, err := f.Fetch(context.Background(), srv.URL, Options{})
+	if err != nil {
+		t.Fatalf("first Fetch() error = %v", err)
+	}
+	if first.FromCache {
+		t.Error("first fetch should not come from cache")
+	}
+
+	second, err := f.Fetch(context.Background(), srv.URL, Options{})
+	if err != nil {
+		t.Fatalf("second Fetch() error = %v", err)
+	}
+	if !second.FromCache {
+		t.Error("second fetch should come from cache")
+	}
+	if second.HTML != first.HTML {
+		t.Errorf("cached HTML = %q, want %q", second.HTML, first.HTML)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("server hits = %d, want 1", got)
+	}
+}
+
+func TestCachingFetcher_RevalidatesWithETag(t *testing.T) {
+	srv, hits := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte("<html><body>versioned</body></html>"))
+	})
+
+	f := NewCaching(NewStatic(StaticConfig{}), CacheConfig{Store: newTestCache(t), Mode: CacheReadWrite})
+
+	if _, err := f.Fetch(context.Background(), srv.URL, Options{}); err != nil {
+		t.Fatalf("first Fetch() error = %v", err)
+	}
+
+	got, err := f.Fetch(context.Background(), srv.URL, Options{})
+	if err != nil {
+		t.Fatalf("revalidating Fetch() error = %v", err)
+	}
+	if !got.FromCache {
+		t.Error("revalidated response should be marked FromCache")
+	}
+	if got.HTML != "<html><body>versioned</body></html>" {
+		t.Errorf("revalidated HTML = %q", got.HTML)
+	}
+	if got.StatusCode != http.StatusOK {
+		t.Errorf("revalidated StatusCode = %d, want 200", got.StatusCode)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("server hits = %d, want 2 (initial + conditional)", n)
+	}
+}
+
+func TestCachingFetcher_CallerConditionalRequest(t *testing.T) {
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte("<html><body>versioned</body></html>"))
+	})
+
+	f := NewCaching(NewStatic(StaticConfig{}), CacheConfig{Store: newTestCache(t), Mode: CacheReadWrite})
+	got, err := f.Fetch(context.Background(), srv.URL, Options{Headers: map[string]string{"If-None-Match": `"v1"`}})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v, want the caller's 304 passed through", err)
+	}
+	if got.StatusCode != http.StatusNotModified {
+		t.Errorf("StatusCode = %d, want 304", got.StatusCode)
+	}
+}
+
+func TestCachingFetcher_TTLOverridesNoStore(t *testing.T) {
+	srv, hits := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write([]byte("<html><body>private</body></html>"))
+	})
+
+	store := newTestCache(t)
+
+	noTTL := NewCaching(NewStatic(StaticConfig{}), CacheConfig{Store: store, Mode: CacheReadWrite})
+	for i := 0; i < 2; i++ {
+		if _, err := noTTL.Fetch(context.Background(), srv.URL+"/a", Options{}); err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("no-store without TTL: server hits = %d, want 2", n)
+	}
+
+	hits.Store(0)
+	withTTL := NewCaching(NewStatic(StaticConfig{}), CacheConfig{Store: store, Mode: CacheReadWrite, TTL: time.Hour})
+	for i := 0; i < 2; i++ {
+		if _, err := withTTL.Fetch(context.Background(), srv.URL+"/b", Options{}); err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("no-store with TTL: server hits = %d, want 1", n)
+	}
+}
+
+func TestCachingFetcher_ReadModeDoesNotWrite(t *testing.T) {
+	srv, hits := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=3600")
+		_, _ = w.Write([]byte("<html><body>hello</body></html>"))
+	})
+
+	f := NewCaching(NewStatic(StaticConfig{}), CacheConfig{Store: newTestCache(t), Mode: CacheRead})
+	for i := 0; i < 2; i++ {
+		if _, err := f.Fetch(context.Background(), srv.URL, Options{}); err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("server hits = %d, want 2", n)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	base := CacheKey("https://Example.com/page?b=2&a=1#frag", Options{})
+
+	if got := CacheKey("https://example.com/page?a=1&b=2", Options{}); got != base {
+		t.Error("equivalent URLs should share a cache key")
+	}
+	if got := CacheKey("https://example.com/page?a=1&b=2", Options{Timeout: time.Minute}); got != base {
+		t.Error("timeout should not affect the cache key")
+	}
+	if got := CacheKey("https://example.com/page?a=1&b=2", Options{UserAgent: "bot"}); got == base {
+		t.Error("user agent should affect the cache key")
+	}
+	if got := CacheKey("https://example.com/page?a=1&b=2", Options{Headers: map[string]string{"Accept-Language": "th"}}); got == base {
+		t.Error("headers should affect the cache key")
+	}
+}
+
+func TestParseCacheMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    CacheMode
+		wantErr bool
+	}{
+		{"off", CacheOff, false},
+		{"READ", CacheRead, false},
+		{"write", CacheWrite, false},
+		{"readwrite", CacheReadWrite, false},
+		{"", CacheOff, false},
+		{"sometimes", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseCacheMode(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCacheMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCacheMode(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
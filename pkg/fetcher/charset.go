@@ -0,0 +1,168 @@
+package fetcher
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saintfish/chardet"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// metaCharsetRe matches <meta charset="..."> and
+// <meta http-equiv="Content-Type" content="text/html; charset=...">.
+var metaCharsetRe = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([a-z0-9_:.\-]+)`)
+
+// metaPrescanBytes is how far into a document a <meta> charset is looked for,
+// as in the HTML spec's prescan.
+const metaPrescanBytes = 1024
+
+// DetectCharset returns the canonical name of body's character encoding
+// ("utf-8", "shift_jis", "windows-1252", ...). In order, it uses a byte order
+// mark, the charset parameter of contentType, a <meta> charset declaration
+// and finally the content itself. A body that is valid UTF-8 is taken to be
+// UTF-8 whatever it declares, since servers often mislabel UTF-8 pages.
+func DetectCharset(body []byte, contentType string) string {
+	if _, name, ok := bomEncoding(body); ok {
+		return name
+	}
+	declared := declaredCharset(body, contentType)
+	if utf8.Valid(body) {
+		if declared != "" && isASCII(body) {
+			return declared // Any ASCII-compatible charset reads the same
+		}
+		return "utf-8"
+	}
+	if declared != "" && declared != "utf-8" {
+		return declared
+	}
+	return sniffCharset(body)
+}
+
+// DecodeBody converts body to UTF-8, returning it with the canonical name of
+// the charset it was decoded from (see DetectCharset). Binary bodies such as
+// PDFs and images are returned as they are, with no charset.
+func DecodeBody(body []byte, contentType string) (string, string) {
+	if len(body) == 0 || !isTextBody(body, contentType) {
+		return string(body), ""
+	}
+	name := DetectCharset(body, contentType)
+	enc, _, hasBOM := bomEncoding(body) // Decoding with it strips the BOM
+	if !hasBOM {
+		if name == "utf-8" {
+			return string(body), name
+		}
+		enc, _ = charset.Lookup(name)
+	}
+	if enc == nil {
+		return string(body), name
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return string(body), name
+	}
+	return string(decoded), name
+}
+
+// bomEncoding returns the encoding announced by body's byte order mark.
+func bomEncoding(body []byte) (encoding.Encoding, string, bool) {
+	switch {
+	case bytes.HasPrefix(body, []byte{0xEF, 0xBB, 0xBF}):
+		return unicode.UTF8BOM, "utf-8", true
+	case bytes.HasPrefix(body, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), "utf-16be", true
+	case bytes.HasPrefix(body, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), "utf-16le", true
+	}
+	return nil, "", false
+}
+
+// declaredCharset returns the canonical name of the charset declared in
+// contentType or, failing that, in a <meta> tag near the start of body.
+// Unknown charsets are ignored.
+func declaredCharset(body []byte, contentType string) string {
+	if name := headerCharset(contentType); name != "" {
+		return name
+	}
+	head := body[:min(len(body), metaPrescanBytes)]
+	if m := metaCharsetRe.FindSubmatch(head); m != nil {
+		if _, name := charset.Lookup(string(m[1])); name != "" {
+			// A document that reached us as bytes can't really be UTF-16
+			if strings.HasPrefix(name, "utf-16") {
+				return "utf-8"
+			}
+			return name
+		}
+	}
+	return ""
+}
+
+// headerCharset returns the canonical name of the charset parameter of a
+// Content-Type value, or "" if it has none or it is unknown.
+func headerCharset(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil || params["charset"] == "" {
+		return ""
+	}
+	_, name := charset.Lookup(params["charset"])
+	return name
+}
+
+// utf8ContentType returns contentType with its charset parameter, if it has
+// one, replaced by utf-8.
+func utf8ContentType(contentType string) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || params["charset"] == "" {
+		return contentType
+	}
+	params["charset"] = "utf-8"
+	return mime.FormatMediaType(mediaType, params)
+}
+
+// sniffCharset guesses the charset of an undeclared, non-UTF-8 body from its
+// byte patterns, falling back to windows-1252 as browsers do.
+func sniffCharset(body []byte) string {
+	result, err := chardet.NewHtmlDetector().DetectBest(body)
+	if err == nil {
+		// chardet names GB18030 "GB-18030"; the others are standard labels
+		label := strings.ReplaceAll(result.Charset, "GB-18030", "gb18030")
+		if _, name := charset.Lookup(label); name != "" && !strings.HasPrefix(name, "utf-") {
+			return name
+		}
+	}
+	return "windows-1252"
+}
+
+// isTextBody reports whether body is text that may need decoding, judging by
+// its content type or, without one, its first bytes.
+func isTextBody(body []byte, contentType string) bool {
+	if DetectDocumentFormat(contentType, body) == FormatPDF {
+		return false
+	}
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		return false // gzip, e.g. a sitemap.xml.gz served as application/xml
+	}
+	mediaType := mediaTypeOf(contentType)
+	if mediaType == "" {
+		mediaType = mediaTypeOf(http.DetectContentType(body))
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+xml") || strings.HasSuffix(mediaType, "+json") ||
+		mediaType == "application/xml" || mediaType == "application/json" ||
+		mediaType == "application/javascript"
+}
+
+// isASCII reports whether body contains only 7-bit bytes.
+func isASCII(body []byte) bool {
+	for _, b := range body {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,163 @@
+package fetcher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// encodeString encodes s with enc, failing the test if it can't be encoded.
+func encodeString(t *testing.T, enc encoding.Encoding, s string) []byte {
+	t.Helper()
+	b, err := enc.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatalf("encoding %q: %v", s, err)
+	}
+	return b
+}
+
+// --- Charset Tests ---
+
+func TestDecodeBody(t *testing.T) {
+	const japaneseText = "東京都渋谷区の賃貸マンション、駅から徒歩五分。ペット可、家具付きの物件です。"
+	const chineseText = "北京市朝阳区的公寓出租，离地铁站步行五分钟，可养宠物，带家具。"
+	const frenchText = "Appartement à louer près de la gare, très lumineux, idéal pour étudiants."
+	page := func(head, body string) string {
+		return "<html><head>" + head + "<title>t</title></head><body><p>" + body + "</p></body></html>"
+	}
+
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		want        string
+		wantCharset string
+	}{
+		{
+			name:        "header charset",
+			body:        encodeString(t, japanese.ShiftJIS, page("", japaneseText)),
+			contentType: "text/html; charset=Shift_JIS",
+			want:        japaneseText,
+			wantCharset: "shift_jis",
+		},
+		{
+			name:        "meta charset",
+			body:        encodeString(t, japanese.EUCJP, page(`<meta charset="euc-jp">`, japaneseText)),
+			contentType: "text/html",
+			want:        japaneseText,
+			wantCharset: "euc-jp",
+		},
+		{
+			name:        "meta http-equiv",
+			body:        encodeString(t, simplifiedchinese.GBK, page(`<meta http-equiv="Content-Type" content="text/html; charset=gb2312">`, chineseText)),
+			contentType: "text/html",
+			want:        chineseText,
+			wantCharset: "gbk",
+		},
+		{
+			name:        "byte order mark",
+			body:        encodeString(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), page("", japaneseText)),
+			contentType: "text/html; charset=iso-8859-1",
+			want:        japaneseText,
+			wantCharset: "utf-16le",
+		},
+		{
+			name:        "sniffed shift_jis",
+			body:        encodeString(t, japanese.ShiftJIS, page("", strings.Repeat(japaneseText, 3))),
+			contentType: "text/html",
+			want:        japaneseText,
+			wantCharset: "shift_jis",
+		},
+		{
+			name:        "sniffed latin",
+			body:        encodeString(t, charmap.ISO8859_1, page("", frenchText)),
+			want:        frenchText,
+			wantCharset: "windows-1252",
+		},
+		{
+			name:        "mislabelled utf-8",
+			body:        []byte(page(`<meta charset="iso-8859-1">`, japaneseText)),
+			contentType: "text/html; charset=iso-8859-1",
+			want:        japaneseText,
+			wantCharset: "utf-8",
+		},
+		{
+			name:        "ascii keeps declared charset",
+			body:        []byte(page("", "plain text")),
+			contentType: "text/html; charset=Shift_JIS",
+			want:        "plain text",
+			wantCharset: "shift_jis",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, charset := DecodeBody(tt.body, tt.contentType)
+			if charset != tt.wantCharset {
+				t.Errorf("charset = %q, want %q", charset, tt.wantCharset)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("decoded body = %q, want it to contain %q", got, tt.want)
+			}
+			if strings.HasPrefix(got, "\uFEFF") {
+				t.Error("decoded body should not start with a BOM")
+			}
+		})
+	}
+}
+
+func TestDecodeBody_Binary(t *testing.T) {
+	pdf := []byte("%PDF-1.7\n\xE2\xE3\xCF\xD3\n")
+	got, charset := DecodeBody(pdf, "application/pdf")
+	if got != string(pdf) || charset != "" {
+		t.Errorf("DecodeBody(pdf) = %q, %q; want the body unchanged and no charset", got, charset)
+	}
+
+	// Gzipped sitemaps are often served with an XML type and no Content-Encoding
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write([]byte(`<?xml version="1.0"?><urlset><url><loc>https://example.com/</loc></url></urlset>`))
+	_ = zw.Close()
+	got, charset = DecodeBody(gz.Bytes(), "application/xml")
+	if got != gz.String() || charset != "" {
+		t.Errorf("DecodeBody(gzip) changed the body (charset %q); want it unchanged and no charset", charset)
+	}
+}
+
+func TestStaticFetcher_Charset(t *testing.T) {
+	const title = "物件一覧"
+	const text = "駅から徒歩五分の賃貸マンション"
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		html := `<html><head><meta charset="shift_jis"><title>` + title + `</title></head><body><p>` + text + `</p></body></html>`
+		contentType := "text/html"
+		if r.URL.Path == "/header" {
+			contentType = "text/html; charset=shift_jis"
+		}
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(encodeString(t, japanese.ShiftJIS, html))
+	})
+
+	f := NewStatic(StaticConfig{})
+	for _, path := range []string{"/meta", "/header"} {
+		t.Run(path, func(t *testing.T) {
+			content, err := f.Fetch(context.Background(), srv.URL+path, Options{})
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if content.Charset != "shift_jis" {
+				t.Errorf("Charset = %q, want shift_jis", content.Charset)
+			}
+			if content.Title != title || !strings.Contains(content.Text, text) {
+				t.Errorf("Title = %q, Text = %q; want the page decoded from Shift_JIS", content.Title, content.Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,518 @@
+package fetcher
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// CookieJar is a thread-safe http.CookieJar that can be shared between
+// fetchers, seeded from and saved to Netscape cookies.txt or JSON files.
+//
+// Unlike net/http/cookiejar, it can enumerate its cookies with their full
+// attributes, which is needed to persist them and to sync them with a browser.
+// Cookies returned by Export and accepted by Import use the cookies.txt
+// convention for Domain: a leading dot marks a cookie that also applies to
+// subdomains; a bare host marks a host-only cookie.
+type CookieJar struct {
+	mu      sync.Mutex
+	entries map[string]*jarEntry // domain;path;name -> entry
+	now     func() time.Time
+}
+
+// jarEntry is a stored cookie.
+type jarEntry struct {
+	Name     string
+	Value    string
+	Domain   string // Without leading dot
+	HostOnly bool
+	Path     string
+	Secure   bool
+	HTTPOnly bool
+	Expires  time.Time // Zero for session cookies
+}
+
+func (e *jarEntry) key() string {
+	return e.Domain + ";" + e.Path + ";" + e.Name
+}
+
+func (e *jarEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !e.Expires.After(now)
+}
+
+// cookie converts the entry to an http.Cookie using the cookies.txt Domain convention.
+func (e *jarEntry) cookie() *http.Cookie {
+	domain := e.Domain
+	if !e.HostOnly {
+		domain = "." + domain
+	}
+	return &http.Cookie{
+		Name:     e.Name,
+		Value:    e.Value,
+		Domain:   domain,
+		Path:     e.Path,
+		Secure:   e.Secure,
+		HttpOnly: e.HTTPOnly,
+		Expires:  e.Expires,
+	}
+}
+
+// NewCookieJar creates an empty cookie jar.
+func NewCookieJar() *CookieJar {
+	return &CookieJar{
+		entries: make(map[string]*jarEntry),
+		now:     time.Now,
+	}
+}
+
+// SetCookies implements http.CookieJar, storing cookies received from u.
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	host := cookieHost(u)
+	if host == "" {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.now()
+	for _, c := range cookies {
+		e := &jarEntry{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+		}
+
+		// Domain: host-only unless a valid parent domain is given
+		domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+		switch {
+		case domain == "" || domain == host:
+			e.Domain, e.HostOnly = host, c.Domain == ""
+		case net.ParseIP(host) == nil && strings.HasSuffix(host, "."+domain) && !isPublicSuffix(domain):
+			e.Domain = domain
+		default:
+			continue // cookie for a domain the response may not set
+		}
+
+		if e.Path == "" || !strings.HasPrefix(e.Path, "/") {
+			e.Path = defaultCookiePath(u.Path)
+		}
+
+		switch {
+		case c.MaxAge < 0:
+			e.Expires = now // delete
+		case c.MaxAge > 0:
+			e.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case !c.Expires.IsZero():
+			e.Expires = c.Expires
+		}
+
+		j.put(e, now)
+	}
+}
+
+// Cookies implements http.CookieJar, returning the cookies to send to u.
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	entries := j.matching(u)
+	cookies := make([]*http.Cookie, len(entries))
+	for i, e := range entries {
+		cookies[i] = &http.Cookie{Name: e.Name, Value: e.Value}
+	}
+	return cookies
+}
+
+// Export returns the cookies that apply to u with all their attributes
+// (e.g., to load them into a browser). If u is nil, every cookie is returned.
+func (j *CookieJar) Export(u *url.URL) []*http.Cookie {
+	var entries []*jarEntry
+	if u != nil {
+		entries = j.matching(u)
+	} else {
+		entries = j.all()
+	}
+	cookies := make([]*http.Cookie, len(entries))
+	for i, e := range entries {
+		cookies[i] = e.cookie()
+	}
+	return cookies
+}
+
+// Import stores fully-specified cookies (e.g., read back from a browser),
+// trusting their Domain and Path as given.
+func (j *CookieJar) Import(cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.now()
+	for _, c := range cookies {
+		domain := strings.ToLower(c.Domain)
+		if domain == "" {
+			continue
+		}
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		j.put(&jarEntry{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   strings.TrimPrefix(domain, "."),
+			HostOnly: !strings.HasPrefix(domain, "."),
+			Path:     path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+			Expires:  c.Expires,
+		}, now)
+	}
+}
+
+// AddCookies stores Options.Cookies for targetURL. Cookies without a Domain
+// are scoped to targetURL's host.
+func (j *CookieJar) AddCookies(targetURL string, cookies []Cookie) {
+	u, err := url.Parse(targetURL)
+	if err != nil || len(cookies) == 0 {
+		return
+	}
+	httpCookies := make([]*http.Cookie, len(cookies))
+	for i, c := range cookies {
+		httpCookies[i] = &http.Cookie{Name: c.Name, Value: c.Value, Domain: c.Domain, Path: "/"}
+	}
+	j.SetCookies(u, httpCookies)
+}
+
+// Len returns the number of unexpired cookies in the jar.
+func (j *CookieJar) Len() int {
+	return len(j.all())
+}
+
+// put stores or deletes e. Caller holds j.mu.
+func (j *CookieJar) put(e *jarEntry, now time.Time) {
+	if e.expired(now) {
+		delete(j.entries, e.key())
+		return
+	}
+	j.entries[e.key()] = e
+}
+
+// matching returns the unexpired entries that apply to u, longest path first.
+func (j *CookieJar) matching(u *url.URL) []*jarEntry {
+	host := cookieHost(u)
+	if host == "" {
+		return nil
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	secure := u.Scheme == "https" || u.Scheme == "wss"
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.now()
+	var matched []*jarEntry
+	for key, e := range j.entries {
+		if e.expired(now) {
+			delete(j.entries, key)
+			continue
+		}
+		if e.Secure && !secure {
+			continue
+		}
+		if e.HostOnly && host != e.Domain {
+			continue
+		}
+		if !e.HostOnly && host != e.Domain && !strings.HasSuffix(host, "."+e.Domain) {
+			continue
+		}
+		if !cookiePathMatch(e.Path, path) {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	sort.SliceStable(matched, func(a, b int) bool {
+		if len(matched[a].Path) != len(matched[b].Path) {
+			return len(matched[a].Path) > len(matched[b].Path)
+		}
+		return matched[a].Name < matched[b].Name
+	})
+	return matched
+}
+
+// all returns every unexpired entry in a stable order.
+func (j *CookieJar) all() []*jarEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.now()
+	entries := make([]*jarEntry, 0, len(j.entries))
+	for key, e := range j.entries {
+		if e.expired(now) {
+			delete(j.entries, key)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(a, b int) bool { return entries[a].key() < entries[b].key() })
+	return entries
+}
+
+// cookieHost returns u's lowercased host without port.
+func cookieHost(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// defaultCookiePath implements the RFC 6265 default-path algorithm.
+func defaultCookiePath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+// cookiePathMatch implements RFC 6265 path matching.
+func cookiePathMatch(cookiePath, requestPath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+// isPublicSuffix reports whether domain is a public suffix (e.g., "co.uk"),
+// which no site may set cookies for.
+func isPublicSuffix(domain string) bool {
+	suffix, _ := publicsuffix.PublicSuffix(domain)
+	return suffix == domain
+}
+
+// --- Persistence ---
+
+// jsonCookie is the JSON cookie format used by browser cookie export
+// extensions (e.g., Cookie-Editor, EditThisCookie).
+type jsonCookie struct {
+	Name           string  `json:"name"`
+	Value          string  `json:"value"`
+	Domain         string  `json:"domain"`
+	Path           string  `json:"path,omitempty"`
+	ExpirationDate float64 `json:"expirationDate,omitempty"` // Unix seconds; 0 = session
+	HostOnly       bool    `json:"hostOnly,omitempty"`
+	Secure         bool    `json:"secure,omitempty"`
+	HTTPOnly       bool    `json:"httpOnly,omitempty"`
+	Session        bool    `json:"session,omitempty"`
+}
+
+// LoadFile adds the cookies in a Netscape cookies.txt or JSON file to the jar.
+// The format is detected from the content.
+func (j *CookieJar) LoadFile(path string) error {
+	data, err := os.ReadFile(path) //#nosec G304 -- caller-specified cookie file
+	if err != nil {
+		return fmt.Errorf("failed to read cookie file: %w", err)
+	}
+	if err := j.Load(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to parse cookie file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Load adds cookies from r, which may be in Netscape cookies.txt or JSON format.
+func (j *CookieJar) Load(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(data) > 0 && data[0] == '[' {
+		return j.loadJSON(bytes.NewReader(data))
+	}
+	return j.loadNetscape(bytes.NewReader(data))
+}
+
+func (j *CookieJar) loadJSON(r io.Reader) error {
+	var cookies []jsonCookie
+	if err := json.NewDecoder(r).Decode(&cookies); err != nil {
+		return err
+	}
+	imported := make([]*http.Cookie, 0, len(cookies))
+	for _, c := range cookies {
+		domain := c.Domain
+		if !c.HostOnly && !strings.HasPrefix(domain, ".") {
+			domain = "." + domain
+		}
+		if c.HostOnly {
+			domain = strings.TrimPrefix(domain, ".")
+		}
+		cookie := &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+		}
+		if c.ExpirationDate > 0 && !c.Session {
+			cookie.Expires = time.Unix(0, int64(c.ExpirationDate*float64(time.Second)))
+		}
+		imported = append(imported, cookie)
+	}
+	j.Import(imported)
+	return nil
+}
+
+func (j *CookieJar) loadNetscape(r io.Reader) error {
+	var imported []*http.Cookie
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := false
+		if rest, ok := strings.CutPrefix(line, "#HttpOnly_"); ok {
+			line, httpOnly = rest, true
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return fmt.Errorf("line %d: expected 7 tab-separated fields, got %d", lineNum, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid expiry %q", lineNum, fields[4])
+		}
+
+		domain := fields[0]
+		if strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(domain, ".") {
+			domain = "." + domain
+		}
+		cookie := &http.Cookie{
+			Domain:   domain,
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+			Name:     fields[5],
+			Value:    fields[6],
+		}
+		if expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+		imported = append(imported, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	j.Import(imported)
+	return nil
+}
+
+// SaveFile writes the jar to path atomically: as JSON if path ends in .json,
+// otherwise in Netscape cookies.txt format. Session cookies are included so a
+// saved login can be reused by the next run.
+func (j *CookieJar) SaveFile(path string) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create cookie directory: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = j.WriteJSON(&buf)
+	} else {
+		err = j.WriteNetscape(&buf)
+	}
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write cookie file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write cookie file: %w", err)
+	}
+	return nil
+}
+
+// WriteNetscape writes the jar in Netscape cookies.txt format.
+func (j *CookieJar) WriteNetscape(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	_, _ = bw.WriteString("# Netscape HTTP Cookie File\n")
+	for _, e := range j.all() {
+		domain := e.Domain
+		includeSubdomains := "FALSE"
+		if !e.HostOnly {
+			domain = "." + domain
+			includeSubdomains = "TRUE"
+		}
+		if e.HTTPOnly {
+			domain = "#HttpOnly_" + domain
+		}
+		var expires int64
+		if !e.Expires.IsZero() {
+			expires = e.Expires.Unix()
+		}
+		_, _ = fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, includeSubdomains, e.Path, strings.ToUpper(strconv.FormatBool(e.Secure)), expires, e.Name, e.Value)
+	}
+	return bw.Flush()
+}
+
+// WriteJSON writes the jar as a JSON array in the browser-export format.
+func (j *CookieJar) WriteJSON(w io.Writer) error {
+	entries := j.all()
+	cookies := make([]jsonCookie, len(entries))
+	for i, e := range entries {
+		domain := e.Domain
+		if !e.HostOnly {
+			domain = "." + domain
+		}
+		cookies[i] = jsonCookie{
+			Name:     e.Name,
+			Value:    e.Value,
+			Domain:   domain,
+			Path:     e.Path,
+			HostOnly: e.HostOnly,
+			Secure:   e.Secure,
+			HTTPOnly: e.HTTPOnly,
+			Session:  e.Expires.IsZero(),
+		}
+		if !e.Expires.IsZero() {
+			cookies[i].ExpirationDate = float64(e.Expires.Unix())
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cookies)
+}
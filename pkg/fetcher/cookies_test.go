@@ -0,0 +1,213 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("url.Parse(%q) error = %v", raw, err)
+	}
+	return u
+}
+
+// cookieNames returns the names of the cookies the jar would send to rawURL.
+func cookieNames(t *testing.T, jar *CookieJar, rawURL string) string {
+	t.Helper()
+	var names []string
+	for _, c := range jar.Cookies(mustParseURL(t, rawURL)) {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// --- CookieJar Tests ---
+
+func TestCookieJar_Scoping(t *testing.T) {
+	jar := NewCookieJar()
+	jar.SetCookies(mustParseURL(t, "https://www.example.com/account/login"), []*http.Cookie{
+		{Name: "host", Value: "1"},
+		{Name: "domain", Value: "1", Domain: ".example.com", Path: "/"},
+		{Name: "secure", Value: "1", Secure: true, Path: "/"},
+		{Name: "shop", Value: "1", Path: "/shop"},
+		{Name: "suffix", Value: "1", Domain: "com"},
+		{Name: "other", Value: "1", Domain: "other.com"},
+	})
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.example.com/account/settings", "host,domain,secure"}, // default path /account sorts first
+		{"https://www.example.com/", "domain,secure"},
+		{"http://www.example.com/account/x", "host,domain"},
+		{"https://api.example.com/account/x", "domain"},
+		{"https://www.example.com/shop/cart", "shop,domain,secure"},
+		{"https://www.example.com/shopping", "domain,secure"},
+		{"https://other.com/", ""},
+	}
+	for _, tt := range tests {
+		if got := cookieNames(t, jar, tt.url); got != tt.want {
+			t.Errorf("Cookies(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCookieJar_ExpiryAndDeletion(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	jar := NewCookieJar()
+	jar.now = func() time.Time { return now }
+	u := mustParseURL(t, "https://example.com/")
+
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "short", Value: "1", MaxAge: 60},
+		{Name: "session", Value: "1"},
+	})
+	if got := cookieNames(t, jar, u.String()); got != "session,short" {
+		t.Fatalf("Cookies() = %q", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if got := cookieNames(t, jar, u.String()); got != "session" {
+		t.Errorf("Cookies() after expiry = %q, want session", got)
+	}
+
+	jar.SetCookies(u, []*http.Cookie{{Name: "session", MaxAge: -1}})
+	if jar.Len() != 0 {
+		t.Errorf("Len() = %d after deletion, want 0", jar.Len())
+	}
+}
+
+func TestCookieJar_NetscapeRoundTrip(t *testing.T) {
+	input := "# Netscape HTTP Cookie File\n" +
+		".example.com\tTRUE\t/\tTRUE\t4102444800\tsid\tabc123\n" +
+		"#HttpOnly_shop.example.com\tFALSE\t/cart\tFALSE\t0\tcart\txyz\n"
+
+	jar := NewCookieJar()
+	if err := jar.Load(strings.NewReader(input)); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cookieNames(t, jar, "https://shop.example.com/cart"); got != "cart,sid" {
+		t.Errorf("Cookies() = %q, want cart,sid", got)
+	}
+	if got := cookieNames(t, jar, "https://api.example.com/cart"); got != "sid" {
+		t.Errorf("host-only cookie leaked to another host: %q", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := jar.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	reloaded := NewCookieJar()
+	if err := reloaded.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	exported := reloaded.Export(nil)
+	if len(exported) != 2 {
+		t.Fatalf("reloaded %d cookies, want 2", len(exported))
+	}
+	for _, c := range exported {
+		switch c.Name {
+		case "cart":
+			if !c.HttpOnly || c.Domain != "shop.example.com" || c.Path != "/cart" || !c.Expires.IsZero() {
+				t.Errorf("cart cookie = %+v", c)
+			}
+		case "sid":
+			if !c.Secure || c.Domain != ".example.com" || c.Expires.Unix() != 4102444800 {
+				t.Errorf("sid cookie = %+v", c)
+			}
+		}
+	}
+}
+
+func TestCookieJar_JSONRoundTrip(t *testing.T) {
+	input := `[
+		{"name": "sid", "value": "abc", "domain": ".example.com", "path": "/", "expirationDate": 4102444800.5, "secure": true},
+		{"name": "pref", "value": "dark", "domain": "www.example.com", "path": "/", "hostOnly": true, "session": true}
+	]`
+
+	jar := NewCookieJar()
+	if err := jar.Load(strings.NewReader(input)); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cookieNames(t, jar, "https://www.example.com/"); got != "pref,sid" {
+		t.Errorf("Cookies() = %q, want pref,sid", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	if err := jar.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	reloaded := NewCookieJar()
+	if err := reloaded.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if got := cookieNames(t, reloaded, "https://www.example.com/"); got != "pref,sid" {
+		t.Errorf("reloaded Cookies() = %q, want pref,sid", got)
+	}
+	if got := cookieNames(t, reloaded, "https://api.example.com/"); got != "sid" {
+		t.Errorf("reloaded Cookies() for other host = %q, want sid", got)
+	}
+}
+
+func TestCookieJar_LoadRejectsMalformedNetscape(t *testing.T) {
+	jar := NewCookieJar()
+	if err := jar.Load(strings.NewReader("example.com\tTRUE\t/\n")); err == nil {
+		t.Error("Load() should reject lines without 7 fields")
+	}
+}
+
+// --- StaticFetcher Session Tests ---
+
+func TestStaticFetcher_PersistsSessionCookies(t *testing.T) {
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cret", Path: "/"})
+			_, _ = w.Write([]byte("<html><body>logged in</body></html>"))
+		case "/account":
+			session, err := r.Cookie("session")
+			seed, _ := r.Cookie("seed")
+			if err != nil || session.Value != "s3cret" || seed == nil {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte("<html><body>welcome</body></html>"))
+		}
+	})
+
+	savePath := filepath.Join(t.TempDir(), "jar", "cookies.txt")
+	f := NewStatic(StaticConfig{SaveCookies: savePath})
+
+	if _, err := f.Fetch(context.Background(), srv.URL+"/login", Options{
+		Cookies: []Cookie{{Name: "seed", Value: "1"}},
+	}); err != nil {
+		t.Fatalf("login Fetch() error = %v", err)
+	}
+	content, err := f.Fetch(context.Background(), srv.URL+"/account", Options{})
+	if err != nil {
+		t.Fatalf("account Fetch() error = %v", err)
+	}
+	if !strings.Contains(content.Text, "welcome") {
+		t.Errorf("account page = %q, want the logged-in page", content.Text)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	saved := NewCookieJar()
+	if err := saved.LoadFile(savePath); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if saved.Len() != 2 {
+		t.Errorf("saved %d cookies, want 2", saved.Len())
+	}
+}
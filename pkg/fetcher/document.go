@@ -0,0 +1,214 @@
+package fetcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// DocumentFormat identifies a non-HTML response body.
+type DocumentFormat string
+
+// Document formats converted by ConvertDocument.
+const (
+	FormatPDF  DocumentFormat = "pdf"
+	FormatText DocumentFormat = "text"
+	FormatJSON DocumentFormat = "json"
+	FormatXML  DocumentFormat = "xml"
+)
+
+// Document describes a non-HTML response that was converted to Markdown.
+// The converted text (with metadata front matter and page headings) is in
+// Content.Text and should be passed to the extractor instead of cleaning
+// Content.HTML, which holds the raw body.
+type Document struct {
+	Format   DocumentFormat
+	Pages    int               // Page count (PDF only)
+	Metadata map[string]string // Document info such as title and author (PDF only)
+}
+
+// DetectDocumentFormat returns the document format of a response from its
+// Content-Type, sniffing the body for PDFs served with a generic type.
+// It returns "" for HTML and for types it doesn't convert.
+func DetectDocumentFormat(contentType string, body []byte) DocumentFormat {
+	if bytes.HasPrefix(body, []byte("%PDF-")) {
+		return FormatPDF
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case mediaType == "application/pdf":
+		return FormatPDF
+	case mediaType == "application/json", mediaType == "text/json", strings.HasSuffix(mediaType, "+json"):
+		return FormatJSON
+	case mediaType == "application/xhtml+xml":
+		return ""
+	case mediaType == "application/xml", mediaType == "text/xml", strings.HasSuffix(mediaType, "+xml"):
+		return FormatXML
+	case mediaType == "text/plain", mediaType == "text/markdown", mediaType == "text/csv":
+		return FormatText
+	}
+	return ""
+}
+
+// parseBody fills in the text, title and links of content, converting
+// non-HTML documents to Markdown.
+func parseBody(content *Content) error {
+	if format := DetectDocumentFormat(content.ContentType, []byte(content.HTML)); format != "" {
+		return ConvertDocument(content, format)
+	}
+	return parseContent(content)
+}
+
+// ConvertDocument converts content.HTML (the raw body) from format to Markdown,
+// storing it in content.Text and setting content.Document.
+// The Markdown starts with a front matter block of document metadata; PDF pages
+// follow under "## Page N" headings so extracted data can cite them.
+func ConvertDocument(content *Content, format DocumentFormat) (err error) {
+	doc := &Document{Format: format}
+	var body string
+
+	switch format {
+	case FormatPDF:
+		body, err = convertPDF([]byte(content.HTML), doc)
+	case FormatJSON:
+		var buf bytes.Buffer
+		if jsonErr := json.Indent(&buf, []byte(content.HTML), "", "  "); jsonErr != nil {
+			buf.Reset()
+			buf.WriteString(strings.TrimSpace(content.HTML))
+		}
+		body = "```json\n" + buf.String() + "\n```"
+	case FormatXML:
+		body = "```xml\n" + strings.TrimSpace(content.HTML) + "\n```"
+	case FormatText:
+		body = strings.TrimSpace(content.HTML)
+	default:
+		return fmt.Errorf("unsupported document format: %s", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to convert %s: %w", format, err)
+	}
+
+	content.Document = doc
+	content.Title = doc.Metadata["title"]
+	content.Text = frontMatter(doc) + body
+	return nil
+}
+
+// pdfInfoKeys maps PDF document info entries to front matter keys, in output order.
+var pdfInfoKeys = []struct{ pdf, key string }{
+	{"Title", "title"},
+	{"Author", "author"},
+	{"Subject", "subject"},
+	{"Keywords", "keywords"},
+	{"Creator", "creator"},
+	{"Producer", "producer"},
+	{"CreationDate", "created"},
+	{"ModDate", "modified"},
+}
+
+// convertPDF extracts the text of each page and the document info of a PDF.
+func convertPDF(data []byte, doc *Document) (text string, err error) {
+	// The PDF reader panics on some malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed PDF: %v", r)
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	info := r.Trailer().Key("Info")
+	for _, k := range pdfInfoKeys {
+		value := strings.TrimSpace(info.Key(k.pdf).Text())
+		if value == "" {
+			continue
+		}
+		if strings.HasPrefix(k.pdf, "Mod") || strings.HasPrefix(k.pdf, "Creation") {
+			value = pdfDate(value)
+		}
+		if doc.Metadata == nil {
+			doc.Metadata = make(map[string]string)
+		}
+		doc.Metadata[k.key] = value
+	}
+
+	doc.Pages = r.NumPage()
+	fonts := make(map[string]*pdf.Font)
+	var sb strings.Builder
+	for i := 1; i <= doc.Pages; i++ {
+		page := r.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		for _, name := range page.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				f := page.Font(name)
+				fonts[name] = &f
+			}
+		}
+		pageText, err := page.GetPlainText(fonts)
+		if err != nil {
+			return "", fmt.Errorf("page %d: %w", i, err)
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "## Page %d\n\n%s", i, cleanLines(pageText))
+	}
+	return sb.String(), nil
+}
+
+// cleanLines normalizes whitespace within each line and drops blank lines.
+func cleanLines(s string) string {
+	var lines []string
+	for line := range strings.SplitSeq(s, "\n") {
+		if line = cleanText(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pdfDate converts a PDF date string (D:YYYYMMDDHHmmSS...) to RFC 3339,
+// returning the input unchanged if it can't be parsed.
+func pdfDate(s string) string {
+	digits := strings.TrimPrefix(s, "D:")
+	for _, layout := range []string{"20060102150405", "200601021504", "20060102"} {
+		if len(digits) >= len(layout) {
+			if t, err := time.Parse(layout, digits[:len(layout)]); err == nil {
+				return t.Format(time.RFC3339)
+			}
+		}
+	}
+	return s
+}
+
+// frontMatter renders a document's format, page count and metadata as a YAML
+// front matter block.
+func frontMatter(doc *Document) string {
+	var sb strings.Builder
+	sb.WriteString("---\nformat: " + string(doc.Format) + "\n")
+	if doc.Pages > 0 {
+		fmt.Fprintf(&sb, "pages: %d\n", doc.Pages)
+	}
+	for _, k := range pdfInfoKeys {
+		if value, ok := doc.Metadata[k.key]; ok {
+			value = strings.Join(strings.Fields(value), " ")
+			fmt.Fprintf(&sb, "%s: %q\n", k.key, value)
+		}
+	}
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
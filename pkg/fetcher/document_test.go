@@ -0,0 +1,139 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// buildPDF returns a minimal PDF with one page per entry in pages and the
+// given document title.
+func buildPDF(title string, pages ...string) []byte {
+	var objects []string
+	objects = append(objects, "<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	objects = append(objects,
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Title (%s) /Author (ACME Ltd) /CreationDate (D:20240315093000Z) >>", title),
+	)
+	for i, text := range pages {
+		stream := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 6+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 4 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// --- Document Conversion Tests ---
+
+func TestDetectDocumentFormat(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		want        DocumentFormat
+	}{
+		{"text/html; charset=utf-8", "<html></html>", ""},
+		{"", "<html></html>", ""},
+		{"application/pdf", "", FormatPDF},
+		{"application/octet-stream", "%PDF-1.7\n...", FormatPDF},
+		{"application/json", "{}", FormatJSON},
+		{"application/ld+json", "{}", FormatJSON},
+		{"text/xml; charset=utf-8", "<a/>", FormatXML},
+		{"application/rss+xml", "<rss/>", FormatXML},
+		{"application/xhtml+xml", "<html/>", ""},
+		{"text/plain", "hello", FormatText},
+		{"image/png", "\x89PNG", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectDocumentFormat(tt.contentType, []byte(tt.body)); got != tt.want {
+			t.Errorf("DetectDocumentFormat(%q) = %q, want %q", tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestConvertDocument_PDF(t *testing.T) {
+	content := Content{HTML: string(buildPDF("Widget Spec Sheet", "Max load 250 kg", "Warranty 5 years"))}
+	if err := ConvertDocument(&content, FormatPDF); err != nil {
+		t.Fatalf("ConvertDocument() error = %v", err)
+	}
+
+	if content.Document == nil || content.Document.Pages != 2 {
+		t.Fatalf("Document = %+v, want 2 pages", content.Document)
+	}
+	if content.Title != "Widget Spec Sheet" {
+		t.Errorf("Title = %q", content.Title)
+	}
+	for _, want := range []string{
+		"format: pdf\npages: 2\n",
+		`title: "Widget Spec Sheet"`,
+		`author: "ACME Ltd"`,
+		`created: "2024-03-15T09:30:00Z"`,
+		"## Page 1\n\nMax load 250 kg",
+		"## Page 2\n\nWarranty 5 years",
+	} {
+		if !strings.Contains(content.Text, want) {
+			t.Errorf("converted text missing %q:\n%s", want, content.Text)
+		}
+	}
+}
+
+func TestConvertDocument_MalformedPDF(t *testing.T) {
+	content := Content{HTML: "%PDF-1.4\nnot really a pdf"}
+	if err := ConvertDocument(&content, FormatPDF); err == nil {
+		t.Error("ConvertDocument() should fail on a malformed PDF")
+	}
+}
+
+func TestConvertDocument_JSON(t *testing.T) {
+	content := Content{HTML: `{"name":"Widget","price":9.99}`}
+	if err := ConvertDocument(&content, FormatJSON); err != nil {
+		t.Fatalf("ConvertDocument() error = %v", err)
+	}
+	want := "---\nformat: json\n---\n\n```json\n{\n  \"name\": \"Widget\",\n  \"price\": 9.99\n}\n```"
+	if content.Text != want {
+		t.Errorf("Text = %q, want %q", content.Text, want)
+	}
+}
+
+func TestStaticFetcher_ConvertsPDF(t *testing.T) {
+	body := buildPDF("Brochure", "Three bedroom house")
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write(body)
+	})
+
+	content, err := NewStatic(StaticConfig{}).Fetch(context.Background(), srv.URL+"/brochure.pdf", Options{})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if content.Document == nil || content.Document.Format != FormatPDF {
+		t.Fatalf("Document = %+v, want a PDF", content.Document)
+	}
+	if !strings.Contains(content.Text, "## Page 1\n\nThree bedroom house") {
+		t.Errorf("Text = %q", content.Text)
+	}
+}
@@ -6,6 +6,8 @@ package fetcher
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -25,10 +27,25 @@ type Fetcher interface {
 type Options struct {
 	UserAgent       string
 	Timeout         time.Duration
-	WaitForSelector string        // CSS selector to wait for (dynamic fetchers)
-	WaitDuration    time.Duration // Additional wait after load
+	WaitForSelector string         // CSS selector to wait for (dynamic fetchers)
+	WaitDuration    time.Duration  // Additional wait after load
+	Actions         []Action       // Browser actions run after load (dynamic fetchers; see ActionConfig)
+	Capture         *CaptureConfig // Record matching XHR/fetch responses in Content.Resources (dynamic fetchers)
 	Headers         map[string]string
 	Cookies         []Cookie
+
+	// Images for vision extraction
+	Screenshot *ScreenshotConfig // Capture a screenshot (and page images) into Content (dynamic fetchers; nil = off)
+
+	// Request body (see RequestBody; default: a GET with no body)
+	Method      string     // HTTP method (default: GET, or POST with a body or form values)
+	Body        string     // Raw request body, e.g. a JSON search query
+	ContentType string     // Content-Type of the body (default for form values: application/x-www-form-urlencoded)
+	FormValues  url.Values // Form fields sent URL-encoded as the body; take precedence over Body
+
+	// Response limits (static fetcher; zero values use the fetcher's config)
+	MaxBodyBytes        int64    // Larger responses fail with ErrTooLarge
+	AllowedContentTypes []string // Media types or "type/*" wildcards; others fail with ErrUnsupportedContentType
 }
 
 // Cookie represents an HTTP cookie.
@@ -46,8 +63,40 @@ type Content struct {
 	Title       string
 	StatusCode  int
 	ContentType string
+	Charset     string // Character encoding the body was decoded from (e.g. "shift_jis"); HTML and Text are always UTF-8
+	Body        []byte // Response body as received, before charset decoding (nil if the fetcher doesn't keep it); archived by RecordingFetcher
 	FetchedAt   time.Time
-	Links       []string // Links found on the page
+	Links       []string    // Links found on the page
+	Headers     http.Header // Response headers (nil if the fetcher cannot expose them)
+	FromCache   bool        // True when served from a cache rather than the network
+	Attempts    []Attempt   // One entry per request made, including retries
+	Document    *Document   // Set when a non-HTML body (PDF, JSON, ...) was converted to Markdown in Text
+	FetchedBy   string      // Type of the fetcher that produced the content when chosen per page (see AutoFetcher)
+	Resources   []Resource  // XHR/fetch responses captured while rendering (see Options.Capture)
+
+	// Redirects
+	FinalURL      string     // URL the content was served from after redirects (empty if unknown)
+	RedirectChain []Redirect // Redirects followed to reach FinalURL, in order (nil if none)
+
+	// Images (see Options.Screenshot)
+	Screenshot *Image  // Full-page screenshot
+	Images     []Image // Images from the page, downscaled
+}
+
+// Redirect is one hop in a redirect chain: URL answered with StatusCode and
+// pointed to the next hop (or the final URL).
+type Redirect struct {
+	URL        string
+	StatusCode int
+}
+
+// PageURL returns the URL the content was served from, falling back to the
+// requested URL when the fetcher didn't report a final one.
+func (c Content) PageURL() string {
+	if c.FinalURL != "" {
+		return c.FinalURL
+	}
+	return c.URL
 }
 
 // Error types for distinguishing failure reasons.
@@ -59,4 +108,11 @@ var (
 	ErrAntiBot = errors.New("anti-bot protection detected")
 	// ErrChallengeTimeout indicates a timeout while waiting for challenge to resolve.
 	ErrChallengeTimeout = errors.New("challenge timeout")
+	// ErrUnsupportedContentType indicates the response's content type is not
+	// one the caller accepts (see Options.AllowedContentTypes and
+	// ContentTypeMiddleware).
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+	// ErrTooLarge indicates the response body exceeds the size limit (see
+	// Options.MaxBodyBytes).
+	ErrTooLarge = errors.New("response too large")
 )
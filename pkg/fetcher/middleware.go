@@ -0,0 +1,199 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"mime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Middleware wraps a Fetcher to add behaviour around every fetch (headers,
+// timeouts, metrics, filtering, ...) without changing the fetcher itself.
+type Middleware func(Fetcher) Fetcher
+
+// Chain wraps base in the given middleware. The first middleware is the
+// outermost: it sees each request first and each response last.
+//
+// Example:
+//
+//	f := fetcher.Chain(fetcher.NewStatic(fetcher.StaticConfig{}),
+//	    fetcher.TimeoutMiddleware(time.Minute),
+//	    fetcher.HeaderMiddleware(map[string]string{"Accept-Language": "en"}),
+//	)
+func Chain(base Fetcher, mws ...Middleware) Fetcher {
+	f := base
+	for i := len(mws) - 1; i >= 0; i-- {
+		f = mws[i](f)
+	}
+	return f
+}
+
+// FetchFunc is a fetch that continues the chain by calling next.
+type FetchFunc func(ctx context.Context, url string, opts Options, next Fetcher) (Content, error)
+
+// MiddlewareFunc returns a Middleware that runs fn in place of the wrapped
+// fetcher's Fetch. Close and Type pass through to the wrapped fetcher.
+func MiddlewareFunc(fn FetchFunc) Middleware {
+	return func(next Fetcher) Fetcher {
+		return &middlewareFetcher{next: next, fetch: fn}
+	}
+}
+
+// middlewareFetcher is the Fetcher a MiddlewareFunc returns.
+type middlewareFetcher struct {
+	next  Fetcher
+	fetch FetchFunc
+}
+
+// Fetch runs the middleware's fetch.
+func (f *middlewareFetcher) Fetch(ctx context.Context, targetURL string, opts Options) (Content, error) {
+	return f.fetch(ctx, targetURL, opts, f.next)
+}
+
+// Close closes the wrapped fetcher.
+func (f *middlewareFetcher) Close() error {
+	return f.next.Close()
+}
+
+// Unwrap returns the wrapped fetcher.
+func (f *middlewareFetcher) Unwrap() Fetcher {
+	return f.next
+}
+
+// Type returns the wrapped fetcher's type; middleware is transparent.
+func (f *middlewareFetcher) Type() string {
+	return f.next.Type()
+}
+
+// HeaderMiddleware adds headers to every request. Headers already set in the
+// request's Options take precedence.
+func HeaderMiddleware(headers map[string]string) Middleware {
+	headers = maps.Clone(headers)
+	return MiddlewareFunc(func(ctx context.Context, url string, opts Options, next Fetcher) (Content, error) {
+		merged := make(map[string]string, len(headers)+len(opts.Headers))
+		maps.Copy(merged, headers)
+		maps.Copy(merged, opts.Headers)
+		opts.Headers = merged
+		return next.Fetch(ctx, url, opts)
+	})
+}
+
+// UserAgentMiddleware sets the user agent of requests that don't set one.
+func UserAgentMiddleware(userAgent string) Middleware {
+	return MiddlewareFunc(func(ctx context.Context, url string, opts Options, next Fetcher) (Content, error) {
+		if opts.UserAgent == "" {
+			opts.UserAgent = userAgent
+		}
+		return next.Fetch(ctx, url, opts)
+	})
+}
+
+// TimeoutMiddleware bounds each fetch, including any retries the wrapped
+// fetcher makes, to d. Options.Timeout still limits each attempt.
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return MiddlewareFunc(func(ctx context.Context, url string, opts Options, next Fetcher) (Content, error) {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		return next.Fetch(ctx, url, opts)
+	})
+}
+
+// ContentTypeMiddleware fails fetches whose content type isn't one of types,
+// with an error wrapping ErrUnsupportedContentType. Types are media types
+// ("text/html") or type wildcards ("text/*"). Content with no content type
+// is let through.
+func ContentTypeMiddleware(types ...string) Middleware {
+	return MiddlewareFunc(func(ctx context.Context, url string, opts Options, next Fetcher) (Content, error) {
+		content, err := next.Fetch(ctx, url, opts)
+		if err != nil || content.ContentType == "" {
+			return content, err
+		}
+		if mediaType := mediaTypeOf(content.ContentType); !matchesMediaType(types, mediaType) {
+			return content, fmt.Errorf("%w: %s", ErrUnsupportedContentType, mediaType)
+		}
+		return content, nil
+	})
+}
+
+// mediaTypeOf returns the lower-cased media type of a Content-Type value,
+// without parameters.
+func mediaTypeOf(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+		return strings.ToLower(strings.TrimSpace(mediaType))
+	}
+	return mediaType
+}
+
+// matchesMediaType reports whether mediaType is one of types, which may
+// include type wildcards ("text/*").
+func matchesMediaType(types []string, mediaType string) bool {
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == mediaType || t == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(t, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchStats summarizes the fetches a MetricsMiddleware has seen.
+type FetchStats struct {
+	Fetches     int           // Fetches made, including failed ones
+	Errors      int           // Fetches that returned an error
+	FromCache   int           // Fetches served from a cache
+	Bytes       int64         // Total size of the bodies fetched
+	Duration    time.Duration // Total time spent fetching
+	StatusCodes map[int]int   // Fetches per status code (0 = no response)
+}
+
+// FetchMetrics collects FetchStats. It is safe for concurrent use.
+type FetchMetrics struct {
+	mu    sync.Mutex
+	stats FetchStats
+}
+
+// Stats returns a snapshot of the metrics collected so far.
+func (m *FetchMetrics) Stats() FetchStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.stats
+	stats.StatusCodes = maps.Clone(m.stats.StatusCodes)
+	return stats
+}
+
+// record adds one fetch to the metrics.
+func (m *FetchMetrics) record(content Content, err error, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats.Fetches++
+	if err != nil {
+		m.stats.Errors++
+	}
+	if content.FromCache {
+		m.stats.FromCache++
+	}
+	m.stats.Bytes += int64(len(content.HTML))
+	m.stats.Duration += elapsed
+	if m.stats.StatusCodes == nil {
+		m.stats.StatusCodes = make(map[int]int)
+	}
+	m.stats.StatusCodes[content.StatusCode]++
+}
+
+// MetricsMiddleware records every fetch in m.
+func MetricsMiddleware(m *FetchMetrics) Middleware {
+	return MiddlewareFunc(func(ctx context.Context, url string, opts Options, next Fetcher) (Content, error) {
+		start := time.Now()
+		content, err := next.Fetch(ctx, url, opts)
+		m.record(content, err, time.Since(start))
+		return content, err
+	})
+}
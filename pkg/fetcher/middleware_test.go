@@ -0,0 +1,143 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+// --- Middleware Tests ---
+
+func TestChain_Order(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return MiddlewareFunc(func(ctx context.Context, url string, opts Options, next Fetcher) (Content, error) {
+			order = append(order, name+" in")
+			content, err := next.Fetch(ctx, url, opts)
+			order = append(order, name+" out")
+			return content, err
+		})
+	}
+
+	base := &stubFetcher{}
+	f := Chain(base, trace("outer"), trace("inner"))
+	if _, err := f.Fetch(context.Background(), "https://example.com/", Options{}); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	want := []string{"outer in", "inner in", "inner out", "outer out"}
+	if !slices.Equal(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+	if f.Type() != "dynamic" {
+		t.Errorf("Type() = %q, want the base fetcher's type", f.Type())
+	}
+	if inner, ok := unwrapAs[*stubFetcher](f); !ok || inner != base {
+		t.Error("Unwrap should reach the base fetcher")
+	}
+	if Chain(base) != base {
+		t.Error("Chain with no middleware should return the base fetcher")
+	}
+}
+
+func TestHeaderAndUserAgentMiddleware(t *testing.T) {
+	var got http.Header
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte(`<html><body>ok</body></html>`))
+	})
+
+	f := Chain(NewStatic(StaticConfig{}),
+		HeaderMiddleware(map[string]string{"Accept-Language": "en-GB", "X-Team": "crawl"}),
+		UserAgentMiddleware("refyne-test/1.0"),
+	)
+	opts := Options{Headers: map[string]string{"X-Team": "override"}}
+	if _, err := f.Fetch(context.Background(), srv.URL, opts); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if got.Get("Accept-Language") != "en-GB" {
+		t.Errorf("Accept-Language = %q, want en-GB", got.Get("Accept-Language"))
+	}
+	if got.Get("X-Team") != "override" {
+		t.Errorf("X-Team = %q, want the request's own header to win", got.Get("X-Team"))
+	}
+	if got.Get("User-Agent") != "refyne-test/1.0" {
+		t.Errorf("User-Agent = %q, want refyne-test/1.0", got.Get("User-Agent"))
+	}
+	if len(opts.Headers) != 1 {
+		t.Errorf("caller's headers were modified: %v", opts.Headers)
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	slow := MiddlewareFunc(func(ctx context.Context, url string, opts Options, next Fetcher) (Content, error) {
+		<-ctx.Done()
+		return Content{}, ctx.Err()
+	})
+	f := Chain(&stubFetcher{}, TimeoutMiddleware(10*time.Millisecond), slow)
+
+	if _, err := f.Fetch(context.Background(), "https://example.com/", Options{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Fetch() error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestContentTypeMiddleware(t *testing.T) {
+	tests := []struct {
+		contentType string
+		allowed     bool
+	}{
+		{"text/html; charset=utf-8", true},
+		{"TEXT/HTML", true},
+		{"text/plain", true}, // text/*
+		{"application/pdf", false},
+		{"image/png", false},
+		{"", true}, // unknown: let through
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			base := MiddlewareFunc(func(ctx context.Context, url string, opts Options, next Fetcher) (Content, error) {
+				return Content{URL: url, ContentType: tt.contentType}, nil
+			})
+			f := Chain(&stubFetcher{}, ContentTypeMiddleware("text/html", "text/*"), base)
+
+			_, err := f.Fetch(context.Background(), "https://example.com/", Options{})
+			if tt.allowed && err != nil {
+				t.Errorf("Fetch() error = %v, want allowed", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrUnsupportedContentType) {
+				t.Errorf("Fetch() error = %v, want ErrUnsupportedContentType", err)
+			}
+		})
+	}
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body>ok</body></html>`))
+	})
+
+	metrics := &FetchMetrics{}
+	f := Chain(NewStatic(StaticConfig{}), MetricsMiddleware(metrics))
+	for _, path := range []string{"/a", "/b", "/missing"} {
+		_, _ = f.Fetch(context.Background(), srv.URL+path, Options{})
+	}
+
+	stats := metrics.Stats()
+	if stats.Fetches != 3 || stats.Errors != 1 {
+		t.Errorf("Fetches = %d, Errors = %d; want 3 and 1", stats.Fetches, stats.Errors)
+	}
+	if stats.StatusCodes[http.StatusOK] != 2 || stats.StatusCodes[http.StatusNotFound] != 1 {
+		t.Errorf("StatusCodes = %v, want two 200s and one 404", stats.StatusCodes)
+	}
+	if stats.Bytes == 0 || stats.Duration <= 0 {
+		t.Errorf("Bytes = %d, Duration = %v; want both recorded", stats.Bytes, stats.Duration)
+	}
+}
@@ -0,0 +1,53 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+)
+
+// RecordingFetcher wraps another Fetcher and archives every successful fetch
+// to a WARC file as a request/response record pair.
+type RecordingFetcher struct {
+	next   Fetcher
+	writer *WARCWriter
+}
+
+// NewRecording wraps next so that every fetch is written to w.
+// The RecordingFetcher takes ownership of w and closes it on Close.
+func NewRecording(next Fetcher, w *WARCWriter) *RecordingFetcher {
+	return &RecordingFetcher{next: next, writer: w}
+}
+
+// Fetch retrieves the page from the wrapped fetcher and records the exchange.
+// Failed fetches are not recorded. Recording errors are logged, not returned,
+// so a full disk doesn't abort an otherwise healthy crawl.
+func (f *RecordingFetcher) Fetch(ctx context.Context, targetURL string, opts Options) (Content, error) {
+	content, err := f.next.Fetch(ctx, targetURL, opts)
+	if err != nil {
+		return content, err
+	}
+
+	if err := f.writer.WriteExchange(targetURL, opts, content); err != nil {
+		logger.Warn("failed to record WARC exchange", "url", targetURL, "error", err)
+	} else {
+		logger.Debug("recorded WARC exchange", "url", targetURL, "status", content.StatusCode, "body_size", len(content.HTML))
+	}
+	return content, nil
+}
+
+// Close closes the wrapped fetcher and the WARC writer.
+func (f *RecordingFetcher) Close() error {
+	return errors.Join(f.next.Close(), f.writer.Close())
+}
+
+// Unwrap returns the wrapped fetcher.
+func (f *RecordingFetcher) Unwrap() Fetcher {
+	return f.next
+}
+
+// Type returns the wrapped fetcher's type; recording is transparent.
+func (f *RecordingFetcher) Type() string {
+	return f.next.Type()
+}
@@ -0,0 +1,252 @@
+package fetcher
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Registered to read archived screenshots' dimensions
+	_ "image/png"
+	"io"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+)
+
+// ErrNotArchived is returned by ReplayFetcher when the URL has no response record.
+var ErrNotArchived = errors.New("URL not found in archive")
+
+// ReplayFetcher serves pages from a WARC archive with no network access.
+// It implements the Fetcher interface, so the crawler, cleaner and extractor
+// run unchanged against a frozen snapshot.
+//
+// The archive is indexed into memory when the fetcher is created. Responses
+// are matched on the method, URL and body of the request that produced them
+// (see RequestKey), so POSTs to one endpoint replay separately; request headers
+// are not compared. If a request was recorded more than once, the last
+// response wins. What was recorded alongside a response (see
+// WARCWriter.WriteExchange) is restored with it.
+type ReplayFetcher struct {
+	responses map[string]*WARCRecord   // request key -> response record
+	siblings  map[string][]*WARCRecord // response record ID -> records written alongside it, in order
+	requests  map[string]Options       // response record ID -> request
+}
+
+// NewReplay loads the WARC file at path (optionally gzip-compressed).
+func NewReplay(path string) (*ReplayFetcher, error) {
+	file, err := os.Open(path) //#nosec G304 -- caller-specified archive path
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WARC file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return NewReplayFromReader(file)
+}
+
+// NewReplayFromReader loads WARC records from r.
+func NewReplayFromReader(r io.Reader) (*ReplayFetcher, error) {
+	reader, err := NewWARCReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request records follow their responses, so match them up once the
+	// whole archive has been read
+	var responses []*WARCRecord
+	siblings := make(map[string][]*WARCRecord)
+	requests := make(map[string]Options)
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if record.TargetURI() == "" {
+			continue
+		}
+		concurrentTo := record.Header.Get("WARC-Concurrent-To")
+		switch record.Type() {
+		case WARCTypeResponse:
+			if concurrentTo != "" {
+				siblings[concurrentTo] = append(siblings[concurrentTo], record) // Captured while rendering a page
+				continue
+			}
+			responses = append(responses, record)
+		case WARCTypeMetadata, WARCTypeResource:
+			if concurrentTo != "" {
+				siblings[concurrentTo] = append(siblings[concurrentTo], record)
+			}
+		case WARCTypeRequest:
+			if opts, ok := archivedRequest(record); ok {
+				requests[concurrentTo] = opts
+			}
+		}
+	}
+
+	f := &ReplayFetcher{
+		responses: make(map[string]*WARCRecord, len(responses)),
+		siblings:  siblings,
+		requests:  requests,
+	}
+	for _, record := range responses {
+		opts := requests[record.Header.Get("WARC-Record-ID")] // a GET if there's no request record
+		f.responses[RequestKey(normalizeCacheURL(record.TargetURI()), opts, nil)] = record
+	}
+
+	logger.Debug("replay archive loaded", "responses", len(f.responses))
+	return f, nil
+}
+
+// Fetch returns the archived response for targetURL.
+func (f *ReplayFetcher) Fetch(ctx context.Context, targetURL string, opts Options) (Content, error) {
+	result := Content{URL: targetURL}
+
+	record, ok := f.responses[RequestKey(normalizeCacheURL(targetURL), opts, nil)]
+	if !ok {
+		return result, fmt.Errorf("%w: %s", ErrNotArchived, targetURL)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(record.Block)), nil)
+	if err != nil {
+		return result, fmt.Errorf("invalid archived response for %s: %w", targetURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result, fmt.Errorf("invalid archived response body for %s: %w", targetURL, err)
+	}
+	decompressed := body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		if decompressed, err = gunzip(body); err != nil {
+			return result, fmt.Errorf("invalid archived response body for %s: %w", targetURL, err)
+		}
+	}
+
+	result.FetchedAt = record.Date()
+	result.StatusCode = resp.StatusCode
+	result.ContentType = resp.Header.Get("Content-Type")
+	result.Headers = resp.Header
+	result.Body = body
+	result.HTML, result.Charset = DecodeBody(decompressed, result.ContentType)
+	f.restoreSiblings(&result, record.Header.Get("WARC-Record-ID"))
+
+	if result.HTML != "" {
+		if err := parseBody(&result); err != nil {
+			return result, fmt.Errorf("failed to parse content: %w", err)
+		}
+	}
+
+	logger.Debug("replay fetch complete", "url", targetURL, "status", result.StatusCode, "body_size", len(body))
+	return result, nil
+}
+
+// restoreSiblings restores what was recorded alongside the response record
+// responseID: the final URL and redirects, the captured resources, and the
+// screenshot and images.
+func (f *ReplayFetcher) restoreSiblings(content *Content, responseID string) {
+	for _, record := range f.siblings[responseID] {
+		switch record.Type() {
+		case WARCTypeMetadata:
+			fields, err := textproto.NewReader(bufio.NewReader(io.MultiReader(bytes.NewReader(record.Block), strings.NewReader("\r\n")))).ReadMIMEHeader()
+			if err != nil {
+				logger.Debug("skipping invalid archived metadata", "url", content.URL, "error", err)
+				continue
+			}
+			content.FinalURL = fields.Get(warcFieldFinalURL)
+			for _, hop := range fields.Values(warcFieldRedirect) {
+				status, hopURL, _ := strings.Cut(hop, " ")
+				code, _ := strconv.Atoi(status)
+				content.RedirectChain = append(content.RedirectChain, Redirect{URL: hopURL, StatusCode: code})
+			}
+		case WARCTypeResponse:
+			if res, ok := f.archivedResource(record); ok {
+				content.Resources = append(content.Resources, res)
+			}
+		case WARCTypeResource:
+			img := Image{MediaType: record.Header.Get("Content-Type"), Data: record.Block}
+			if cfg, _, err := image.DecodeConfig(bytes.NewReader(record.Block)); err == nil {
+				img.Width, img.Height = cfg.Width, cfg.Height
+			}
+			if strings.HasPrefix(record.TargetURI(), warcScreenshotPrefix) {
+				content.Screenshot = &img
+			} else {
+				img.URL = record.TargetURI()
+				content.Images = append(content.Images, img)
+			}
+		}
+	}
+}
+
+// archivedResource returns the resource captured in an archived response.
+func (f *ReplayFetcher) archivedResource(record *WARCRecord) (Resource, bool) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(record.Block)), nil)
+	if err != nil {
+		return Resource{}, false
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Resource{}, false
+	}
+	return Resource{
+		URL:         record.TargetURI(),
+		Method:      cmp.Or(f.requests[record.Header.Get("WARC-Record-ID")].Method, http.MethodGet),
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+	}, true
+}
+
+// gunzip decompresses a gzip-encoded body.
+func gunzip(body []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = gz.Close() }()
+	return io.ReadAll(gz)
+}
+
+// archivedRequest returns the method and body of an archived request.
+func archivedRequest(record *WARCRecord) (Options, bool) {
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(record.Block)))
+	if err != nil {
+		return Options{}, false
+	}
+	defer func() { _ = req.Body.Close() }()
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return Options{}, false
+	}
+	return Options{
+		Method:      req.Method,
+		Body:        string(body),
+		ContentType: req.Header.Get("Content-Type"),
+	}, true
+}
+
+// Len returns the number of archived responses.
+func (f *ReplayFetcher) Len() int {
+	return len(f.responses)
+}
+
+// Close releases resources.
+func (f *ReplayFetcher) Close() error {
+	return nil
+}
+
+// Type returns the fetcher type.
+func (f *ReplayFetcher) Type() string {
+	return "replay"
+}
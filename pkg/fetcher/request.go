@@ -0,0 +1,158 @@
+package fetcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// formContentType is the content type of URL-encoded form submissions.
+const formContentType = "application/x-www-form-urlencoded"
+
+// RequestBody returns the method, content type and body to send. Form values
+// are URL-encoded and take precedence over Body. A request with a body
+// defaults to POST; one without defaults to GET.
+func (o Options) RequestBody() (method, contentType string, body []byte) {
+	method, contentType = strings.ToUpper(o.Method), o.ContentType
+	switch {
+	case len(o.FormValues) > 0:
+		body = []byte(o.FormValues.Encode())
+		if contentType == "" {
+			contentType = formContentType
+		}
+	case o.Body != "":
+		body = []byte(o.Body)
+	}
+	if method == "" {
+		method = http.MethodGet
+		if body != nil {
+			method = http.MethodPost
+		}
+	}
+	return method, contentType, body
+}
+
+// RequestKey identifies a request for deduplication and replay. A plain GET is
+// keyed by its normalized URL alone; anything else by its method, URL and a
+// digest of its content type, body and the given headers.
+func RequestKey(normalizedURL string, opts Options, headers map[string]string) string {
+	method, contentType, body := opts.RequestBody()
+	if method == http.MethodGet && body == nil && len(headers) == 0 {
+		return normalizedURL
+	}
+	h := sha256.New()
+	h.Write([]byte(contentType + "\n"))
+	h.Write(body)
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		h.Write([]byte("\n" + name + ": " + headers[name]))
+	}
+	return method + " " + normalizedURL + " " + hex.EncodeToString(h.Sum(nil))
+}
+
+// Request is a fetch that is more than a GET of a URL, such as a search form
+// submission or a JSON query to a search endpoint. Crawl seeds can be given as
+// requests (see LoadRequests).
+type Request struct {
+	URL         string            `yaml:"url" json:"url"`
+	Method      string            `yaml:"method,omitempty" json:"method,omitempty"` // Default: GET, or POST with a body or form
+	Body        string            `yaml:"body,omitempty" json:"body,omitempty"`
+	ContentType string            `yaml:"content_type,omitempty" json:"content_type,omitempty"`
+	Form        map[string]string `yaml:"form,omitempty" json:"form,omitempty"` // URL-encoded form fields; $VAR and ${VAR} are expanded from the environment
+	Headers     map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// UnmarshalYAML accepts a plain URL as well as a request mapping, so request
+// lists can mix both.
+func (r *Request) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*r = Request{URL: node.Value}
+		return nil
+	}
+	type plain Request
+	return node.Decode((*plain)(r))
+}
+
+// Validate checks the request's URL and method, and that it doesn't set both
+// a body and a form.
+func (r Request) Validate() error {
+	u, err := url.Parse(r.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid request URL %q", r.URL)
+	}
+	if r.Method != "" && strings.ContainsAny(r.Method, " \t\r\n/") {
+		return fmt.Errorf("invalid request method %q", r.Method)
+	}
+	if r.Body != "" && len(r.Form) > 0 {
+		return errors.New("request must not set both body and form")
+	}
+	return nil
+}
+
+// IsGet reports whether the request is a plain GET of its URL.
+func (r Request) IsGet() bool {
+	return (r.Method == "" || strings.EqualFold(r.Method, http.MethodGet)) &&
+		r.Body == "" && len(r.Form) == 0 && len(r.Headers) == 0
+}
+
+// Apply returns opts with the request's method, body, form and headers set.
+// The request's headers take precedence over those already in opts.
+func (r Request) Apply(opts Options) Options {
+	opts.Method = r.Method
+	opts.Body = r.Body
+	opts.ContentType = r.ContentType
+	opts.FormValues = nil
+	if len(r.Form) > 0 {
+		opts.FormValues = make(url.Values, len(r.Form))
+		for name, value := range r.Form {
+			opts.FormValues.Set(name, os.ExpandEnv(value))
+		}
+	}
+	if len(r.Headers) > 0 {
+		headers := make(map[string]string, len(opts.Headers)+len(r.Headers))
+		maps.Copy(headers, opts.Headers)
+		maps.Copy(headers, r.Headers)
+		opts.Headers = headers
+	}
+	return opts
+}
+
+// LoadRequests reads a YAML or JSON list of requests. Entries are either a
+// URL or a request mapping:
+//
+//	# searches.yaml
+//	- https://example.com/listings
+//	- url: https://example.com/search
+//	  form: {q: "garden chairs", sort: newest}
+//	- url: https://api.example.com/search
+//	  body: '{"query": "garden chairs"}'
+//	  content_type: application/json
+func LoadRequests(path string) ([]Request, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- caller-specified request list
+	if err != nil {
+		return nil, fmt.Errorf("failed to read requests: %w", err)
+	}
+	var requests []Request
+	if err := yaml.Unmarshal(data, &requests); err != nil {
+		return nil, fmt.Errorf("failed to parse requests: %w", err)
+	}
+	for i, r := range requests {
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("request %d: %w", i+1, err)
+		}
+	}
+	return requests, nil
+}
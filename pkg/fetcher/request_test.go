@@ -0,0 +1,109 @@
+package fetcher
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// --- Request Tests ---
+
+func TestOptions_RequestBody(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            Options
+		wantMethod      string
+		wantContentType string
+		wantBody        string
+	}{
+		{"plain GET", Options{}, http.MethodGet, "", ""},
+		{"form posts", Options{FormValues: url.Values{"q": {"garden chairs"}}}, http.MethodPost, "application/x-www-form-urlencoded", "q=garden+chairs"},
+		{"form wins over body", Options{Body: "ignored", FormValues: url.Values{"q": {"x"}}}, http.MethodPost, "application/x-www-form-urlencoded", "q=x"},
+		{"body posts", Options{Body: `{"q":"x"}`, ContentType: "application/json"}, http.MethodPost, "application/json", `{"q":"x"}`},
+		{"explicit method", Options{Method: "put", Body: "data"}, http.MethodPut, "", "data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, contentType, body := tt.opts.RequestBody()
+			if method != tt.wantMethod || contentType != tt.wantContentType || string(body) != tt.wantBody {
+				t.Errorf("RequestBody() = %q, %q, %q; want %q, %q, %q", method, contentType, body, tt.wantMethod, tt.wantContentType, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestStaticFetcher_PostRequests(t *testing.T) {
+	type seen struct{ method, contentType, body string }
+	var got seen
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = seen{r.Method, r.Header.Get("Content-Type"), string(body)}
+		_, _ = w.Write([]byte(`<html><body>results</body></html>`))
+	})
+	f := NewStatic(StaticConfig{})
+
+	t.Setenv("REFYNE_TEST_QUERY", "chairs")
+	req := Request{URL: srv.URL + "/search", Form: map[string]string{"q": "$REFYNE_TEST_QUERY"}}
+	if _, err := f.Fetch(context.Background(), req.URL, req.Apply(Options{})); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if want := (seen{http.MethodPost, "application/x-www-form-urlencoded", "q=chairs"}); got != want {
+		t.Errorf("form request = %+v, want %+v", got, want)
+	}
+
+	opts := Options{Body: `{"query":"chairs"}`, ContentType: "application/json"}
+	if _, err := f.Fetch(context.Background(), srv.URL+"/api/search", opts); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if want := (seen{http.MethodPost, "application/json", `{"query":"chairs"}`}); got != want {
+		t.Errorf("JSON request = %+v, want %+v", got, want)
+	}
+
+	// Different bodies are cached separately
+	if CacheKey(srv.URL, opts) == CacheKey(srv.URL, Options{Body: `{"query":"tables"}`, ContentType: "application/json"}) {
+		t.Error("CacheKey() should differ for different request bodies")
+	}
+}
+
+func TestLoadRequests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seeds.yaml")
+	data := `
+- https://example.com/listings
+- url: https://example.com/search
+  form: {q: garden chairs}
+- url: https://api.example.com/search
+  body: '{"query": "garden chairs"}'
+  content_type: application/json
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	requests, err := LoadRequests(path)
+	if err != nil {
+		t.Fatalf("LoadRequests() error = %v", err)
+	}
+	if len(requests) != 3 {
+		t.Fatalf("LoadRequests() returned %d requests, want 3", len(requests))
+	}
+	if !requests[0].IsGet() || requests[0].URL != "https://example.com/listings" {
+		t.Errorf("requests[0] = %+v, want a plain URL", requests[0])
+	}
+	if requests[1].Form["q"] != "garden chairs" || requests[1].IsGet() {
+		t.Errorf("requests[1] = %+v, want a form request", requests[1])
+	}
+	if requests[2].ContentType != "application/json" || requests[2].Body == "" {
+		t.Errorf("requests[2] = %+v, want a JSON request", requests[2])
+	}
+
+	if err := os.WriteFile(path, []byte("- url: https://example.com/\n  body: x\n  form: {q: y}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRequests(path); err == nil {
+		t.Error("LoadRequests() should reject a request with both body and form")
+	}
+}
@@ -0,0 +1,142 @@
+package fetcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// defaultMaxResourceSize caps a captured response body. Larger bodies are
+// skipped rather than truncated, since truncated JSON is useless.
+const defaultMaxResourceSize = 2 * 1024 * 1024
+
+// Resource is an XHR/fetch response that a page loaded while it was rendered.
+type Resource struct {
+	URL         string
+	Method      string
+	StatusCode  int
+	ContentType string
+	Body        string
+}
+
+// CaptureConfig selects the XHR/fetch responses a dynamic fetcher records in
+// Content.Resources. Many single-page apps load their data from JSON APIs, which
+// is cheaper and more reliable to extract from than the rendered DOM.
+type CaptureConfig struct {
+	URLPattern   string   // Regex matched against the request URL (empty = all)
+	ContentTypes []string // Media types to keep (default: JSON, including +json types)
+	MaxSize      int      // Max body size in bytes (default: 2 MiB)
+}
+
+// Validate checks the URL pattern.
+func (c CaptureConfig) Validate() error {
+	if _, err := regexp.Compile(c.URLPattern); err != nil {
+		return fmt.Errorf("invalid capture pattern: %w", err)
+	}
+	return nil
+}
+
+// Matches reports whether a response with this URL and Content-Type should be captured.
+func (c CaptureConfig) Matches(resourceURL, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	if len(c.ContentTypes) == 0 {
+		if mediaType != "application/json" && mediaType != "text/json" && !strings.HasSuffix(mediaType, "+json") {
+			return false
+		}
+	} else if !containsFold(c.ContentTypes, mediaType) {
+		return false
+	}
+	if c.URLPattern == "" {
+		return true
+	}
+	matched, _ := regexp.MatchString(c.URLPattern, resourceURL)
+	return matched
+}
+
+// MaxBodySize returns the body size limit, applying the default.
+func (c CaptureConfig) MaxBodySize() int {
+	if c.MaxSize > 0 {
+		return c.MaxSize
+	}
+	return defaultMaxResourceSize
+}
+
+// ResourceMode controls how captured resources are passed to the extractor.
+type ResourceMode string
+
+const (
+	// ResourcesIgnore extracts from the page only (captured resources are still
+	// available on Content.Resources).
+	ResourcesIgnore ResourceMode = ""
+	// ResourcesAppend extracts from the page followed by the captured resources.
+	ResourcesAppend ResourceMode = "append"
+	// ResourcesOnly extracts from the captured resources instead of the page,
+	// falling back to the page when nothing was captured.
+	ResourcesOnly ResourceMode = "only"
+)
+
+// ParseResourceMode parses "page", "append" or "only".
+func ParseResourceMode(s string) (ResourceMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "page", "ignore":
+		return ResourcesIgnore, nil
+	case "append", "both":
+		return ResourcesAppend, nil
+	case "only", "resources":
+		return ResourcesOnly, nil
+	default:
+		return "", fmt.Errorf("unknown resource mode %q (use page, append or only)", s)
+	}
+}
+
+// ExtractionInput combines the cleaned page content with the captured
+// resources according to mode.
+func ExtractionInput(page string, resources []Resource, mode ResourceMode) string {
+	if len(resources) == 0 || mode == ResourcesIgnore {
+		return page
+	}
+	formatted := FormatResources(resources)
+	if mode == ResourcesOnly {
+		return formatted
+	}
+	return page + "\n\n" + formatted
+}
+
+// FormatResources renders captured resources as Markdown, one section per
+// response with its JSON pretty-printed.
+func FormatResources(resources []Resource) string {
+	var sb strings.Builder
+	for i, r := range resources {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "## Resource: %s %s\n\n", coalesce(r.Method, "GET"), r.URL)
+
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(r.Body), "", "  "); err == nil {
+			sb.WriteString("```json\n")
+			sb.Write(buf.Bytes())
+		} else {
+			sb.WriteString("```\n")
+			sb.WriteString(strings.TrimSpace(r.Body))
+		}
+		sb.WriteString("\n```")
+	}
+	return sb.String()
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package fetcher
+
+import (
+	"strings"
+	"testing"
+)
+
+// --- Resource Capture Tests ---
+
+func TestCaptureConfig_Matches(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         CaptureConfig
+		url         string
+		contentType string
+		want        bool
+	}{
+		{"json by default", CaptureConfig{}, "https://api.example.com/items", "application/json; charset=utf-8", true},
+		{"+json by default", CaptureConfig{}, "https://api.example.com/items", "application/vnd.api+json", true},
+		{"html not by default", CaptureConfig{}, "https://example.com/partial", "text/html", false},
+		{"pattern matches", CaptureConfig{URLPattern: `/api/v\d+/products`}, "https://shop.example.com/api/v2/products?page=1", "application/json", true},
+		{"pattern excludes", CaptureConfig{URLPattern: `/api/v\d+/products`}, "https://shop.example.com/api/v2/analytics", "application/json", false},
+		{"custom types", CaptureConfig{ContentTypes: []string{"application/x-ndjson"}}, "https://example.com/feed", "application/x-ndjson", true},
+		{"custom types replace json", CaptureConfig{ContentTypes: []string{"text/plain"}}, "https://example.com/feed", "application/json", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.Matches(tt.url, tt.contentType); got != tt.want {
+				t.Errorf("Matches(%q, %q) = %v, want %v", tt.url, tt.contentType, got, tt.want)
+			}
+		})
+	}
+
+	if err := (CaptureConfig{URLPattern: "("}).Validate(); err == nil {
+		t.Error("Validate() should reject an invalid pattern")
+	}
+}
+
+func TestParseResourceMode(t *testing.T) {
+	tests := map[string]ResourceMode{"": ResourcesIgnore, "page": ResourcesIgnore, "append": ResourcesAppend, "ONLY": ResourcesOnly}
+	for in, want := range tests {
+		if got, err := ParseResourceMode(in); err != nil || got != want {
+			t.Errorf("ParseResourceMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseResourceMode("dom"); err == nil {
+		t.Error("ParseResourceMode should reject unknown modes")
+	}
+}
+
+func TestExtractionInput(t *testing.T) {
+	resources := []Resource{
+		{URL: "https://shop.example.com/api/products", Method: "GET", Body: `{"items":[{"name":"Widget"}]}`},
+		{URL: "https://shop.example.com/api/search", Method: "POST", Body: `not json`},
+	}
+	page := "# Products\n\nLoading..."
+
+	formatted := FormatResources(resources)
+	for _, want := range []string{
+		"## Resource: GET https://shop.example.com/api/products\n\n```json\n{\n  \"items\": [",
+		"## Resource: POST https://shop.example.com/api/search\n\n```\nnot json\n```",
+	} {
+		if !strings.Contains(formatted, want) {
+			t.Errorf("FormatResources() missing %q:\n%s", want, formatted)
+		}
+	}
+
+	tests := []struct {
+		mode      ResourceMode
+		resources []Resource
+		want      string
+	}{
+		{ResourcesIgnore, resources, page},
+		{ResourcesAppend, resources, page + "\n\n" + formatted},
+		{ResourcesOnly, resources, formatted},
+		{ResourcesOnly, nil, page}, // nothing captured: fall back to the page
+	}
+	for _, tt := range tests {
+		if got := ExtractionInput(page, tt.resources, tt.mode); got != tt.want {
+			t.Errorf("ExtractionInput(mode %q) = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jmylchreest/refyne/internal/logger"
+)
+
+// RetryPolicy controls how fetchers retry failed requests.
+// The zero value makes a single attempt.
+type RetryPolicy struct {
+	MaxAttempts   int           // Total attempts including the first (0 or 1 = no retries)
+	BaseBackoff   time.Duration // Backoff before the first retry; doubles on each retry
+	MaxBackoff    time.Duration // Cap on a single backoff, including Retry-After waits
+	Jitter        float64       // Fraction of each backoff that is randomized (0-1)
+	RetryStatuses []int         // HTTP status codes worth retrying
+
+	// RetryError reports whether a failed attempt with no retryable status should
+	// be retried. Defaults to IsTransientError.
+	RetryError func(err error) bool
+}
+
+// DefaultRetryStatuses are the status codes retried by DefaultRetryPolicy.
+var DefaultRetryStatuses = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryPolicy returns a policy of 3 attempts with jittered exponential
+// backoff from 500ms, capped at 30s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:   3,
+		BaseBackoff:   500 * time.Millisecond,
+		MaxBackoff:    30 * time.Second,
+		Jitter:        0.2,
+		RetryStatuses: DefaultRetryStatuses,
+	}
+}
+
+// Attempt records one try at fetching a URL.
+type Attempt struct {
+	StatusCode int           // 0 if no response was received
+	Duration   time.Duration // Time spent on the request
+	Backoff    time.Duration // Wait before the next attempt (0 for the last)
+	Error      string        // Empty on success
+}
+
+// IsTransientError reports whether err looks like a temporary network failure:
+// timeouts, refused or reset connections, and truncated responses.
+// Browser (net::ERR_*) errors are matched by name.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsTemporary {
+		return true
+	}
+
+	msg := err.Error()
+	for _, code := range []string{
+		"net::ERR_CONNECTION_RESET",
+		"net::ERR_CONNECTION_CLOSED",
+		"net::ERR_CONNECTION_REFUSED",
+		"net::ERR_CONNECTION_TIMED_OUT",
+		"net::ERR_TIMED_OUT",
+		"net::ERR_EMPTY_RESPONSE",
+		"net::ERR_NETWORK_CHANGED",
+	} {
+		if strings.Contains(msg, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRetryAfter parses a Retry-After header value (delta-seconds or HTTP-date)
+// into a wait relative to now. It returns 0 if the value is missing or invalid.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := time.ParseDuration(value + "s"); err == nil {
+		return max(secs, 0)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
+// retryable reports whether an attempt that produced content and err should be retried.
+func (p RetryPolicy) retryable(content Content, err error) bool {
+	if content.StatusCode != 0 && slices.Contains(p.RetryStatuses, content.StatusCode) {
+		return true
+	}
+	if err == nil {
+		return false
+	}
+	if p.RetryError != nil {
+		return p.RetryError(err)
+	}
+	return IsTransientError(err)
+}
+
+// backoff returns the wait before retry number n (1-based), honouring Retry-After
+// on 429/503 responses.
+func (p RetryPolicy) backoff(n int, content Content) time.Duration {
+	wait := p.BaseBackoff << (n - 1)
+	if wait <= 0 || (p.MaxBackoff > 0 && wait > p.MaxBackoff) {
+		wait = p.MaxBackoff
+	}
+	if p.Jitter > 0 && wait > 0 {
+		spread := float64(wait) * min(p.Jitter, 1)
+		wait = time.Duration(float64(wait) - spread + rand.Float64()*2*spread) //#nosec G404 -- jitter doesn't need crypto randomness
+	}
+
+	if content.StatusCode == http.StatusTooManyRequests || content.StatusCode == http.StatusServiceUnavailable {
+		if retryAfter := ParseRetryAfter(content.Headers.Get("Retry-After"), time.Now()); retryAfter > wait {
+			wait = retryAfter
+			if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+				wait = p.MaxBackoff
+			}
+		}
+	}
+	return wait
+}
+
+// Do calls fetch until it succeeds, fails with a non-retryable error, or the
+// policy runs out of attempts. The returned content carries every attempt.
+func (p RetryPolicy) Do(ctx context.Context, targetURL string, fetch func(ctx context.Context) (Content, error)) (Content, error) {
+	maxAttempts := max(p.MaxAttempts, 1)
+
+	var attempts []Attempt
+	for n := 1; ; n++ {
+		start := time.Now()
+		content, err := fetch(ctx)
+		attempt := Attempt{StatusCode: content.StatusCode, Duration: time.Since(start)}
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+
+		if n >= maxAttempts || ctx.Err() != nil || !p.retryable(content, err) {
+			content.Attempts = append(attempts, attempt)
+			return content, err
+		}
+
+		attempt.Backoff = p.backoff(n, content)
+		attempts = append(attempts, attempt)
+		logger.Info("retrying fetch",
+			"url", targetURL,
+			"attempt", n,
+			"status", content.StatusCode,
+			"error", err,
+			"backoff", attempt.Backoff)
+
+		timer := time.NewTimer(attempt.Backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			content.Attempts = attempts
+			if err == nil {
+				err = ctx.Err()
+			}
+			return content, err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fastRetry is a retry policy with backoffs short enough for tests.
+func fastRetry(attempts int) RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.MaxAttempts = attempts
+	p.BaseBackoff = time.Millisecond
+	p.MaxBackoff = 10 * time.Millisecond
+	p.Jitter = 0
+	return p
+}
+
+func TestStaticFetcher_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv, hits := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte("<html><body>ok</body></html>"))
+	})
+
+	f := NewStatic(StaticConfig{Retry: fastRetry(3)})
+	content, err := f.Fetch(context.Background(), srv.URL, Options{})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("server hit %d times, want 3", got)
+	}
+	if len(content.Attempts) != 3 {
+		t.Fatalf("len(Attempts) = %d, want 3", len(content.Attempts))
+	}
+	first := content.Attempts[0]
+	if first.StatusCode != http.StatusBadGateway || first.Error == "" || first.Backoff == 0 {
+		t.Errorf("first attempt = %+v, want a 502 with an error and a backoff", first)
+	}
+	last := content.Attempts[2]
+	if last.StatusCode != http.StatusOK || last.Error != "" || last.Backoff != 0 {
+		t.Errorf("last attempt = %+v, want a clean 200", last)
+	}
+}
+
+func TestStaticFetcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	srv, hits := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	f := NewStatic(StaticConfig{Retry: fastRetry(2)})
+	content, err := f.Fetch(context.Background(), srv.URL, Options{})
+	if err == nil {
+		t.Fatal("Fetch() should fail when every attempt fails")
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("server hit %d times, want 2", got)
+	}
+	if len(content.Attempts) != 2 {
+		t.Errorf("len(Attempts) = %d, want 2", len(content.Attempts))
+	}
+}
+
+func TestStaticFetcher_DoesNotRetryClientErrors(t *testing.T) {
+	srv, hits := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	f := NewStatic(StaticConfig{Retry: fastRetry(3)})
+	if _, err := f.Fetch(context.Background(), srv.URL, Options{}); err == nil {
+		t.Fatal("Fetch() should fail on 404")
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("server hit %d times, want 1", got)
+	}
+}
+
+func TestRetryPolicy_HonoursRetryAfter(t *testing.T) {
+	p := fastRetry(2)
+	p.MaxBackoff = time.Minute
+
+	calls := 0
+	content, err := p.Do(context.Background(), "https://example.com", func(ctx context.Context) (Content, error) {
+		calls++
+		if calls == 1 {
+			return Content{
+				StatusCode: http.StatusTooManyRequests,
+				Headers:    http.Header{"Retry-After": {"1"}},
+			}, errors.New("too many requests")
+		}
+		return Content{StatusCode: http.StatusOK}, nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got := content.Attempts[0].Backoff; got != time.Second {
+		t.Errorf("backoff = %v, want Retry-After of 1s", got)
+	}
+}
+
+func TestRetryPolicy_RetryAfterCappedByMaxBackoff(t *testing.T) {
+	p := fastRetry(2)
+	got := p.backoff(1, Content{
+		StatusCode: http.StatusServiceUnavailable,
+		Headers:    http.Header{"Retry-After": {"3600"}},
+	})
+	if got != p.MaxBackoff {
+		t.Errorf("backoff = %v, want MaxBackoff %v", got, p.MaxBackoff)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{80, time.Second}, // shift overflow stays capped
+	}
+	for _, tt := range tests {
+		if got := p.backoff(tt.retry, Content{}); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.retry, got, tt.want)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(2, Content{}); got < 100*time.Millisecond || got > 300*time.Millisecond {
+			t.Fatalf("jittered backoff = %v, want within 100ms-300ms", got)
+		}
+	}
+}
+
+func TestRetryPolicy_StopsOnCancel(t *testing.T) {
+	p := fastRetry(5)
+	p.BaseBackoff = time.Hour
+	p.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	_, err := p.Do(ctx, "https://example.com", func(ctx context.Context) (Content, error) {
+		calls++
+		cancel()
+		return Content{}, syscall.ECONNRESET
+	})
+	if err == nil {
+		t.Fatal("Do() should return an error")
+	}
+	if calls != 1 {
+		t.Errorf("fetch called %d times, want 1", calls)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"connection reset", fmt.Errorf("fetch error: %w", syscall.ECONNRESET), true},
+		{"connection refused", syscall.ECONNREFUSED, true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"canceled", context.Canceled, false},
+		{"browser reset", errors.New("page load error net::ERR_CONNECTION_RESET"), true},
+		{"browser DNS", errors.New("page load error net::ERR_NAME_NOT_RESOLVED"), false},
+		{"other", errors.New("Not Found"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransientError(tt.err); got != tt.want {
+				t.Errorf("IsTransientError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{" 3 ", 3 * time.Second},
+		{"-5", 0},
+		{"Wed, 01 Jan 2025 00:00:30 GMT", 30 * time.Second},
+		{"Tue, 31 Dec 2024 23:59:00 GMT", 0},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := ParseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package fetcher
+
+// Screenshot defaults, sized for vision models (which downscale anything
+// larger) and to keep request payloads reasonable.
+const (
+	defaultScreenshotQuality = 80
+	defaultScreenshotHeight  = 8000
+	defaultScreenshotWidth   = 1280
+	defaultMaxImages         = 10
+	defaultMinImageSize      = 100
+)
+
+// Image is an image captured from a rendered page.
+type Image struct {
+	URL       string // Source URL of a page image (empty for screenshots)
+	MediaType string // "image/png" or "image/jpeg"
+	Data      []byte
+	Width     int // Pixels, after any downscaling
+	Height    int
+}
+
+// ScreenshotConfig controls the images a dynamic fetcher captures into
+// Content.Screenshot and Content.Images, for extraction with vision models.
+type ScreenshotConfig struct {
+	Quality   int // JPEG quality 1-99, or 100 for PNG (default: 80)
+	MaxHeight int // Long pages are cut off below this many CSS pixels (default: 8000)
+	MaxWidth  int // Wider screenshots and images are downscaled to this width (default: 1280)
+
+	// Page images
+	Images        bool   // Also capture the page's images (floor plans, price badges, charts)
+	ImageSelector string // CSS selector for the images to capture (default: "img")
+	MaxImages     int    // Max images per page, in document order (default: 10)
+	MinImageSize  int    // Skip images rendered smaller than this in either dimension, e.g. icons (default: 100)
+}
+
+// WithDefaults returns the config with defaults applied to unset fields.
+func (c ScreenshotConfig) WithDefaults() ScreenshotConfig {
+	if c.Quality <= 0 || c.Quality > 100 {
+		c.Quality = defaultScreenshotQuality
+	}
+	if c.MaxHeight <= 0 {
+		c.MaxHeight = defaultScreenshotHeight
+	}
+	if c.MaxWidth <= 0 {
+		c.MaxWidth = defaultScreenshotWidth
+	}
+	if c.ImageSelector == "" {
+		c.ImageSelector = "img"
+	}
+	if c.MaxImages <= 0 {
+		c.MaxImages = defaultMaxImages
+	}
+	if c.MinImageSize <= 0 {
+		c.MinImageSize = defaultMinImageSize
+	}
+	return c
+}
+
+// MediaType returns the media type of captured images.
+func (c ScreenshotConfig) MediaType() string {
+	if c.Quality == 100 {
+		return "image/png"
+	}
+	return "image/jpeg"
+}
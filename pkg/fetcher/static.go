@@ -1,9 +1,15 @@
 package fetcher
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"fmt"
+	"io"
+	"math"
+	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +22,15 @@ import (
 type StaticConfig struct {
 	UserAgent string
 	Timeout   time.Duration
+	Retry     RetryPolicy // Retry policy for failed requests (zero value = no retries)
+
+	// Session state
+	CookieJar   *CookieJar // Cookie jar shared across requests (default: a new jar per fetcher)
+	SaveCookies string     // If set, the jar is written to this file on Close (.json or cookies.txt)
+
+	// Response limits (Options can override them per request)
+	MaxBodyBytes        int64    // Larger responses fail with ErrTooLarge (0 = DefaultMaxBodyBytes)
+	AllowedContentTypes []string // Media types or "type/*" wildcards; others fail with ErrUnsupportedContentType (nil = any)
 }
 
 // DefaultStaticConfig returns sensible defaults.
@@ -23,9 +38,16 @@ func DefaultStaticConfig() StaticConfig {
 	return StaticConfig{
 		UserAgent: defaultUserAgent,
 		Timeout:   30 * time.Second,
+		Retry:     DefaultRetryPolicy(),
 	}
 }
 
+// maxRedirects matches net/http's default redirect limit.
+const maxRedirects = 10
+
+// DefaultMaxBodyBytes is the default response size limit of the static fetcher.
+const DefaultMaxBodyBytes = 10 << 20
+
 // Chrome user agent for better compatibility
 const defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
 
@@ -33,6 +55,7 @@ const defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/
 // It implements the Fetcher interface.
 type StaticFetcher struct {
 	config StaticConfig
+	jar    *CookieJar
 }
 
 // NewStatic creates a new static fetcher.
@@ -43,11 +66,27 @@ func NewStatic(cfg StaticConfig) *StaticFetcher {
 	if cfg.Timeout == 0 {
 		cfg.Timeout = DefaultStaticConfig().Timeout
 	}
-	return &StaticFetcher{config: cfg}
+	jar := cfg.CookieJar
+	if jar == nil {
+		jar = NewCookieJar()
+	}
+	return &StaticFetcher{config: cfg, jar: jar}
+}
+
+// CookieJar returns the fetcher's cookie jar.
+func (f *StaticFetcher) CookieJar() *CookieJar {
+	return f.jar
 }
 
-// Fetch retrieves page content using Colly.
+// Fetch retrieves page content using Colly, retrying according to the configured policy.
 func (f *StaticFetcher) Fetch(ctx context.Context, targetURL string, opts Options) (Content, error) {
+	return f.config.Retry.Do(ctx, targetURL, func(ctx context.Context) (Content, error) {
+		return f.fetchOnce(ctx, targetURL, opts)
+	})
+}
+
+// fetchOnce makes a single request.
+func (f *StaticFetcher) fetchOnce(ctx context.Context, targetURL string, opts Options) (Content, error) {
 	logger.Debug("static fetch starting", "url", targetURL)
 
 	result := Content{
@@ -62,6 +101,14 @@ func (f *StaticFetcher) Fetch(ctx context.Context, targetURL string, opts Option
 	)
 	logger.Debug("static fetch configured", "user_agent", userAgent)
 
+	// Share session cookies across requests
+	f.jar.AddCookies(targetURL, opts.Cookies)
+	c.SetCookieJar(f.jar)
+
+	// Keep the body as received: colly transcodes bodies that declare a charset
+	raw := &rawBodyTransport{next: http.DefaultTransport}
+	c.WithTransport(raw)
+
 	// Set timeout
 	timeout := opts.Timeout
 	if timeout == 0 {
@@ -79,33 +126,124 @@ func (f *StaticFetcher) Fetch(ctx context.Context, targetURL string, opts Option
 		})
 	}
 
+	// Record the redirect chain
+	var redirects []Redirect
+	c.SetRedirectHandler(func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return http.ErrUseLastResponse
+		}
+		prev := via[len(via)-1]
+		status := 0
+		if req.Response != nil {
+			status = req.Response.StatusCode
+		}
+		redirects = append(redirects, Redirect{URL: prev.URL.String(), StatusCode: status})
+		logger.Debug("static fetch redirected", "from", prev.URL, "to", req.URL, "status", status)
+
+		// Don't leak credentials to another host
+		if req.URL.Host != prev.URL.Host {
+			req.Header.Del("Authorization")
+		}
+		return nil
+	})
+
+	// Abort responses that are too large, or of a type the caller doesn't want,
+	// once their headers arrive rather than after downloading them. Reading one
+	// byte past the limit tells a body at the limit from a larger one.
+	maxBody := cmp.Or(opts.MaxBodyBytes, f.config.MaxBodyBytes, DefaultMaxBodyBytes)
+	allowedTypes := opts.AllowedContentTypes
+	if allowedTypes == nil {
+		allowedTypes = f.config.AllowedContentTypes
+	}
+	c.MaxBodySize = int(min(maxBody+1, math.MaxInt))
+	var limitErr error
+	c.OnResponseHeaders(func(r *colly.Response) {
+		limitErr = checkResponseLimits(r.StatusCode, *r.Headers, maxBody, allowedTypes)
+		if limitErr == nil {
+			return
+		}
+		result.StatusCode = r.StatusCode
+		result.FinalURL = r.Request.URL.String()
+		result.RedirectChain = redirects
+		result.ContentType = r.Headers.Get("Content-Type")
+		result.Headers = r.Headers.Clone()
+		logger.Debug("static fetch aborted after headers", "url", targetURL, "error", limitErr)
+		r.Request.Abort()
+	})
+
 	var fetchErr error
 
 	// Handle response
 	c.OnResponse(func(r *colly.Response) {
 		result.StatusCode = r.StatusCode
+		result.FinalURL = r.Request.URL.String()
+		result.RedirectChain = redirects
 		result.ContentType = r.Headers.Get("Content-Type")
-		result.HTML = string(r.Body)
+		result.Headers = r.Headers.Clone()
+		if int64(len(r.Body)) > maxBody {
+			// No Content-Length to check up front
+			limitErr = fmt.Errorf("%w: more than %d bytes", ErrTooLarge, maxBody)
+			return
+		}
+		result.Body = bytes.Clone(raw.body.Bytes())
+		result.HTML, result.Charset = DecodeBody(r.Body, result.ContentType)
+		// colly has already decoded a body whose Content-Type declares a charset
+		if declared := headerCharset(result.ContentType); declared != "" && result.Charset == "utf-8" {
+			result.Charset = declared
+		}
 		logger.Debug("static fetch response received",
 			"status", r.StatusCode,
 			"content_type", result.ContentType,
+			"charset", result.Charset,
 			"body_size", len(r.Body))
 	})
 
 	// Handle errors
 	c.OnError(func(r *colly.Response, err error) {
+		if limitErr != nil {
+			return // aborted on purpose; result already describes the response
+		}
 		statusCode := 0
 		if r != nil {
 			statusCode = r.StatusCode
 			result.StatusCode = statusCode
+			if r.Request != nil {
+				result.FinalURL = r.Request.URL.String()
+				result.RedirectChain = redirects
+			}
+			if r.Headers != nil {
+				result.Headers = r.Headers.Clone()
+			}
+		}
+		// A 304 is the expected answer to a conditional request (see CachingFetcher),
+		// not a failure. Return it with an empty body and let the caller decide.
+		if statusCode == http.StatusNotModified {
+			logger.Debug("static fetch not modified", "url", targetURL)
+			return
 		}
 		fetchErr = fmt.Errorf("fetch error: %w", err)
 		logger.Debug("static fetch error", "status", statusCode, "error", err)
 	})
 
 	// Perform the request
-	logger.Debug("static fetch visiting URL", "url", targetURL)
-	if err := c.Visit(targetURL); err != nil {
+	method, contentType, body := opts.RequestBody()
+	var header http.Header
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+		if contentType != "" {
+			header = http.Header{"Content-Type": {contentType}}
+		}
+	}
+	logger.Debug("static fetch visiting URL", "url", targetURL, "method", method, "body_size", len(body))
+	err := c.Request(method, targetURL, reqBody, nil, header)
+	if limitErr != nil {
+		return result, limitErr
+	}
+	if err != nil {
+		if result.StatusCode == http.StatusNotModified {
+			return result, nil
+		}
 		logger.Debug("static fetch visit failed", "url", targetURL, "error", err)
 		return result, fmt.Errorf("failed to visit URL: %w", err)
 	}
@@ -117,7 +255,7 @@ func (f *StaticFetcher) Fetch(ctx context.Context, targetURL string, opts Option
 	// Parse HTML and extract content
 	if result.HTML != "" {
 		logger.Debug("static fetch parsing content", "html_size", len(result.HTML))
-		if err := f.parseContent(&result); err != nil {
+		if err := parseBody(&result); err != nil {
 			logger.Debug("static fetch parse failed", "error", err)
 			return result, fmt.Errorf("failed to parse content: %w", err)
 		}
@@ -131,8 +269,46 @@ func (f *StaticFetcher) Fetch(ctx context.Context, targetURL string, opts Option
 	return result, nil
 }
 
+// rawBodyTransport keeps a copy of the body of the last response read
+// through it, before colly decompresses or transcodes it.
+type rawBodyTransport struct {
+	next http.RoundTripper
+	body bytes.Buffer
+}
+
+func (t *rawBodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	t.body.Reset() // A redirect's body is replaced by the next response's
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.TeeReader(resp.Body, &t.body), resp.Body}
+	return resp, nil
+}
+
+// checkResponseLimits checks a successful response's declared size and
+// content type before its body is read.
+func checkResponseLimits(status int, headers http.Header, maxBody int64, allowedTypes []string) error {
+	if status < 200 || status >= 300 {
+		return nil // errors and 304s are handled as usual
+	}
+	if size, err := strconv.ParseInt(headers.Get("Content-Length"), 10, 64); err == nil && size > maxBody {
+		return fmt.Errorf("%w: %d bytes (limit %d)", ErrTooLarge, size, maxBody)
+	}
+	contentType := headers.Get("Content-Type")
+	if len(allowedTypes) > 0 && contentType != "" {
+		if mediaType := mediaTypeOf(contentType); !matchesMediaType(allowedTypes, mediaType) {
+			return fmt.Errorf("%w: %s", ErrUnsupportedContentType, mediaType)
+		}
+	}
+	return nil
+}
+
 // parseContent extracts text and metadata from HTML.
-func (f *StaticFetcher) parseContent(content *Content) error {
+func parseContent(content *Content) error {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content.HTML))
 	if err != nil {
 		return err
@@ -154,8 +330,8 @@ func (f *StaticFetcher) parseContent(content *Content) error {
 	})
 	content.Text = strings.Join(textParts, "\n")
 
-	// Extract links
-	baseURL, _ := url.Parse(content.URL)
+	// Extract links, resolving them against the URL after any redirects
+	baseURL, _ := url.Parse(content.PageURL())
 	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
 		href, exists := s.Attr("href")
 		if !exists || href == "" || strings.HasPrefix(href, "#") {
@@ -177,8 +353,15 @@ func (f *StaticFetcher) parseContent(content *Content) error {
 	return nil
 }
 
-// Close releases resources.
+// Close saves the cookie jar if configured.
 func (f *StaticFetcher) Close() error {
+	if f.config.SaveCookies == "" {
+		return nil
+	}
+	if err := f.jar.SaveFile(f.config.SaveCookies); err != nil {
+		return err
+	}
+	logger.Debug("cookies saved", "path", f.config.SaveCookies, "count", f.jar.Len())
 	return nil
 }
 
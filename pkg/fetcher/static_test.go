@@ -0,0 +1,118 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// --- StaticFetcher Tests ---
+
+func TestStaticFetcher_RedirectChain(t *testing.T) {
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+		case "/moved":
+			http.Redirect(w, r, "/articles/new", http.StatusFound)
+		default:
+			w.Header().Set("X-Page", "final")
+			_, _ = w.Write([]byte(`<html><body><a href="related">Related</a></body></html>`))
+		}
+	})
+
+	content, err := NewStatic(StaticConfig{}).Fetch(context.Background(), srv.URL+"/old", Options{})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if content.URL != srv.URL+"/old" {
+		t.Errorf("URL = %q, want the requested URL", content.URL)
+	}
+	if want := srv.URL + "/articles/new"; content.FinalURL != want || content.PageURL() != want {
+		t.Errorf("FinalURL = %q, PageURL() = %q; want %q", content.FinalURL, content.PageURL(), want)
+	}
+	wantChain := []Redirect{
+		{URL: srv.URL + "/old", StatusCode: http.StatusMovedPermanently},
+		{URL: srv.URL + "/moved", StatusCode: http.StatusFound},
+	}
+	if !slices.Equal(content.RedirectChain, wantChain) {
+		t.Errorf("RedirectChain = %+v, want %+v", content.RedirectChain, wantChain)
+	}
+	if content.StatusCode != http.StatusOK || content.Headers.Get("X-Page") != "final" {
+		t.Errorf("StatusCode = %d, X-Page = %q; want the final response", content.StatusCode, content.Headers.Get("X-Page"))
+	}
+
+	// Relative links resolve against the final URL, not the requested one
+	if want := srv.URL + "/articles/related"; !slices.Contains(content.Links, want) {
+		t.Errorf("Links = %v, want %q", content.Links, want)
+	}
+}
+
+func TestStaticFetcher_NoRedirect(t *testing.T) {
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body>ok</body></html>`))
+	})
+
+	content, err := NewStatic(StaticConfig{}).Fetch(context.Background(), srv.URL+"/page", Options{})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if content.FinalURL != srv.URL+"/page" || content.RedirectChain != nil {
+		t.Errorf("FinalURL = %q, RedirectChain = %+v; want the requested URL and no redirects", content.FinalURL, content.RedirectChain)
+	}
+}
+
+func TestStaticFetcher_ResponseLimits(t *testing.T) {
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/video.mp4":
+			w.Header().Set("Content-Type", "video/mp4")
+			_, _ = w.Write(make([]byte, 512))
+		case "/big":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><body>" + strings.Repeat("x", 2048) + "</body></html>"))
+		case "/streamed":
+			// Flushing early sends a chunked response with no Content-Length
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><body>"))
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte(strings.Repeat("x", 2048) + "</body></html>"))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html><body>ok</body></html>"))
+		}
+	})
+
+	f := NewStatic(StaticConfig{MaxBodyBytes: 1024, AllowedContentTypes: []string{"text/*"}})
+	tests := []struct {
+		path    string
+		opts    Options
+		wantErr error
+	}{
+		{"/page", Options{}, nil},
+		{"/video.mp4", Options{}, ErrUnsupportedContentType},
+		{"/video.mp4", Options{AllowedContentTypes: []string{"video/mp4"}}, nil},
+		{"/big", Options{}, ErrTooLarge},
+		{"/big", Options{MaxBodyBytes: 4096}, nil},
+		{"/streamed", Options{}, ErrTooLarge},
+	}
+	for _, tt := range tests {
+		content, err := f.Fetch(context.Background(), srv.URL+tt.path, tt.opts)
+		if tt.wantErr == nil {
+			if err != nil {
+				t.Errorf("Fetch(%s) error = %v", tt.path, err)
+			}
+			continue
+		}
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Fetch(%s) error = %v, want %v", tt.path, err, tt.wantErr)
+		}
+		if content.StatusCode != http.StatusOK || content.HTML != "" {
+			t.Errorf("Fetch(%s) StatusCode = %d, body size %d; want the status and no body", tt.path, content.StatusCode, len(content.HTML))
+		}
+	}
+}
@@ -0,0 +1,422 @@
+package fetcher
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1" //#nosec G505 -- SHA-1 is mandated by the WARC digest convention, not used for security
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WARC record types used by the writer and reader.
+const (
+	WARCTypeInfo     = "warcinfo"
+	WARCTypeRequest  = "request"
+	WARCTypeResponse = "response"
+	WARCTypeMetadata = "metadata"
+	WARCTypeResource = "resource"
+)
+
+// Fields of the metadata record written alongside a page's response.
+const (
+	warcFieldFinalURL = "final-url" // URL the page was served from after redirects
+	warcFieldRedirect = "redirect"  // One per redirect followed, in order: "<status> <url>"
+)
+
+// warcScreenshotPrefix prefixes a page's URL to make the target URI of its
+// screenshot's resource record, as other archiving tools do.
+const warcScreenshotPrefix = "urn:screenshot:"
+
+const warcVersion = "WARC/1.1"
+
+// warcDateFormat is the W3C-ISO8601 form WARC 1.1 uses, with microsecond precision.
+const warcDateFormat = "2006-01-02T15:04:05.000000Z"
+
+// WARCRecord is a single record read from a WARC file.
+type WARCRecord struct {
+	Header textproto.MIMEHeader
+	Block  []byte
+}
+
+// Type returns the WARC-Type of the record.
+func (r *WARCRecord) Type() string {
+	return r.Header.Get("WARC-Type")
+}
+
+// TargetURI returns the WARC-Target-URI of the record.
+func (r *WARCRecord) TargetURI() string {
+	return strings.Trim(r.Header.Get("WARC-Target-URI"), "<>")
+}
+
+// Date returns the WARC-Date of the record, or the zero time if it is missing or malformed.
+func (r *WARCRecord) Date() time.Time {
+	t, err := time.Parse(time.RFC3339Nano, r.Header.Get("WARC-Date"))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// WARCWriter writes WARC 1.1 records.
+// When compression is enabled each record is written as its own gzip member,
+// as recommended by the WARC specification, so readers can seek to any record.
+// It is safe for concurrent use.
+type WARCWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	closer   io.Closer
+	compress bool
+}
+
+// NewWARCWriter creates a writer that emits WARC records to w.
+func NewWARCWriter(w io.Writer, compress bool) *WARCWriter {
+	ww := &WARCWriter{w: w, compress: compress}
+	if c, ok := w.(io.Closer); ok {
+		ww.closer = c
+	}
+	return ww
+}
+
+// CreateWARC creates (or truncates) a WARC file at path and writes a warcinfo record.
+// Records are gzip-compressed when the path ends in ".gz".
+func CreateWARC(path string) (*WARCWriter, error) {
+	f, err := os.Create(path) //#nosec G304 -- caller-specified archive path
+	if err != nil {
+		return nil, fmt.Errorf("failed to create WARC file: %w", err)
+	}
+	w := NewWARCWriter(f, strings.HasSuffix(path, ".gz"))
+	if err := w.WriteInfo(map[string]string{
+		"software": "refyne",
+		"format":   "WARC File Format 1.1",
+	}); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// WriteInfo writes a warcinfo record with the given fields.
+func (w *WARCWriter) WriteInfo(fields map[string]string) error {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var block bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&block, "%s: %s\r\n", k, fields[k])
+	}
+
+	return w.writeRecord([][2]string{
+		{"WARC-Type", WARCTypeInfo},
+		{"WARC-Record-ID", newWARCRecordID()},
+		{"WARC-Date", time.Now().UTC().Format(warcDateFormat)},
+		{"Content-Type", "application/warc-fields"},
+	}, block.Bytes())
+}
+
+// WriteExchange writes a request/response record pair for a completed fetch,
+// followed by records for what the fetch returned besides the response: a
+// metadata record with its final URL and redirects, request/response pairs for
+// the resources captured while rendering the page, and resource records for
+// its screenshot and images. Each of these refers to the page's response with
+// WARC-Concurrent-To.
+//
+// The response payload is the body as received (see Content.Body). If the
+// fetcher didn't keep it, the body it returned is recorded instead, already
+// decoded and converted to UTF-8, so transfer and content encodings are dropped
+// from the recorded headers and the charset is recorded as UTF-8.
+func (w *WARCWriter) WriteExchange(targetURL string, opts Options, content Content) error {
+	if _, err := url.Parse(targetURL); err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+
+	date := content.FetchedAt
+	if date.IsZero() {
+		date = time.Now()
+	}
+	warcDate := date.UTC().Format(warcDateFormat)
+	responseID := newWARCRecordID()
+
+	// Response record
+	body := content.Body
+	headers := content.Headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	if headers.Get("Content-Type") == "" && content.ContentType != "" {
+		headers.Set("Content-Type", content.ContentType)
+	}
+	if body == nil {
+		body = []byte(content.HTML)
+		if content.Charset != "" {
+			headers.Set("Content-Type", utf8ContentType(headers.Get("Content-Type")))
+		}
+		headers.Del("Content-Encoding")
+	}
+	if err := w.writeResponse(responseID, "", warcDate, targetURL, content.StatusCode, headers, body); err != nil {
+		return err
+	}
+
+	// Request record
+	method, contentType, reqBody := opts.RequestBody()
+	reqHeaders := http.Header{}
+	if opts.UserAgent != "" {
+		reqHeaders.Set("User-Agent", opts.UserAgent)
+	}
+	for k, v := range opts.Headers {
+		reqHeaders.Set(k, v)
+	}
+	if reqBody != nil && contentType != "" {
+		reqHeaders.Set("Content-Type", contentType)
+	}
+	if err := w.writeRequest(responseID, warcDate, targetURL, method, reqHeaders, reqBody); err != nil {
+		return err
+	}
+
+	// Metadata record, if the page was served from another URL
+	if (content.FinalURL != "" && content.FinalURL != targetURL) || len(content.RedirectChain) > 0 {
+		var block bytes.Buffer
+		if content.FinalURL != "" {
+			fmt.Fprintf(&block, "%s: %s\r\n", warcFieldFinalURL, content.FinalURL)
+		}
+		for _, hop := range content.RedirectChain {
+			fmt.Fprintf(&block, "%s: %d %s\r\n", warcFieldRedirect, hop.StatusCode, hop.URL)
+		}
+		if err := w.writeRecord([][2]string{
+			{"WARC-Type", WARCTypeMetadata},
+			{"WARC-Record-ID", newWARCRecordID()},
+			{"WARC-Date", warcDate},
+			{"WARC-Target-URI", targetURL},
+			{"WARC-Concurrent-To", responseID},
+			{"Content-Type", "application/warc-fields"},
+		}, block.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	// Captured resources, as the request/response pairs they were
+	for _, res := range content.Resources {
+		if _, err := url.Parse(res.URL); err != nil {
+			continue
+		}
+		resHeaders := http.Header{}
+		if res.ContentType != "" {
+			resHeaders.Set("Content-Type", res.ContentType)
+		}
+		resID := newWARCRecordID()
+		if err := w.writeResponse(resID, responseID, warcDate, res.URL, res.StatusCode, resHeaders, []byte(res.Body)); err != nil {
+			return err
+		}
+		if err := w.writeRequest(resID, warcDate, res.URL, cmp.Or(res.Method, http.MethodGet), http.Header{}, nil); err != nil {
+			return err
+		}
+	}
+
+	// Screenshot and images
+	if content.Screenshot != nil {
+		if err := w.writeImage(responseID, warcDate, warcScreenshotPrefix+targetURL, *content.Screenshot); err != nil {
+			return err
+		}
+	}
+	for _, img := range content.Images {
+		if err := w.writeImage(responseID, warcDate, img.URL, img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeResponse writes a response record with the given ID for an HTTP
+// response, referring to the record concurrentTo unless it is empty.
+func (w *WARCWriter) writeResponse(id, concurrentTo, warcDate, targetURL string, status int, headers http.Header, body []byte) error {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	var resp bytes.Buffer
+	fmt.Fprintf(&resp, "HTTP/1.1 %d %s\r\n", status, http.StatusText(status))
+	headers.Del("Transfer-Encoding")
+	headers.Set("Content-Length", strconv.Itoa(len(body)))
+	_ = headers.Write(&resp)
+	resp.WriteString("\r\n")
+	resp.Write(body)
+
+	fields := [][2]string{
+		{"WARC-Type", WARCTypeResponse},
+		{"WARC-Record-ID", id},
+		{"WARC-Date", warcDate},
+		{"WARC-Target-URI", targetURL},
+	}
+	if concurrentTo != "" {
+		fields = append(fields, [2]string{"WARC-Concurrent-To", concurrentTo})
+	}
+	fields = append(fields,
+		[2]string{"WARC-Payload-Digest", warcDigest(body)},
+		[2]string{"Content-Type", "application/http;msgtype=response"},
+	)
+	return w.writeRecord(fields, resp.Bytes())
+}
+
+// writeRequest writes the request record for the response record responseID.
+func (w *WARCWriter) writeRequest(responseID, warcDate, targetURL, method string, headers http.Header, body []byte) error {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	var req bytes.Buffer
+	fmt.Fprintf(&req, "%s %s HTTP/1.1\r\n", method, u.RequestURI())
+	headers.Set("Host", u.Host)
+	if body != nil {
+		headers.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	_ = headers.Write(&req)
+	req.WriteString("\r\n")
+	req.Write(body)
+
+	return w.writeRecord([][2]string{
+		{"WARC-Type", WARCTypeRequest},
+		{"WARC-Record-ID", newWARCRecordID()},
+		{"WARC-Date", warcDate},
+		{"WARC-Target-URI", targetURL},
+		{"WARC-Concurrent-To", responseID},
+		{"Content-Type", "application/http;msgtype=request"},
+	}, req.Bytes())
+}
+
+// writeImage writes a resource record for an image taken from the page whose
+// response record is responseID.
+func (w *WARCWriter) writeImage(responseID, warcDate, targetURI string, img Image) error {
+	return w.writeRecord([][2]string{
+		{"WARC-Type", WARCTypeResource},
+		{"WARC-Record-ID", newWARCRecordID()},
+		{"WARC-Date", warcDate},
+		{"WARC-Target-URI", targetURI},
+		{"WARC-Concurrent-To", responseID},
+		{"Content-Type", img.MediaType},
+	}, img.Data)
+}
+
+// writeRecord serialises a single record with the given named fields and block.
+func (w *WARCWriter) writeRecord(fields [][2]string, block []byte) error {
+	var buf bytes.Buffer
+	buf.WriteString(warcVersion + "\r\n")
+	for _, f := range fields {
+		fmt.Fprintf(&buf, "%s: %s\r\n", f[0], f[1])
+	}
+	fmt.Fprintf(&buf, "WARC-Block-Digest: %s\r\n", warcDigest(block))
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n", len(block))
+	buf.Write(block)
+	buf.WriteString("\r\n\r\n")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.compress {
+		_, err := w.w.Write(buf.Bytes())
+		return err
+	}
+	gz := gzip.NewWriter(w.w)
+	if _, err := gz.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (w *WARCWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closer != nil {
+		return w.closer.Close()
+	}
+	return nil
+}
+
+// WARCReader reads records sequentially from a WARC file.
+// Gzip-compressed input (one or many members) is detected automatically.
+type WARCReader struct {
+	r *bufio.Reader
+}
+
+// NewWARCReader creates a reader over r.
+func NewWARCReader(r io.Reader) (*WARCReader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip WARC: %w", err)
+		}
+		br = bufio.NewReader(gz)
+	}
+	return &WARCReader{r: br}, nil
+}
+
+// Next returns the next record, or io.EOF when there are no more records.
+func (r *WARCReader) Next() (*WARCRecord, error) {
+	// Skip blank lines between records
+	var version string
+	for {
+		line, err := r.r.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) && strings.TrimSpace(line) == "" {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+		version = strings.TrimSpace(line)
+		if version != "" {
+			break
+		}
+	}
+	if !strings.HasPrefix(version, "WARC/") {
+		return nil, fmt.Errorf("invalid WARC record: unexpected version line %q", version)
+	}
+
+	header, err := textproto.NewReader(r.r).ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("invalid WARC header: %w", err)
+	}
+
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid WARC Content-Length %q", header.Get("Content-Length"))
+	}
+
+	block := make([]byte, length)
+	if _, err := io.ReadFull(r.r, block); err != nil {
+		return nil, fmt.Errorf("truncated WARC record: %w", err)
+	}
+
+	return &WARCRecord{Header: header, Block: block}, nil
+}
+
+// newWARCRecordID returns a random urn:uuid record identifier.
+func newWARCRecordID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// warcDigest returns the conventional base32 SHA-1 digest used in WARC files.
+func warcDigest(data []byte) string {
+	sum := sha1.Sum(data) //#nosec G401 -- WARC digest convention
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,288 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWARC_RecordAndReplay(t *testing.T) {
+	srv, hits := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head><title>Archived</title></head><body><a href="/next">next</a>frozen</body></html>`))
+	})
+
+	for _, name := range []string{"crawl.warc", "crawl.warc.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			w, err := CreateWARC(path)
+			if err != nil {
+				t.Fatalf("CreateWARC() error = %v", err)
+			}
+
+			rec := NewRecording(NewStatic(StaticConfig{}), w)
+			live, err := rec.Fetch(context.Background(), srv.URL+"/page", Options{})
+			if err != nil {
+				t.Fatalf("recording Fetch() error = %v", err)
+			}
+			if err := rec.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			before := hits.Load()
+			replay, err := NewReplay(path)
+			if err != nil {
+				t.Fatalf("NewReplay() error = %v", err)
+			}
+			if replay.Len() != 1 {
+				t.Fatalf("replay.Len() = %d, want 1", replay.Len())
+			}
+
+			got, err := replay.Fetch(context.Background(), srv.URL+"/page", Options{})
+			if err != nil {
+				t.Fatalf("replay Fetch() error = %v", err)
+			}
+			if hits.Load() != before {
+				t.Error("replay should not touch the network")
+			}
+			if got.HTML != live.HTML {
+				t.Errorf("replayed HTML = %q, want %q", got.HTML, live.HTML)
+			}
+			if got.Title != "Archived" {
+				t.Errorf("replayed Title = %q, want %q", got.Title, "Archived")
+			}
+			if got.StatusCode != http.StatusOK {
+				t.Errorf("replayed StatusCode = %d, want 200", got.StatusCode)
+			}
+			if !strings.HasPrefix(got.ContentType, "text/html") {
+				t.Errorf("replayed ContentType = %q", got.ContentType)
+			}
+			if len(got.Links) != 1 || got.Links[0] != srv.URL+"/next" {
+				t.Errorf("replayed Links = %v", got.Links)
+			}
+		})
+	}
+}
+
+func TestWARC_RecordAndReplay_Redirect(t *testing.T) {
+	// "\xe9" and "\xe8" are é and è in ISO-8859-1
+	page := []byte("<html><head><title>Caf\xe9</title></head><body>cr\xe8me</body></html>")
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+		_, _ = w.Write(page)
+	})
+
+	var buf bytes.Buffer
+	rec := NewRecording(NewStatic(StaticConfig{}), NewWARCWriter(&buf, false))
+	live, err := rec.Fetch(context.Background(), srv.URL+"/old", Options{})
+	if err != nil {
+		t.Fatalf("recording Fetch() error = %v", err)
+	}
+	if len(live.RedirectChain) != 1 {
+		t.Fatalf("live RedirectChain = %v, want one redirect", live.RedirectChain)
+	}
+
+	// The archive holds the body as served, in its declared charset
+	r, _ := NewWARCReader(bytes.NewReader(buf.Bytes()))
+	resp, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if !bytes.HasSuffix(resp.Block, page) || !strings.Contains(string(resp.Block), "charset=iso-8859-1") {
+		t.Errorf("response record = %q, want the ISO-8859-1 body as served", resp.Block)
+	}
+
+	replay, err := NewReplayFromReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReplayFromReader() error = %v", err)
+	}
+	got, err := replay.Fetch(context.Background(), srv.URL+"/old", Options{})
+	if err != nil {
+		t.Fatalf("replay Fetch() error = %v", err)
+	}
+	if got.HTML != live.HTML || got.Title != "Café" || got.Charset != live.Charset {
+		t.Errorf("replayed HTML = %q, Title = %q, Charset = %q; want %q, %q, %q", got.HTML, got.Title, got.Charset, live.HTML, "Café", live.Charset)
+	}
+	if !bytes.Equal(got.Body, page) {
+		t.Errorf("replayed Body = %q, want %q", got.Body, page)
+	}
+	if got.FinalURL != srv.URL+"/new" || !reflect.DeepEqual(got.RedirectChain, live.RedirectChain) {
+		t.Errorf("replayed FinalURL = %q, RedirectChain = %v; want %q, %v", got.FinalURL, got.RedirectChain, srv.URL+"/new", live.RedirectChain)
+	}
+}
+
+func TestWARC_ReplayResourcesAndImages(t *testing.T) {
+	img := blankPNG(t, 3, 2)
+	want := Content{
+		StatusCode: http.StatusOK,
+		HTML:       "<html><body>rendered</body></html>",
+		Resources: []Resource{
+			{URL: "https://example.com/api/items?page=1", Method: http.MethodPost, StatusCode: http.StatusOK, ContentType: "application/json", Body: `{"items":[1,2]}`},
+		},
+		Screenshot: &Image{MediaType: "image/png", Data: img, Width: 3, Height: 2},
+		Images:     []Image{{URL: "https://example.com/plan.png", MediaType: "image/png", Data: img, Width: 3, Height: 2}},
+	}
+
+	var buf bytes.Buffer
+	if err := NewWARCWriter(&buf, false).WriteExchange("https://example.com/page", Options{}, want); err != nil {
+		t.Fatalf("WriteExchange() error = %v", err)
+	}
+	replay, err := NewReplayFromReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReplayFromReader() error = %v", err)
+	}
+	if replay.Len() != 1 {
+		t.Errorf("replay.Len() = %d, want only the page", replay.Len())
+	}
+	got, err := replay.Fetch(context.Background(), "https://example.com/page", Options{})
+	if err != nil {
+		t.Fatalf("replay Fetch() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Resources, want.Resources) {
+		t.Errorf("replayed Resources = %+v, want %+v", got.Resources, want.Resources)
+	}
+	if !reflect.DeepEqual(got.Screenshot, want.Screenshot) {
+		t.Errorf("replayed Screenshot = %+v, want %+v", got.Screenshot, want.Screenshot)
+	}
+	if !reflect.DeepEqual(got.Images, want.Images) {
+		t.Errorf("replayed Images = %+v, want %+v", got.Images, want.Images)
+	}
+	if got.FinalURL != "" || got.RedirectChain != nil {
+		t.Errorf("replayed FinalURL = %q, RedirectChain = %v; want none", got.FinalURL, got.RedirectChain)
+	}
+}
+
+// blankPNG returns a blank width x height PNG.
+func blankPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReplayFetcher_NotArchived(t *testing.T) {
+	replay, err := NewReplayFromReader(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("NewReplayFromReader() error = %v", err)
+	}
+	_, err = replay.Fetch(context.Background(), "https://example.com/missing", Options{})
+	if !errors.Is(err, ErrNotArchived) {
+		t.Errorf("Fetch() error = %v, want ErrNotArchived", err)
+	}
+}
+
+func TestReplayFetcher_KeysByRequestBody(t *testing.T) {
+	srv, _ := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body>" + r.Method + " " + string(body) + "</body></html>"))
+	})
+
+	var buf bytes.Buffer
+	rec := NewRecording(NewStatic(StaticConfig{}), NewWARCWriter(&buf, false))
+	requests := []Options{
+		{},
+		{Body: "q=chairs", ContentType: "application/x-www-form-urlencoded"},
+		{Body: "q=tables", ContentType: "application/x-www-form-urlencoded"},
+	}
+	for _, opts := range requests {
+		if _, err := rec.Fetch(context.Background(), srv.URL+"/search", opts); err != nil {
+			t.Fatalf("recording Fetch() error = %v", err)
+		}
+	}
+
+	replay, err := NewReplayFromReader(&buf)
+	if err != nil {
+		t.Fatalf("NewReplayFromReader() error = %v", err)
+	}
+	if replay.Len() != len(requests) {
+		t.Fatalf("replay.Len() = %d, want %d", replay.Len(), len(requests))
+	}
+	for _, want := range []struct {
+		opts Options
+		text string
+	}{
+		{requests[0], "GET"},
+		{requests[1], "POST q=chairs"},
+		{requests[2], "POST q=tables"},
+	} {
+		got, err := replay.Fetch(context.Background(), srv.URL+"/search", want.opts)
+		if err != nil {
+			t.Fatalf("replay Fetch(%+v) error = %v", want.opts, err)
+		}
+		if !strings.Contains(got.Text, want.text) {
+			t.Errorf("replay Fetch(%+v) Text = %q, want %q", want.opts, got.Text, want.text)
+		}
+	}
+
+	_, err = replay.Fetch(context.Background(), srv.URL+"/search", Options{Body: "q=sofas"})
+	if !errors.Is(err, ErrNotArchived) {
+		t.Errorf("Fetch() of an unrecorded body error = %v, want ErrNotArchived", err)
+	}
+}
+
+func TestWARCReader_RecordStructure(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWARCWriter(&buf, false)
+	if err := w.WriteExchange("https://example.com/a?b=1", Options{UserAgent: "test-agent"}, Content{
+		StatusCode: http.StatusOK,
+		HTML:       "<p>hi</p>",
+		Headers:    http.Header{"Content-Encoding": {"gzip"}},
+	}); err != nil {
+		t.Fatalf("WriteExchange() error = %v", err)
+	}
+
+	r, err := NewWARCReader(&buf)
+	if err != nil {
+		t.Fatalf("NewWARCReader() error = %v", err)
+	}
+
+	resp, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if resp.Type() != WARCTypeResponse {
+		t.Errorf("first record type = %q, want response", resp.Type())
+	}
+	if strings.Contains(string(resp.Block), "Content-Encoding") {
+		t.Error("decoded payload should not carry Content-Encoding")
+	}
+	if resp.Header.Get("WARC-Payload-Digest") == "" || resp.Header.Get("WARC-Block-Digest") == "" {
+		t.Error("response record should carry payload and block digests")
+	}
+
+	req, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if req.Type() != WARCTypeRequest {
+		t.Errorf("second record type = %q, want request", req.Type())
+	}
+	if req.Header.Get("WARC-Concurrent-To") != resp.Header.Get("WARC-Record-ID") {
+		t.Error("request record should reference its response")
+	}
+	if !strings.HasPrefix(string(req.Block), "GET /a?b=1 HTTP/1.1\r\n") {
+		t.Errorf("unexpected request line: %q", string(req.Block))
+	}
+	if !strings.Contains(string(req.Block), "User-Agent: test-agent") {
+		t.Error("request record should include the user agent")
+	}
+
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next() at end error = %v, want io.EOF", err)
+	}
+}
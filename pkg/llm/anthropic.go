@@ -74,9 +74,12 @@ func (p *AnthropicProvider) Execute(ctx context.Context, req Request) (*Response
 		case RoleSystem:
 			systemPrompt = msg.Content
 		case RoleUser:
-			messages = append(messages, anthropic.NewUserMessage(
-				anthropic.NewTextBlock(msg.Content),
-			))
+			blocks := make([]anthropic.ContentBlockParamUnion, 0, len(msg.Images)+1)
+			for _, img := range msg.Images {
+				blocks = append(blocks, anthropic.NewImageBlockBase64(img.MediaType, img.Base64()))
+			}
+			blocks = append(blocks, anthropic.NewTextBlock(msg.Content))
+			messages = append(messages, anthropic.NewUserMessage(blocks...))
 		case RoleAssistant:
 			messages = append(messages, anthropic.NewAssistantMessage(
 				anthropic.NewTextBlock(msg.Content),
@@ -151,12 +151,15 @@ func (p *HeliconeProvider) setHeaders(req *http.Request) {
 }
 
 func (p *HeliconeProvider) buildRequestBody(req Request) map[string]any {
-	messages := make([]map[string]string, len(req.Messages))
+	messages := make([]map[string]any, len(req.Messages))
 	for i, msg := range req.Messages {
-		messages[i] = map[string]string{
+		messages[i] = map[string]any{
 			"role":    string(msg.Role),
 			"content": msg.Content,
 		}
+		if msg.Role == RoleUser && len(msg.Images) > 0 {
+			messages[i]["content"] = openAIContentParts(msg)
+		}
 	}
 
 	body := map[string]any{
@@ -51,8 +51,9 @@ type ollamaRequest struct {
 }
 
 type ollamaMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"` // Base64-encoded, for vision models
 }
 
 type ollamaOptions struct {
@@ -74,10 +75,16 @@ func (p *OllamaProvider) Execute(ctx context.Context, req Request) (*Response, e
 
 	messages := make([]ollamaMessage, 0, len(req.Messages))
 	for _, msg := range req.Messages {
-		messages = append(messages, ollamaMessage{
+		m := ollamaMessage{
 			Role:    string(msg.Role),
 			Content: msg.Content,
-		})
+		}
+		if msg.Role == RoleUser {
+			for _, img := range msg.Images {
+				m.Images = append(m.Images, img.Base64())
+			}
+		}
+		messages = append(messages, m)
 	}
 
 	ollamaReq := ollamaRequest{
@@ -71,7 +71,7 @@ func (p *OpenAIProvider) Execute(ctx context.Context, req Request) (*Response, e
 		case RoleSystem:
 			messages = append(messages, openai.SystemMessage(msg.Content))
 		case RoleUser:
-			messages = append(messages, openai.UserMessage(msg.Content))
+			messages = append(messages, openAIUserMessage(msg))
 		case RoleAssistant:
 			messages = append(messages, openai.AssistantMessage(msg.Content))
 		}
@@ -130,6 +130,36 @@ func (p *OpenAIProvider) Execute(ctx context.Context, req Request) (*Response, e
 	}, nil
 }
 
+// openAIUserMessage converts a user message, sending any images as image_url
+// parts ahead of the text.
+func openAIUserMessage(msg Message) openai.ChatCompletionMessageParamUnion {
+	if len(msg.Images) == 0 {
+		return openai.UserMessage(msg.Content)
+	}
+	parts := make([]openai.ChatCompletionContentPartUnionParam, 0, len(msg.Images)+1)
+	for _, img := range msg.Images {
+		parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+			URL: img.DataURL(),
+		}))
+	}
+	parts = append(parts, openai.TextContentPart(msg.Content))
+	return openai.UserMessage(parts)
+}
+
+// openAIContentParts builds the content of a user message with images for
+// hand-written OpenAI-compatible requests. The text part is last, so callers
+// can attach cache_control to it.
+func openAIContentParts(msg Message) []map[string]any {
+	parts := make([]map[string]any, 0, len(msg.Images)+1)
+	for _, img := range msg.Images {
+		parts = append(parts, map[string]any{
+			"type":      "image_url",
+			"image_url": map[string]string{"url": img.DataURL()},
+		})
+	}
+	return append(parts, map[string]any{"type": "text", "text": msg.Content})
+}
+
 // Name returns the provider identifier.
 func (p *OpenAIProvider) Name() string {
 	return "openai"
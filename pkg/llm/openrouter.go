@@ -110,7 +110,7 @@ func (p *OpenRouterProvider) executeStandard(ctx context.Context, req Request, s
 		case RoleSystem:
 			messages = append(messages, openai.SystemMessage(msg.Content))
 		case RoleUser:
-			messages = append(messages, openai.UserMessage(msg.Content))
+			messages = append(messages, openAIUserMessage(msg))
 		case RoleAssistant:
 			messages = append(messages, openai.AssistantMessage(msg.Content))
 		}
@@ -175,6 +175,9 @@ func (p *OpenRouterProvider) executeWithCacheControl(ctx context.Context, req Re
 		if msg.CacheControl != "" {
 			m["cache_control"] = map[string]string{"type": string(msg.CacheControl)}
 		}
+		if msg.Role == RoleUser && len(msg.Images) > 0 {
+			m["content"] = openAIContentParts(msg)
+		}
 		messages = append(messages, m)
 	}
 
@@ -3,6 +3,7 @@ package llm
 
 import (
 	"context"
+	"encoding/base64"
 	"time"
 )
 
@@ -28,12 +29,32 @@ const (
 type Message struct {
 	Role    Role
 	Content string
+	// Images are sent as image content parts ahead of Content, for vision-capable
+	// models. Only user messages carry images; they are ignored on other roles.
+	Images []Image
 	// CacheControl enables prompt caching for this message on supported providers.
 	// Set to CacheControlEphemeral to cache the message prefix.
 	// Only effective on providers/models that support prompt caching.
 	CacheControl CacheControlType
 }
 
+// Image is an image content part.
+// Supported by: Anthropic, OpenAI, OpenRouter and Ollama (with vision models).
+type Image struct {
+	MediaType string // MIME type, e.g. "image/png" or "image/jpeg"
+	Data      []byte
+}
+
+// Base64 returns the image data base64-encoded.
+func (i Image) Base64() string {
+	return base64.StdEncoding.EncodeToString(i.Data)
+}
+
+// DataURL returns the image as a data: URL, as OpenAI-compatible APIs expect.
+func (i Image) DataURL() string {
+	return "data:" + i.MediaType + ";base64," + i.Base64()
+}
+
 // Request represents a completion request to the LLM.
 type Request struct {
 	Messages    []Message
@@ -43,6 +64,16 @@ type Request struct {
 	StrictMode  bool           // Use strict JSON schema validation (only for supported models)
 }
 
+// HasImages reports whether any message in the request carries images.
+func (r Request) HasImages() bool {
+	for _, msg := range r.Messages {
+		if len(msg.Images) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // Usage tracks token consumption.
 type Usage struct {
 	InputTokens  int
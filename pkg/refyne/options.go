@@ -33,10 +33,28 @@ type Config struct {
 	// Scraping settings
 	UserAgent string
 	Timeout   time.Duration
+	Retry     fetcher.RetryPolicy // Fetch retry policy (used when Fetcher is nil)
+	CookieJar *fetcher.CookieJar  // Cookie jar (used when Fetcher is nil; default: a new jar)
 	Fetcher   fetcher.Fetcher     // Optional: inject a pre-configured fetcher
 	Cleaner   cleaner.Cleaner     // Optional: inject a content cleaner (default: markdown)
 	Extractor extractor.Extractor // Optional: inject a custom extractor
 
+	// Fetch middleware wrapping the injected or default fetcher, outermost first
+	FetchMiddleware []fetcher.Middleware
+
+	// Fetcher for robots.txt, sitemaps and feeds when Fetcher renders pages in
+	// a browser (default: Fetcher). Middleware applies to it too; Close doesn't
+	// close it.
+	DocumentFetcher fetcher.Fetcher
+
+	// Browser settings (need a fetcher that drives a browser; crawls may override)
+	Actions      fetcher.ActionConfig   // Browser actions run on each page after it loads
+	Capture      *fetcher.CaptureConfig // Record matching XHR/fetch responses (nil = off)
+	ResourceMode fetcher.ResourceMode   // How captured responses are passed to the extractor
+
+	// Vision (needs a fetcher that drives a browser and a vision-capable model)
+	Screenshot *fetcher.ScreenshotConfig // Screenshot settings for schemas that use vision (nil = defaults)
+
 	// Extraction settings (used when Extractor is nil)
 	MaxRetries     int
 	Temperature    float64
@@ -60,6 +78,7 @@ func DefaultConfig() Config {
 		Provider:       "anthropic",
 		UserAgent:      defaultUserAgent,
 		Timeout:        30 * time.Second,
+		Retry:          fetcher.DefaultRetryPolicy(),
 		MaxRetries:     3,
 		Temperature:    0.1,
 		MaxContentSize: 100000, // 100KB default (SI units)
@@ -142,6 +161,54 @@ func WithTimeout(d time.Duration) Option {
 	}
 }
 
+// WithCookieJar sets the cookie jar used by the default fetcher, so session
+// cookies persist across requests and can be shared with other fetchers.
+// It has no effect when a fetcher is injected with WithFetcher.
+func WithCookieJar(jar *fetcher.CookieJar) Option {
+	return func(c *Config) {
+		c.CookieJar = jar
+	}
+}
+
+// WithRetryPolicy sets how the default fetcher retries failed requests.
+// It has no effect when a fetcher is injected with WithFetcher; configure that
+// fetcher's own retry policy instead.
+func WithRetryPolicy(p fetcher.RetryPolicy) Option {
+	return func(c *Config) {
+		c.Retry = p
+	}
+}
+
+// WithActions sets the browser actions run on each page after it loads, for
+// Extract and as the default for crawls: clicking "load more" buttons, scrolling
+// infinite listings, dismissing consent dialogs and so on. Actions need a
+// fetcher that drives a browser; the static fetcher ignores them.
+func WithActions(cfg fetcher.ActionConfig) Option {
+	return func(c *Config) {
+		c.Actions = cfg
+	}
+}
+
+// WithResourceCapture records the XHR/fetch responses a page loads while it
+// renders (Content.Resources) and passes them to the extractor according to
+// mode: ResourcesAppend adds them after the page content, ResourcesOnly uses
+// them instead of it. Capture needs a fetcher that drives a browser.
+func WithResourceCapture(cfg fetcher.CaptureConfig, mode fetcher.ResourceMode) Option {
+	return func(c *Config) {
+		c.Capture = &cfg
+		c.ResourceMode = mode
+	}
+}
+
+// WithScreenshots sets how screenshots and page images are captured for
+// schemas that opt into vision extraction (see schema.Vision). Screenshots
+// need a fetcher that drives a browser, and a vision-capable model.
+func WithScreenshots(cfg fetcher.ScreenshotConfig) Option {
+	return func(c *Config) {
+		c.Screenshot = &cfg
+	}
+}
+
 // WithMaxRetries sets the maximum extraction retry attempts.
 func WithMaxRetries(n int) Option {
 	return func(c *Config) {
@@ -192,6 +259,37 @@ func WithFetcher(f fetcher.Fetcher) Option {
 	}
 }
 
+// WithDocumentFetcher sets the fetcher robots.txt, sitemaps and feeds are
+// fetched with, as served.
+// Use it when the fetcher drives a browser, which would render the file as a
+// page: pass a static fetcher sharing its cookie jar, cache and archive. It
+// isn't closed by Close.
+func WithDocumentFetcher(f fetcher.Fetcher) Option {
+	return func(c *Config) {
+		c.DocumentFetcher = f
+	}
+}
+
+// WithFetchMiddleware wraps the fetcher (injected with WithFetcher, or the
+// default) in middleware, for cross-cutting concerns such as headers,
+// timeouts, metrics and content-type filtering. Middleware from repeated calls
+// is appended; the first is the outermost. See fetcher.Chain.
+//
+// Example:
+//
+//	metrics := &fetcher.FetchMetrics{}
+//	r, err := refyne.New(
+//	    refyne.WithFetchMiddleware(
+//	        fetcher.HeaderMiddleware(map[string]string{"Accept-Language": "en-GB"}),
+//	        fetcher.MetricsMiddleware(metrics),
+//	    ),
+//	)
+func WithFetchMiddleware(mws ...fetcher.Middleware) Option {
+	return func(c *Config) {
+		c.FetchMiddleware = append(c.FetchMiddleware, mws...)
+	}
+}
+
 // WithCleaner injects a content cleaner.
 // The cleaner transforms fetched HTML into a format suitable for LLM extraction.
 // Default: MarkdownCleaner (converts HTML to clean Markdown)
@@ -246,6 +344,26 @@ func WithLogger(l *slog.Logger) Option {
 // CrawlOption configures crawling behavior.
 type CrawlOption func(*crawler.Config)
 
+// WithSitemapSeeds adds sitemaps, sitemap indexes (including gzipped ones) and
+// RSS/Atom feeds whose URLs are crawled as seeds alongside those passed to
+// CrawlMany. Each source can filter entries by lastmod/pubDate window and URL regex.
+func WithSitemapSeeds(sources ...SeedSource) CrawlOption {
+	return func(c *crawler.Config) {
+		c.SeedSources = append(c.SeedSources, sources...)
+	}
+}
+
+// WithSeedRequests adds requests that aren't plain GETs, such as search form
+// submissions or JSON queries to a search endpoint, to the seeds passed to
+// CrawlMany. Requests are deduplicated on the method, URL, body and headers,
+// so several searches posted to the same URL are all crawled. See
+// fetcher.LoadRequests to read them from a YAML or JSON file.
+func WithSeedRequests(reqs ...fetcher.Request) CrawlOption {
+	return func(c *crawler.Config) {
+		c.SeedRequests = append(c.SeedRequests, reqs...)
+	}
+}
+
 // WithFollowSelector sets the CSS selector for links to follow.
 func WithFollowSelector(selector string) CrawlOption {
 	return func(c *crawler.Config) {
@@ -288,7 +406,8 @@ func WithMaxURLs(n int) CrawlOption {
 	}
 }
 
-// WithDelay sets the delay between requests.
+// WithDelay sets the minimum interval between requests to the same host.
+// It is ignored when WithHostRateLimit sets an explicit rate.
 func WithDelay(d time.Duration) CrawlOption {
 	return func(c *crawler.Config) {
 		c.Delay = d
@@ -302,6 +421,23 @@ func WithConcurrency(n int) CrawlOption {
 	}
 }
 
+// WithHostRateLimit sets a per-host token bucket: rps requests per second
+// sustained, with up to burst requests back-to-back. The rate backs off
+// automatically when a host returns 429/503 or slows down.
+func WithHostRateLimit(rps float64, burst int) CrawlOption {
+	return func(c *crawler.Config) {
+		c.HostRequestsPerSecond = rps
+		c.HostBurst = burst
+	}
+}
+
+// WithHostMaxInFlight caps concurrent requests to any single host.
+func WithHostMaxInFlight(n int) CrawlOption {
+	return func(c *crawler.Config) {
+		c.HostMaxInFlight = n
+	}
+}
+
 // WithSameDomainOnly restricts crawling to the same domain.
 func WithSameDomainOnly(enabled bool) CrawlOption {
 	return func(c *crawler.Config) {
@@ -309,6 +445,148 @@ func WithSameDomainOnly(enabled bool) CrawlOption {
 	}
 }
 
+// WithRespectRobots enables robots.txt compliance.
+// robots.txt is fetched once per host; disallowed URLs are reported as skipped
+// results (with ErrRobotsDisallowed) and Crawl-delay sets the host's minimum interval.
+// Groups are matched against the configured user agent's product token, or
+// "refyne" for a browser's user agent such as the default.
+func WithRespectRobots(enabled bool) CrawlOption {
+	return func(c *crawler.Config) {
+		c.RespectRobots = enabled
+	}
+}
+
+// WithAuth runs a login step once per host before it is crawled. Form logins
+// post through the fetcher's cookie jar; script logins need a fetcher that can
+// drive a browser (fetcher.ActionRunner). When a page matches the config's
+// logged-out selector or status, the crawler logs in again and refetches it.
+func WithAuth(cfg fetcher.AuthConfig) CrawlOption {
+	return func(c *crawler.Config) {
+		c.Auth = &cfg
+	}
+}
+
+// WithCrawlState saves the crawl's progress to dir: the pending queue with
+// depths, every URL seen, pagination counters and each URL's outcome. It is
+// saved periodically and when the crawl stops, including on cancellation, so
+// an interrupted crawl can be continued with WithResume.
+func WithCrawlState(dir string) CrawlOption {
+	return func(c *crawler.Config) {
+		c.StateDir = dir
+	}
+}
+
+// WithResume continues the crawl saved by WithCrawlState where it stopped,
+// instead of starting from the seeds. Finished URLs are not fetched or
+// extracted again. The seeds are used if no state has been saved yet.
+func WithResume(enabled bool) CrawlOption {
+	return func(c *crawler.Config) {
+		c.Resume = enabled
+	}
+}
+
+// WithCheckpointInterval sets how often WithCrawlState saves the crawl's
+// progress (default: 30s).
+func WithCheckpointInterval(d time.Duration) CrawlOption {
+	return func(c *crawler.Config) {
+		c.CheckpointInterval = d
+	}
+}
+
+// WithCrawlStrategy sets the order URLs are crawled in: StrategyBFS (the
+// default), StrategyDFS or StrategyPriority.
+func WithCrawlStrategy(strategy Strategy) CrawlOption {
+	return func(c *crawler.Config) {
+		c.Strategy = strategy
+	}
+}
+
+// WithPriority crawls the highest-scoring URLs first, scored by cfg: depth,
+// URL pattern weights, anchor text keywords and a per-host fairness penalty.
+// Start from DefaultPriorityConfig to keep its depth and fairness weights.
+func WithPriority(cfg PriorityConfig) CrawlOption {
+	return func(c *crawler.Config) {
+		c.Strategy = StrategyPriority
+		c.Priority = cfg
+	}
+}
+
+// WithURLScorer crawls the highest-scoring URLs first, scored by fn instead
+// of the built-in score. The priority config's host fairness penalty still
+// applies, and fn can build on the built-in score with PriorityConfig.BaseScore.
+func WithURLScorer(fn URLScorer) CrawlOption {
+	return func(c *crawler.Config) {
+		c.Strategy = StrategyPriority
+		c.Priority.Scorer = fn
+	}
+}
+
+// WithURLNormalization sets the rules URLs are normalized with before they
+// are queued and deduplicated, replacing DefaultNormalizeConfig.
+func WithURLNormalization(cfg NormalizeConfig) CrawlOption {
+	return func(c *crawler.Config) {
+		c.Normalize = cfg
+	}
+}
+
+// WithIgnoreParams removes query parameters (session IDs, sort orders, ...)
+// from URLs before they are deduplicated, in addition to the tracking
+// parameters removed by default. A trailing * matches a prefix, e.g. "sess*".
+func WithIgnoreParams(params ...string) CrawlOption {
+	return func(c *crawler.Config) {
+		c.Normalize.IgnoreParams = append(c.Normalize.IgnoreParams, params...)
+	}
+}
+
+// WithCanonicalDedup sets whether pages are deduplicated on the URL they
+// declare with rel=canonical (default: true). When enabled, each canonical
+// page is extracted once; its other URLs are reported as skipped results with
+// ErrDuplicateCanonical (their links are still followed), and every result
+// records its CanonicalURL.
+func WithCanonicalDedup(enabled bool) CrawlOption {
+	return func(c *crawler.Config) {
+		c.IgnoreCanonical = !enabled
+	}
+}
+
+// WithNearDuplicateDetection skips extraction for pages whose cleaned content
+// is at least similarity (0-1, e.g. 0.95) alike to a page already extracted in
+// the crawl, judged by SimHash fingerprints. Such pages are reported as
+// skipped results with ErrNearDuplicate and DuplicateOf set, and their links
+// are still followed. 0 turns it off.
+func WithNearDuplicateDetection(similarity float64) CrawlOption {
+	return func(c *crawler.Config) {
+		c.DuplicateSimilarity = similarity
+	}
+}
+
+// WithIncremental keeps a record of each extracted page in dir (a hash of its
+// cleaned content, its ETag and Last-Modified validators, and the data
+// extracted) and uses it on the next crawl: a page whose content is unchanged
+// is not extracted again, and is reported with its previous data and
+// Unchanged set. Changing the schema extracts every page again.
+func WithIncremental(dir string) CrawlOption {
+	return func(c *crawler.Config) {
+		c.IncrementalDir = dir
+	}
+}
+
+// WithOmitUnchanged reports pages WithIncremental finds unchanged as skipped
+// results with ErrUnchanged, instead of with their previous data.
+func WithOmitUnchanged(enabled bool) CrawlOption {
+	return func(c *crawler.Config) {
+		c.OmitUnchanged = enabled
+	}
+}
+
+// WithCrawlActions sets the browser actions for this crawl, replacing those
+// set with WithActions. Rules in cfg.URLs add actions for matching pages.
+func WithCrawlActions(cfg fetcher.ActionConfig) CrawlOption {
+	return func(c *crawler.Config) {
+		c.Actions = cfg
+	}
+}
+
 // WithExtractFromSeeds enables extraction from seed pages.
 func WithExtractFromSeeds(enabled bool) CrawlOption {
 	return func(c *crawler.Config) {
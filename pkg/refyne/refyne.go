@@ -1,6 +1,7 @@
 package refyne
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"runtime/debug"
@@ -23,12 +24,81 @@ var (
 	// ErrInsufficientContent is returned when cleaned content is too small for extraction.
 	// This typically indicates the page requires JavaScript rendering (dynamic fetch mode).
 	ErrInsufficientContent = crawler.ErrInsufficientContent
+
+	// ErrRobotsDisallowed is reported (on a skipped result) for URLs that robots.txt disallows.
+	ErrRobotsDisallowed = crawler.ErrRobotsDisallowed
+
+	// ErrDuplicateRedirect is reported (on a skipped result) for pages that redirect
+	// to a URL already crawled or queued.
+	ErrDuplicateRedirect = crawler.ErrDuplicateRedirect
+
+	// ErrDuplicateCanonical is reported (on a skipped result) for pages whose
+	// rel=canonical URL was already crawled or queued.
+	ErrDuplicateCanonical = crawler.ErrDuplicateCanonical
+
+	// ErrNearDuplicate is reported (on a skipped result) for pages whose content
+	// nearly duplicates a page already extracted; Result.DuplicateOf names it.
+	ErrNearDuplicate = crawler.ErrNearDuplicate
+
+	// ErrUnchanged is reported (on a skipped result) for pages unchanged since
+	// the last incremental crawl when WithOmitUnchanged is set.
+	ErrUnchanged = crawler.ErrUnchanged
 )
 
 // InsufficientContentError provides details about why content was insufficient.
 // Use errors.As to check for this error type.
 type InsufficientContentError = crawler.InsufficientContentError
 
+// SeedSource is a sitemap, sitemap index or RSS/Atom feed whose entries are
+// added to a crawl as seed URLs. See WithSitemapSeeds.
+type SeedSource = crawler.SeedSource
+
+// Strategy is the order in which a crawl visits the URLs it discovers.
+// See WithCrawlStrategy.
+type Strategy = crawler.Strategy
+
+// Crawl strategies.
+const (
+	StrategyBFS      = crawler.StrategyBFS      // Breadth-first: URLs in the order they were found (default)
+	StrategyDFS      = crawler.StrategyDFS      // Depth-first: the most recently found URL first
+	StrategyPriority = crawler.StrategyPriority // Highest-scoring URL first (see WithPriority)
+)
+
+// ParseStrategy converts a string (e.g., from a CLI flag) to a Strategy.
+func ParseStrategy(s string) (Strategy, error) {
+	return crawler.ParseStrategy(s)
+}
+
+// FrontierItem is a URL waiting to be crawled, as passed to a URLScorer.
+type FrontierItem = crawler.FrontierItem
+
+// URLScorer scores a URL for the priority strategy; higher scores are crawled
+// first. See WithURLScorer.
+type URLScorer = crawler.URLScorer
+
+// PriorityConfig scores URLs for the priority strategy. See WithPriority.
+type PriorityConfig = crawler.PriorityConfig
+
+// NormalizeConfig sets the rules URLs are normalized with before a crawl
+// deduplicates them. See WithURLNormalization.
+type NormalizeConfig = crawler.NormalizeConfig
+
+// DefaultNormalizeConfig returns the default URL normalization: tracking
+// parameters removed, query parameters sorted, lowercase hosts and no default
+// ports.
+func DefaultNormalizeConfig() NormalizeConfig {
+	return crawler.DefaultNormalizeConfig()
+}
+
+// PatternWeight adds a weight to the priority of URLs matching a pattern.
+type PatternWeight = crawler.PatternWeight
+
+// DefaultPriorityConfig returns the default priority scoring: shallow URLs
+// first, with the crawl spread across hosts.
+func DefaultPriorityConfig() PriorityConfig {
+	return crawler.DefaultPriorityConfig()
+}
+
 // Version returns the module version of the refyne library.
 // This returns the actual version consumers pulled via go get (e.g., "v1.0.0").
 // Returns "(devel)" when built from source without version info.
@@ -42,6 +112,9 @@ func Version() string {
 // Result represents an extraction result.
 type Result struct {
 	URL             string
+	FinalURL        string // URL after redirects (empty if the page wasn't redirected)
+	CanonicalURL    string // URL the page declared canonical with rel=canonical (empty if none, or the page's own URL)
+	DuplicateOf     string // Already extracted page this one nearly duplicates (skipped results with ErrNearDuplicate)
 	FetchedAt       time.Time
 	Data            any
 	Raw             string // Raw LLM response
@@ -56,8 +129,12 @@ type Result struct {
 	CostIncluded    bool          // True if Cost contains actual cost from provider
 	RetryCount      int
 	FetchDuration   time.Duration // Time to fetch the page
+	FetchAttempts   []fetcher.Attempt // Per-attempt fetch timings (more than one if the fetch was retried)
+	Fetcher         string        // Fetcher that produced the page (static, dynamic; auto records which it chose)
 	ExtractDuration time.Duration // Time for LLM extraction
 	Error           error
+	Skipped         bool // URL was deliberately not processed (e.g., robots.txt); Error explains why
+	Unchanged       bool // Page is unchanged since the last incremental crawl; Data is the data extracted then
 }
 
 // IsTruncated returns true if the output was truncated due to hitting the max_tokens limit.
@@ -74,6 +151,7 @@ type TokenUsage struct {
 // Refyne is the main entry point for web scraping with LLM extraction.
 type Refyne struct {
 	fetcher   fetcher.Fetcher
+	documents fetcher.Fetcher // robots.txt, sitemap and feed fetcher (nil = fetcher)
 	cleaner   cleaner.Cleaner
 	extractor extractor.Extractor
 	config    Config
@@ -94,8 +172,17 @@ func New(opts ...Option) (*Refyne, error) {
 		f = fetcher.NewStatic(fetcher.StaticConfig{
 			UserAgent: cfg.UserAgent,
 			Timeout:   cfg.Timeout,
+			Retry:     cfg.Retry,
+			CookieJar: cfg.CookieJar,
 		})
 	}
+	documents := cfg.DocumentFetcher
+	if len(cfg.FetchMiddleware) > 0 {
+		f = fetcher.Chain(f, cfg.FetchMiddleware...)
+		if documents != nil {
+			documents = fetcher.Chain(documents, cfg.FetchMiddleware...)
+		}
+	}
 
 	// Use injected cleaner or create a default refyne cleaner with markdown output
 	var cl cleaner.Cleaner
@@ -144,6 +231,7 @@ func New(opts ...Option) (*Refyne, error) {
 
 	return &Refyne{
 		fetcher:   f,
+		documents: documents,
 		cleaner:   cl,
 		extractor: ext,
 		config:    cfg,
@@ -154,8 +242,11 @@ func New(opts ...Option) (*Refyne, error) {
 func (r *Refyne) Extract(ctx context.Context, url string, s schema.Schema) (*Result, error) {
 	// Prepare fetch options
 	fetchOpts := fetcher.Options{
-		UserAgent: r.config.UserAgent,
-		Timeout:   r.config.Timeout,
+		UserAgent:  r.config.UserAgent,
+		Timeout:    r.config.Timeout,
+		Actions:    r.config.Actions.For(url),
+		Capture:    r.config.Capture,
+		Screenshot: crawler.ScreenshotOptions(r.config.Screenshot, s),
 	}
 
 	// Fetch the page
@@ -166,11 +257,14 @@ func (r *Refyne) Extract(ctx context.Context, url string, s schema.Schema) (*Res
 		return nil, fmt.Errorf("fetch failed: %w", err)
 	}
 
-	// Clean the content (convert HTML to markdown or other format)
+	// Clean the content (convert HTML to markdown or other format).
+	// Documents (PDF, JSON, ...) were already converted to markdown by the fetcher.
 	cleanStart := time.Now()
-	cleanedContent, err := r.cleaner.Clean(content.HTML)
-	cleanDuration := time.Since(cleanStart)
-	if err != nil {
+	var cleanedContent string
+	if content.Document != nil {
+		cleanedContent = content.Text
+		logger.Debug("using converted document", "format", content.Document.Format, "pages", content.Document.Pages)
+	} else if cleanedContent, err = r.cleaner.Clean(content.HTML); err != nil {
 		// Fall back to fetcher's text extraction if cleaner fails
 		logger.Debug("cleaner failed, using raw text",
 			"cleaner", r.cleaner.Name(),
@@ -181,17 +275,32 @@ func (r *Refyne) Extract(ctx context.Context, url string, s schema.Schema) (*Res
 			"cleaner", r.cleaner.Name(),
 			"input_size", len(content.HTML),
 			"output_size", len(cleanedContent),
-			"duration", cleanDuration)
+			"duration", time.Since(cleanStart))
 	}
 
+	// Add (or substitute) the JSON the page loaded through XHR/fetch
+	cleanedContent = fetcher.ExtractionInput(cleanedContent, content.Resources, r.config.ResourceMode)
+
+	// Send screenshots with (or instead of) the content if the schema uses vision
+	cleanedContent, images := crawler.VisionInput(content, cleanedContent, s.Vision)
+
 	// Extract data using cleaned content
-	result, extractErr := r.extractor.Extract(ctx, cleanedContent, s)
+	result, extractErr := extractor.ExtractWithImages(ctx, r.extractor, cleanedContent, images, s)
 
 	// Build the result - include partial data even on error for truncation detection
 	refyneResult := &Result{
 		URL:       url,
 		FetchedAt: content.FetchedAt,
 	}
+	if content.FinalURL != url {
+		refyneResult.FinalURL = content.FinalURL
+	}
+	if content.Document == nil {
+		pageURL := cmp.Or(content.FinalURL, url)
+		if canonical, ok := crawler.FindCanonical(content.HTML, pageURL); ok && canonical != pageURL {
+			refyneResult.CanonicalURL = canonical
+		}
+	}
 
 	if result != nil {
 		refyneResult.Data = result.Data
@@ -212,6 +321,11 @@ func (r *Refyne) Extract(ctx context.Context, url string, s schema.Schema) (*Res
 		refyneResult.Errors = result.Errors
 	}
 	refyneResult.FetchDuration = fetchDuration
+	refyneResult.FetchAttempts = content.Attempts
+	refyneResult.Fetcher = content.FetchedBy
+	if refyneResult.Fetcher == "" {
+		refyneResult.Fetcher = r.fetcher.Type()
+	}
 
 	if extractErr != nil {
 		refyneResult.Error = fmt.Errorf("extraction failed: %w", extractErr)
@@ -267,6 +381,22 @@ func (r *Refyne) CrawlMany(ctx context.Context, seeds []string, s schema.Schema,
 	for _, opt := range opts {
 		opt(&crawlCfg)
 	}
+	if crawlCfg.UserAgent == "" {
+		crawlCfg.UserAgent = r.config.UserAgent
+	}
+	if len(crawlCfg.Actions.Actions) == 0 && len(crawlCfg.Actions.URLs) == 0 {
+		crawlCfg.Actions = r.config.Actions
+	}
+	if crawlCfg.Capture == nil {
+		crawlCfg.Capture = r.config.Capture
+		crawlCfg.ResourceMode = r.config.ResourceMode
+	}
+	if crawlCfg.Screenshot == nil {
+		crawlCfg.Screenshot = r.config.Screenshot
+	}
+	if crawlCfg.DocumentFetcher == nil {
+		crawlCfg.DocumentFetcher = r.documents
+	}
 
 	// Create crawler with cleaner
 	c := crawler.New(r.fetcher, r.cleaner, r.extractor, crawlCfg)
@@ -281,13 +411,20 @@ func (r *Refyne) CrawlMany(ctx context.Context, seeds []string, s schema.Schema,
 		for cr := range crawlResults {
 			result := &Result{
 				URL:             cr.URL,
+				FinalURL:        cr.FinalURL,
+				CanonicalURL:    cr.CanonicalURL,
+				DuplicateOf:     cr.DuplicateOf,
 				FetchedAt:       cr.FetchedAt,
 				Data:            cr.Data,
 				Raw:             cr.Raw,
 				FetchDuration:   cr.FetchDuration,
+				FetchAttempts:   cr.FetchAttempts,
+				Fetcher:         cr.Fetcher,
 				ExtractDuration: cr.ExtractDuration,
 				Errors:          cr.Errors,
 				Error:           cr.Error,
+				Skipped:         cr.Skipped,
+				Unchanged:       cr.Unchanged,
 			}
 			// Copy extraction metadata if available (nil when extraction failed/skipped)
 			if cr.Usage != nil {
@@ -17,6 +17,7 @@ type Schema struct {
 	Name        string  `json:"name" yaml:"name"`
 	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
 	Fields      []Field `json:"fields" yaml:"fields"`
+	Vision      *Vision `json:"vision,omitempty" yaml:"vision,omitempty"` // Opt into extraction from page images (nil = text only)
 
 	target   reflect.Type      // Original struct type for unmarshaling
 	validate *validator.Validate
@@ -27,6 +28,7 @@ type SchemaOption func(*schemaBuilder)
 
 type schemaBuilder struct {
 	description string
+	vision      *Vision
 }
 
 // WithDescription sets the schema description (the NLP context).
@@ -36,6 +38,13 @@ func WithDescription(desc string) SchemaOption {
 	}
 }
 
+// WithVision opts the schema into extraction from page images.
+func WithVision(v Vision) SchemaOption {
+	return func(b *schemaBuilder) {
+		b.vision = &v
+	}
+}
+
 // NewSchema creates a Schema from a struct type using reflection.
 func NewSchema[T any](opts ...SchemaOption) (Schema, error) {
 	var zero T
@@ -61,6 +70,7 @@ func NewSchema[T any](opts ...SchemaOption) (Schema, error) {
 		Name:        t.Name(),
 		Description: builder.description,
 		Fields:      fields,
+		Vision:      builder.vision,
 		target:      t,
 		validate:    validator.New(),
 	}, nil
@@ -405,6 +405,37 @@ fields:
 	}
 }
 
+// TestFromYAML_Vision tests a schema that opts into vision extraction
+func TestFromYAML_Vision(t *testing.T) {
+	yamlData := []byte(`
+name: Listing
+vision:
+  mode: only
+  images: true
+fields:
+  - name: floor_area
+    type: number
+`)
+
+	s, err := FromYAML(yamlData)
+	if err != nil {
+		t.Fatalf("FromYAML failed: %v", err)
+	}
+	if s.Vision == nil {
+		t.Fatal("expected vision settings")
+	}
+	if !s.Vision.ImagesOnly() || !s.Vision.Images {
+		t.Errorf("expected images-only vision with page images, got %+v", *s.Vision)
+	}
+
+	if (Vision{Mode: "sometimes"}).Validate() == nil {
+		t.Error("expected error for unknown vision mode")
+	}
+	if (Vision{}).ImagesOnly() {
+		t.Error("vision should send content with images by default")
+	}
+}
+
 // TestFromYAML_InvalidYAML tests error handling for invalid YAML
 func TestFromYAML_InvalidYAML(t *testing.T) {
 	yamlData := []byte(`
@@ -0,0 +1,49 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VisionMode selects what a vision extraction sends to the model.
+type VisionMode string
+
+const (
+	// VisionAppend sends the page images along with the cleaned content.
+	VisionAppend VisionMode = "append"
+	// VisionOnly sends the page images instead of the cleaned content, falling
+	// back to the content when no images were captured.
+	VisionOnly VisionMode = "only"
+)
+
+// ParseVisionMode parses "append" (the default) or "only".
+func ParseVisionMode(s string) (VisionMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "append", "with":
+		return VisionAppend, nil
+	case "only", "instead":
+		return VisionOnly, nil
+	default:
+		return "", fmt.Errorf("unknown vision mode %q (use append or only)", s)
+	}
+}
+
+// Vision opts a schema into extraction from images of the page, for
+// information that only appears visually (floor plans, price badges, charts).
+// Images need a fetcher that renders pages (dynamic mode).
+type Vision struct {
+	Mode   VisionMode `json:"mode,omitempty" yaml:"mode,omitempty"`     // append (default) or only
+	Images bool       `json:"images,omitempty" yaml:"images,omitempty"` // Also send the page's images, not just a screenshot
+}
+
+// Validate checks the mode.
+func (v Vision) Validate() error {
+	_, err := ParseVisionMode(string(v.Mode))
+	return err
+}
+
+// ImagesOnly reports whether the cleaned content is left out of the request.
+func (v Vision) ImagesOnly() bool {
+	mode, _ := ParseVisionMode(string(v.Mode))
+	return mode == VisionOnly
+}